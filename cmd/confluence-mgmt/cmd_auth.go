@@ -2,10 +2,12 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/relux-works/skill-confluence-management/internal/config"
 	"github.com/relux-works/skill-confluence-management/internal/confluence"
@@ -14,11 +16,15 @@ import (
 )
 
 type authSetAccessFlags struct {
-	Instance string
-	Email    string
-	Token    string
-	Source   string
-	Check    bool
+	Instance      string
+	Email         string
+	Token         string
+	Source        string
+	Check         bool
+	ProxyURL      string
+	ProxyUsername string
+	ProxyPassword string
+	NoProxy       string
 }
 
 type authLookupFlags struct {
@@ -47,6 +53,7 @@ var authCmd = &cobra.Command{
 
 Primary flow:
   confluence-mgmt auth set-access --instance https://company.atlassian.net/wiki --email user@company.com --token API_TOKEN
+  confluence-mgmt auth login --oauth --client-id ID   (Cloud, OAuth 2.0 instead of an API token)
   confluence-mgmt auth whoami
   confluence-mgmt auth resolve
   confluence-mgmt auth clean
@@ -54,7 +61,7 @@ Primary flow:
 Compatibility:
   confluence-mgmt auth --instance URL --email EMAIL --token TOKEN
 
-Cloud uses Basic auth (email + API token).
+Cloud uses Basic auth (email + API token) or OAuth 2.0 (auth login --oauth).
 Server/DC PAT uses Bearer auth (token without email).`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runAuthSetAccess(cmd, authCompatOptions)
@@ -114,6 +121,10 @@ func bindAuthSetAccessFlags(fs *pflag.FlagSet, target *authSetAccessFlags) {
 	fs.StringVar(&target.Token, "token", "", "Confluence API token or PAT")
 	fs.StringVar(&target.Source, "source", string(config.SourceAuto), "Credential source: auto, keychain, env_or_file")
 	fs.BoolVar(&target.Check, "check", false, "Validate credentials immediately with a live Confluence read")
+	fs.StringVar(&target.ProxyURL, "proxy-url", "", "Proxy for this instance (overrides proxy_url in config)")
+	fs.StringVar(&target.ProxyUsername, "proxy-username", "", "Proxy auth user for this instance")
+	fs.StringVar(&target.ProxyPassword, "proxy-password", "", "Proxy auth password, stored with the credentials")
+	fs.StringVar(&target.NoProxy, "no-proxy", "", "Comma-separated hosts, domains or CIDRs this instance reaches without the proxy")
 }
 
 func bindAuthLookupFlags(fs *pflag.FlagSet, target *authLookupFlags) {
@@ -136,9 +147,13 @@ func runAuthSetAccess(cmd *cobra.Command, opts authSetAccessFlags) error {
 	}
 
 	creds := config.Credentials{
-		InstanceURL: instanceURL,
-		Email:       email,
-		APIToken:    apiToken,
+		InstanceURL:   instanceURL,
+		Email:         email,
+		APIToken:      apiToken,
+		ProxyURL:      opts.ProxyURL,
+		ProxyUsername: opts.ProxyUsername,
+		ProxyPassword: opts.ProxyPassword,
+		NoProxy:       opts.NoProxy,
 	}
 	if err := creds.Validate(); err != nil {
 		return fmt.Errorf("invalid credentials: %w", err)
@@ -162,7 +177,7 @@ func runAuthSetAccess(cmd *cobra.Command, opts authSetAccessFlags) error {
 	fmt.Fprintln(out, "Credentials stored.")
 	fmt.Fprintf(out, "  instance: %s\n", result.Credentials.InstanceURL)
 	fmt.Fprintf(out, "  auth type: %s\n", result.Credentials.AuthType)
-	fmt.Fprintf(out, "  instance type: %s\n", instanceType)
+	fmt.Fprintf(out, "  instance type: %s (guessed from the URL; confirmed by probing on first use)\n", instanceType)
 	fmt.Fprintf(out, "  source: %s\n", result.Source)
 	switch result.Source {
 	case config.SourceKeychain:
@@ -176,13 +191,15 @@ func runAuthSetAccess(cmd *cobra.Command, opts authSetAccessFlags) error {
 	if opts.Check {
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Validating credentials...")
-		instanceType, err := validateCredentials(result.Credentials, flagInsecure)
+		cfg, _ := cfgMgr.GetConfig()
+		info, trustPath, err := validateCredentials(cmd.Context(), result.Credentials, result.Source, cfg)
 		if err != nil {
 			return err
 		}
-		_ = cfgMgr.SetInstanceType(string(instanceType))
+		_ = storeDetectedInstance(cfgMgr, result.Credentials.InstanceURL, info)
+		fmt.Fprintf(out, "  trust path: %s\n", trustPath)
 		fmt.Fprintf(out, "  auth probe: ok\n")
-		fmt.Fprintf(out, "  instance type: %s\n", instanceType)
+		printInstanceInfo(out, info)
 	} else {
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Run 'confluence-mgmt auth whoami' to validate stored credentials.")
@@ -222,20 +239,27 @@ func runAuthWhoami(cmd *cobra.Command, opts authWhoamiFlags) error {
 		return nil
 	}
 
-	instanceType, err := validateCredentials(resolved.Credentials, flagInsecure)
+	var cfg config.Config
+	cfgMgr, cfgErr := config.NewConfigManager()
+	if cfgErr == nil {
+		cfg, _ = cfgMgr.GetConfig()
+	}
+
+	info, trustPath, err := validateCredentials(cmd.Context(), resolved.Credentials, persistSourceFor(resolved), cfg)
+	fmt.Fprintf(out, "  proxy: %s\n", describeProxy(proxyFromConfig(cfg, resolved.Credentials)))
+	fmt.Fprintf(out, "  trust path: %s\n", trustPath)
 	if err != nil {
 		return err
 	}
 
-	cfgMgr, err := config.NewConfigManager()
-	if err == nil && resolved.ResolvedFrom != "env" {
+	if cfgErr == nil && resolved.ResolvedFrom != "env" {
 		_ = cfgMgr.SetInstanceURL(resolved.Credentials.InstanceURL)
 		_ = cfgMgr.SetAuthType(resolved.Credentials.AuthType)
-		_ = cfgMgr.SetInstanceType(string(instanceType))
+		_ = storeDetectedInstance(cfgMgr, resolved.Credentials.InstanceURL, info)
 	}
 
 	fmt.Fprintf(out, "  auth probe: ok\n")
-	fmt.Fprintf(out, "  instance type: %s\n", instanceType)
+	printInstanceInfo(out, info)
 	return nil
 }
 
@@ -308,24 +332,48 @@ func promptForCredentials(out io.Writer, instanceURL, email, apiToken string) (s
 	return strings.TrimSpace(instanceURL), strings.TrimSpace(email), strings.TrimSpace(apiToken), nil
 }
 
-func validateCredentials(creds config.Credentials, insecure bool) (confluence.InstanceType, error) {
+// printInstanceInfo prints what probing the instance found.
+func printInstanceInfo(out io.Writer, info *confluence.InstanceInfo) {
+	fmt.Fprintf(out, "  instance type: %s\n", info.Type)
+	fmt.Fprintf(out, "  product version: %s\n", valueOrNone(info.Version))
+	fmt.Fprintf(out, "  capabilities: %s\n", describeCapabilities(capabilityNames(info.Capabilities)))
+	if !info.Complete() {
+		fmt.Fprintf(out, "  undetermined: %s\n", strings.Join(capabilityNames(info.Undetermined), ", "))
+	}
+}
+
+// validateCredentials probes the instance with creds using the TLS settings
+// from cfg; refreshed OAuth tokens are saved to source. It returns what the
+// probe detected and the trust path the probe used, which is set
+// whenever the client could be built, even if the probe itself failed.
+func validateCredentials(ctx context.Context, creds config.Credentials, source config.Source, cfg config.Config) (*confluence.InstanceInfo, string, error) {
 	instanceType := inferInstanceType(creds.InstanceURL)
+	if creds.AuthType == config.AuthTypeOAuth {
+		instanceType = confluence.InstanceCloud
+	}
 	client, err := confluence.NewClient(confluence.Config{
 		BaseURL:            creds.InstanceURL,
 		Email:              creds.Email,
 		Token:              creds.APIToken,
 		AuthType:           confluence.AuthType(creds.AuthType),
 		InstanceType:       instanceType,
-		InsecureSkipVerify: insecure,
+		InsecureSkipVerify: flagInsecure || cfg.TLSSkipVerify,
+		CABundle:           cfg.TLSCABundle,
+		ClientCert:         cfg.TLSClientCert,
+		ClientKey:          cfg.TLSClientKey,
+		Proxy:              proxyFromConfig(cfg, creds),
+		OAuth:              oauthFromCredentials(creds, source),
+		OnTrace:            traceHook(),
 	})
 	if err != nil {
-		return "", fmt.Errorf("creating client: %w", err)
+		return nil, "(unavailable)", fmt.Errorf("creating client: %w", err)
 	}
 
-	if _, err := client.ListSpaces(1); err != nil {
-		return "", fmt.Errorf("authentication failed: %w", err)
+	info, err := client.DetectInstanceContext(ctx)
+	if err != nil {
+		return nil, client.TrustPath(), fmt.Errorf("authentication failed: %w", err)
 	}
-	return instanceType, nil
+	return info, client.TrustPath(), nil
 }
 
 func inferInstanceType(instanceURL string) confluence.InstanceType {
@@ -359,6 +407,20 @@ func printResolvedCredentials(out io.Writer, resolved config.ResolvedCredentials
 	fmt.Fprintf(out, "  source: %s\n", resolved.Source)
 	fmt.Fprintf(out, "  resolved from: %s\n", resolved.ResolvedFrom)
 	fmt.Fprintf(out, "  inferred instance type: %s\n", inferInstanceType(resolved.Credentials.InstanceURL))
+	if resolved.Credentials.AuthType == config.AuthTypeOAuth {
+		fmt.Fprintf(out, "  cloud id: %s\n", valueOrNone(resolved.Credentials.CloudID))
+		if resolved.Credentials.AccountID != "" {
+			fmt.Fprintf(out, "  account id: %s\n", resolved.Credentials.AccountID)
+		} else {
+			fmt.Fprintln(out, "  account id: none (responses aren't cached; run 'auth login --oauth' again)")
+		}
+		if !resolved.Credentials.TokenExpiry.IsZero() {
+			fmt.Fprintf(out, "  access token expires: %s\n", resolved.Credentials.TokenExpiry.Format(time.RFC3339))
+		}
+	}
+	if resolved.Credentials.ProxyURL != "" {
+		fmt.Fprintf(out, "  profile proxy: %s\n", resolved.Credentials.ProxyURL)
+	}
 	if resolved.ConfigPath != "" {
 		fmt.Fprintf(out, "  auth file: %s\n", resolved.ConfigPath)
 	}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/relux-works/skill-confluence-management/internal/config"
+	"github.com/relux-works/skill-confluence-management/internal/confluence"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// defaultOAuthScopes cover the v2 read/write operations this CLI performs.
+// offline_access is what yields a refresh token.
+var defaultOAuthScopes = []string{
+	"offline_access",
+	"read:confluence-user",
+	"read:space:confluence",
+	"read:page:confluence",
+	"write:page:confluence",
+	"read:content-details:confluence",
+	"read:label:confluence",
+	"write:label:confluence",
+	"search:confluence",
+	"read:confluence-content.all",
+	"write:confluence-content",
+}
+
+// oauthLoginTimeout bounds how long login waits for the browser consent.
+const oauthLoginTimeout = 5 * time.Minute
+
+type authLoginFlags struct {
+	OAuth        bool
+	Instance     string
+	ClientID     string
+	ClientSecret string
+	Scopes       string
+	Port         int
+	Source       string
+	NoBrowser    bool
+}
+
+var authLoginOptions authLoginFlags
+
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Sign in to Confluence Cloud with OAuth 2.0 (3LO) in the browser",
+	Long: `Sign in to Confluence Cloud with OAuth 2.0 (3LO) and PKCE.
+
+Register an OAuth 2.0 integration in the Atlassian developer console with the
+callback URL http://localhost:<port>/callback (default port 8765) and the
+Confluence scopes you need, then run:
+
+  confluence-mgmt auth login --oauth --client-id ID [--client-secret SECRET]
+
+The access and refresh tokens are stored like other credentials (keychain or
+auth.json) and refreshed automatically. Revoke the app's access from your
+Atlassian account settings to invalidate them.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAuthLogin(cmd, authLoginOptions)
+	},
+}
+
+func bindAuthLoginFlags(fs *pflag.FlagSet, target *authLoginFlags) {
+	fs.BoolVar(&target.OAuth, "oauth", false, "Use the OAuth 2.0 (3LO) browser flow")
+	fs.StringVar(&target.Instance, "instance", "", "Site to use when the grant covers several (e.g. https://company.atlassian.net)")
+	fs.StringVar(&target.ClientID, "client-id", "", "OAuth app client ID (default $"+config.EnvOAuthClientID+")")
+	fs.StringVar(&target.ClientSecret, "client-secret", "", "OAuth app client secret, if the app has one (default $"+config.EnvOAuthClientSecret+")")
+	fs.StringVar(&target.Scopes, "scopes", strings.Join(defaultOAuthScopes, " "), "Space-separated scopes to request")
+	fs.IntVar(&target.Port, "port", 8765, "Loopback port for the OAuth callback; must match the app's callback URL")
+	fs.StringVar(&target.Source, "source", string(config.SourceAuto), "Credential source: auto, keychain, env_or_file")
+	fs.BoolVar(&target.NoBrowser, "no-browser", false, "Print the consent URL instead of opening a browser")
+}
+
+func runAuthLogin(cmd *cobra.Command, opts authLoginFlags) error {
+	if !opts.OAuth {
+		return fmt.Errorf("auth login supports --oauth only; for API tokens and PATs use 'confluence-mgmt auth set-access'")
+	}
+	out := cmd.OutOrStdout()
+
+	clientID := firstSet(strings.TrimSpace(opts.ClientID), os.Getenv(config.EnvOAuthClientID))
+	clientSecret := firstSet(strings.TrimSpace(opts.ClientSecret), os.Getenv(config.EnvOAuthClientSecret))
+	if clientID == "" {
+		return fmt.Errorf("--client-id is required (or set %s)", config.EnvOAuthClientID)
+	}
+
+	cfgMgr, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("config manager: %w", err)
+	}
+	cfg, _ := cfgMgr.GetConfig()
+
+	app, err := confluence.NewOAuthApp(confluence.Config{
+		InsecureSkipVerify: flagInsecure || cfg.TLSSkipVerify,
+		CABundle:           cfg.TLSCABundle,
+		ClientCert:         cfg.TLSClientCert,
+		ClientKey:          cfg.TLSClientKey,
+		Proxy:              proxyFromConfig(cfg, config.Credentials{}),
+		OAuth:              &confluence.OAuthConfig{ClientID: clientID, ClientSecret: clientSecret},
+		OnTrace:            traceHook(),
+	})
+	if err != nil {
+		return err
+	}
+
+	verifier, challenge, err := confluence.NewPKCE()
+	if err != nil {
+		return err
+	}
+	state, err := confluence.NewOAuthState()
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", opts.Port))
+	if err != nil {
+		return fmt.Errorf("listening for the OAuth callback on port %d: %w", opts.Port, err)
+	}
+	redirectURI := fmt.Sprintf("http://localhost:%d/callback", opts.Port)
+	authURL := app.AuthorizeURL(redirectURI, state, challenge, strings.Fields(opts.Scopes))
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), oauthLoginTimeout)
+	defer cancel()
+
+	fmt.Fprintln(out, "Open this URL to authorize confluence-mgmt:")
+	fmt.Fprintf(out, "  %s\n\n", authURL)
+	if !opts.NoBrowser {
+		_ = openBrowser(authURL)
+	}
+	fmt.Fprintf(out, "Waiting for the callback on %s ...\n", redirectURI)
+
+	code, err := waitForOAuthCallback(ctx, listener, state)
+	if err != nil {
+		return err
+	}
+
+	tok, err := app.Exchange(ctx, code, redirectURI, verifier)
+	if err != nil {
+		return fmt.Errorf("exchanging authorization code: %w", err)
+	}
+	resources, err := app.AccessibleResources(ctx, tok.AccessToken)
+	if err != nil {
+		return fmt.Errorf("listing accessible sites: %w", err)
+	}
+	site, err := pickOAuthSite(resources, opts.Instance)
+	if err != nil {
+		return err
+	}
+	accountID, err := app.CurrentAccountID(ctx, tok.AccessToken, site.ID)
+	if err != nil {
+		return fmt.Errorf("reading the signed-in account: %w", err)
+	}
+
+	creds := config.Credentials{
+		InstanceURL:       strings.TrimRight(site.URL, "/") + "/wiki",
+		AuthType:          config.AuthTypeOAuth,
+		APIToken:          tok.AccessToken,
+		RefreshToken:      tok.RefreshToken,
+		TokenExpiry:       tok.Expiry,
+		OAuthClientID:     clientID,
+		OAuthClientSecret: clientSecret,
+		CloudID:           site.ID,
+		AccountID:         accountID,
+	}
+	result, err := getCredentialResolver().SetAccess(config.Source(opts.Source), creds)
+	if err != nil {
+		return fmt.Errorf("saving credentials: %w", err)
+	}
+	_ = cfgMgr.SetInstanceURL(result.Credentials.InstanceURL)
+	_ = cfgMgr.SetAuthType(config.AuthTypeOAuth)
+	_ = cfgMgr.SetInstanceType(string(confluence.InstanceCloud))
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Signed in with OAuth.")
+	fmt.Fprintf(out, "  site: %s (%s)\n", site.Name, site.URL)
+	fmt.Fprintf(out, "  cloud id: %s\n", site.ID)
+	fmt.Fprintf(out, "  account id: %s\n", accountID)
+	fmt.Fprintf(out, "  scopes: %s\n", valueOrNone(tok.Scope))
+	if !tok.Expiry.IsZero() {
+		fmt.Fprintf(out, "  access token expires: %s\n", tok.Expiry.Format(time.RFC3339))
+	}
+	fmt.Fprintf(out, "  source: %s\n", result.Source)
+	if tok.RefreshToken == "" {
+		fmt.Fprintln(out, "  warning: no refresh token was issued (request the offline_access scope); run login again when the access token expires")
+	}
+	return nil
+}
+
+// waitForOAuthCallback serves the loopback redirect until it delivers an
+// authorization code for state, or ctx ends.
+func waitForOAuthCallback(ctx context.Context, listener net.Listener, state string) (string, error) {
+	type callback struct {
+		code string
+		err  error
+	}
+	results := make(chan callback, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		var res callback
+		switch {
+		case q.Get("state") != state:
+			res.err = errors.New("OAuth callback state mismatch; start the login again")
+		case q.Get("error") != "":
+			res.err = fmt.Errorf("authorization denied: %s %s", q.Get("error"), q.Get("error_description"))
+		case q.Get("code") == "":
+			res.err = errors.New("OAuth callback carried no authorization code")
+		default:
+			res.code = q.Get("code")
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if res.err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "<p>confluence-mgmt sign-in failed: %s</p>", html.EscapeString(res.err.Error()))
+		} else {
+			io.WriteString(w, "<p>confluence-mgmt is signed in. You can close this tab.</p>")
+		}
+		select {
+		case results <- res:
+		default:
+		}
+	})
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go server.Serve(listener)
+	defer server.Close()
+
+	select {
+	case res := <-results:
+		return res.code, res.err
+	case <-ctx.Done():
+		return "", fmt.Errorf("waiting for the OAuth callback: %w", ctx.Err())
+	}
+}
+
+// pickOAuthSite selects the Confluence site for the grant: the one matching
+// instance, or the only one when instance is empty.
+func pickOAuthSite(resources []confluence.AccessibleResource, instance string) (confluence.AccessibleResource, error) {
+	if len(resources) == 0 {
+		return confluence.AccessibleResource{}, errors.New("the grant covers no Confluence sites; check the app's scopes and that you picked a site on the consent page")
+	}
+	want := strings.TrimSuffix(strings.TrimRight(strings.TrimSpace(instance), "/"), "/wiki")
+	if want == "" && len(resources) == 1 {
+		return resources[0], nil
+	}
+	var sites []string
+	for _, r := range resources {
+		if want != "" && strings.EqualFold(strings.TrimRight(r.URL, "/"), want) {
+			return r, nil
+		}
+		sites = append(sites, r.URL)
+	}
+	if want == "" {
+		return confluence.AccessibleResource{}, fmt.Errorf("the grant covers several sites; pick one with --instance: %s", strings.Join(sites, ", "))
+	}
+	return confluence.AccessibleResource{}, fmt.Errorf("site %s is not in the grant (available: %s)", want, strings.Join(sites, ", "))
+}
+
+// openBrowser opens url in the default browser, best effort.
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+func init() {
+	bindAuthLoginFlags(authLoginCmd.Flags(), &authLoginOptions)
+	authCmd.AddCommand(authLoginCmd)
+}
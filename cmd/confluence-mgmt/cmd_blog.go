@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var blogCmd = &cobra.Command{
+	Use:   "blog",
+	Short: "Blog post operations (list, get, create, update, delete)",
+}
+
+// --- blog list ---
+
+var (
+	blogListTitle string
+	blogListLimit int
+)
+
+var blogListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the blog posts in a space",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if flagSpace == "" {
+			return fmt.Errorf("space is required (use --space flag or 'config set space')")
+		}
+
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		posts, err := client.ListBlogPostsContext(cmd.Context(), flagSpace, blogListTitle, blogListLimit)
+		if err != nil {
+			return err
+		}
+
+		return outputResult(cmd, posts)
+	},
+}
+
+// --- blog get ---
+
+var blogGetBody bool
+
+var blogGetCmd = &cobra.Command{
+	Use:   "get <post-id>",
+	Short: "Get a blog post by ID",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		post, err := client.GetBlogPostContext(cmd.Context(), args[0], blogGetBody)
+		if err != nil {
+			return err
+		}
+
+		return outputResult(cmd, post)
+	},
+}
+
+// --- blog create ---
+
+var (
+	blogCreateTitle    string
+	blogCreateBody     string
+	blogCreateBodyFile string
+)
+
+var blogCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Publish a new blog post",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if flagSpace == "" {
+			return fmt.Errorf("space is required (use --space flag or 'config set space')")
+		}
+
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		body := blogCreateBody
+		if blogCreateBodyFile != "" {
+			data, err := os.ReadFile(blogCreateBodyFile)
+			if err != nil {
+				return fmt.Errorf("reading body file: %w", err)
+			}
+			body = string(data)
+		}
+
+		post, err := client.CreateBlogPostContext(cmd.Context(), flagSpace, blogCreateTitle, body)
+		if err != nil {
+			return err
+		}
+
+		return outputResult(cmd, post)
+	},
+}
+
+// --- blog update ---
+
+var (
+	blogUpdateTitle    string
+	blogUpdateBody     string
+	blogUpdateBodyFile string
+	blogUpdateMessage  string
+)
+
+var blogUpdateCmd = &cobra.Command{
+	Use:   "update <post-id>",
+	Short: "Update a blog post (omitted title or body are kept)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		body := blogUpdateBody
+		if blogUpdateBodyFile != "" {
+			data, err := os.ReadFile(blogUpdateBodyFile)
+			if err != nil {
+				return fmt.Errorf("reading body file: %w", err)
+			}
+			body = string(data)
+		}
+
+		post, err := client.UpdateBlogPostContext(cmd.Context(), args[0], blogUpdateTitle, body, blogUpdateMessage)
+		if err != nil {
+			return err
+		}
+
+		return outputResult(cmd, post)
+	},
+}
+
+// --- blog delete ---
+
+var blogDeleteCmd = &cobra.Command{
+	Use:   "delete <post-id>",
+	Short: "Delete (trash) a blog post",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		if err := client.DeleteBlogPostContext(cmd.Context(), args[0]); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Blog post %s deleted\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	blogListCmd.Flags().StringVar(&blogListTitle, "title", "", "Only posts with this exact title")
+	blogListCmd.Flags().IntVar(&blogListLimit, "limit", 0, "Maximum number of posts (0 = all)")
+
+	blogGetCmd.Flags().BoolVar(&blogGetBody, "body", false, "Include post body in response")
+
+	blogCreateCmd.Flags().StringVar(&blogCreateTitle, "title", "", "Post title")
+	blogCreateCmd.Flags().StringVar(&blogCreateBody, "body", "", "Post body (storage format)")
+	blogCreateCmd.Flags().StringVar(&blogCreateBodyFile, "body-file", "", "Read body from file")
+
+	blogUpdateCmd.Flags().StringVar(&blogUpdateTitle, "title", "", "New title")
+	blogUpdateCmd.Flags().StringVar(&blogUpdateBody, "body", "", "New body (storage format)")
+	blogUpdateCmd.Flags().StringVar(&blogUpdateBodyFile, "body-file", "", "Read body from file")
+	blogUpdateCmd.Flags().StringVar(&blogUpdateMessage, "message", "", "Version message")
+
+	blogCmd.AddCommand(blogListCmd)
+	blogCmd.AddCommand(blogGetCmd)
+	blogCmd.AddCommand(blogCreateCmd)
+	blogCmd.AddCommand(blogUpdateCmd)
+	blogCmd.AddCommand(blogDeleteCmd)
+	rootCmd.AddCommand(blogCmd)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/relux-works/skill-confluence-management/internal/config"
+	"github.com/relux-works/skill-confluence-management/internal/confluence"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect or clear the local response and space key caches",
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show response cache size and hit/miss counters",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := config.CacheDir()
+		if err != nil {
+			return err
+		}
+		stats, err := confluence.NewResponseCache(dir).Stats()
+		if err != nil {
+			return err
+		}
+
+		var hitRate float64
+		if lookups := stats.Hits + stats.Misses; lookups > 0 {
+			hitRate = float64(stats.Hits) / float64(lookups)
+		}
+		return outputResult(cmd, map[string]any{
+			"dir":         dir,
+			"entries":     stats.Entries,
+			"bytes":       stats.Bytes,
+			"hits":        stats.Hits,
+			"misses":      stats.Misses,
+			"revalidated": stats.Revalidated,
+			"hitRate":     hitRate,
+		})
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete cached responses and space key mappings",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := config.CacheDir()
+		if err != nil {
+			return err
+		}
+		removed, err := confluence.NewResponseCache(dir).Clear()
+		if err != nil {
+			return err
+		}
+
+		spacePath, err := config.SpaceCachePath()
+		if err != nil {
+			return err
+		}
+		if err := confluence.NewSpaceCache(spacePath, 0).Clear(); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed %d cached responses and the space key cache\n", removed)
+		return nil
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -2,8 +2,14 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/relux-works/skill-confluence-management/internal/config"
+	"github.com/relux-works/skill-confluence-management/internal/confluence"
 	"github.com/spf13/cobra"
 )
 
@@ -18,12 +24,35 @@ var configSetCmd = &cobra.Command{
 	Long: `Set a configuration value.
 
 Keys:
-  space            — active Confluence space key (e.g. DEV)
-  tls_skip_verify  — skip TLS cert verification: true/false (for corporate CAs)
+  space                    — active Confluence space key (e.g. DEV)
+  instance_type            — cloud or server to skip probing the instance, auto to detect again
+  tls_skip_verify          — skip TLS cert verification: true/false (prefer tls_ca_bundle)
+  tls_ca_bundle            — PEM file of corporate CAs, trusted alongside the system roots ("" to clear)
+  tls_client_cert          — PEM client certificate for instances behind an mTLS gateway
+  tls_client_key           — PEM private key for tls_client_cert
+  proxy_url                — default proxy for all instances, e.g. http://proxy.corp:3128 ("" = use HTTP(S)_PROXY)
+  proxy_username           — proxy auth user; the password comes from CONFLUENCE_MGMT_PROXY_PASSWORD
+                             or 'auth set-access --proxy-password' (never stored in config.yaml)
+  no_proxy                 — comma-separated hosts, .domains or CIDRs reached without the proxy
+  retry_max_attempts       — total attempts per request, including the first (1 = no retries)
+  retry_base_delay         — delay before the first retry, doubled each time (e.g. 500ms, 2s)
+  retry_max_delay          — cap on any single wait, including Retry-After (e.g. 30s)
+  retry_jitter             — fraction of each delay randomised, 0-1 (e.g. 0.2)
+  retry_honor_retry_after  — wait as long as Retry-After asks on 429/503: true/false
+  rate_limit_rps           — client-side requests per second for all calls (0 = unlimited)
+  rate_limit_burst         — requests allowed back-to-back before pacing starts
+  rate_limit_write_rps     — separate writes-per-second budget (0 = only the overall limit)
+  rate_limit_write_burst   — writes allowed back-to-back before pacing starts
+  space_cache_ttl          — how long cached space key/ID mappings are reused (0 = don't persist)
+  response_cache           — cache page bodies and ETag responses on disk: true/false
 
 Examples:
   confluence-mgmt config set space DEV
-  confluence-mgmt config set tls_skip_verify true`,
+  confluence-mgmt config set instance_type server
+  confluence-mgmt config set tls_ca_bundle ~/certs/corp-root.pem
+  confluence-mgmt config set retry_max_attempts 6
+  confluence-mgmt config set retry_max_delay 1m
+  confluence-mgmt config set rate_limit_rps 5`,
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		key := args[0]
@@ -43,6 +72,23 @@ Examples:
 			}
 			fmt.Fprintf(out, "Active space set to %s\n", value)
 
+		case "instance_type":
+			switch value {
+			case "auto":
+				if err := cfgMgr.ClearDetectedInstance(); err != nil {
+					return err
+				}
+				fmt.Fprintln(out, "Instance type will be detected on the next command (or run 'confluence-mgmt config detect')")
+			case string(confluence.InstanceCloud), string(confluence.InstanceServer):
+				instanceURL, _ := configuredInstanceURL(getCredentialResolver(), "")
+				if err := cfgMgr.SetDetectedInstance(instanceURL, value, "", nil); err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "Instance type pinned to %s\n", value)
+			default:
+				return fmt.Errorf("instance_type must be cloud, server or auto, got %q", value)
+			}
+
 		case "tls_skip_verify":
 			skip := value == "true" || value == "1" || value == "yes"
 			if err := cfgMgr.SetTLSSkipVerify(skip); err != nil {
@@ -50,8 +96,136 @@ Examples:
 			}
 			fmt.Fprintf(out, "TLS skip verify set to %v\n", skip)
 
+		case "tls_ca_bundle", "tls_client_cert", "tls_client_key":
+			path, err := tlsFilePath(key, value)
+			if err != nil {
+				return err
+			}
+			switch key {
+			case "tls_ca_bundle":
+				err = cfgMgr.SetTLSCABundle(path)
+			case "tls_client_cert":
+				err = cfgMgr.SetTLSClientCert(path)
+			default:
+				err = cfgMgr.SetTLSClientKey(path)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "%s set to %s\n", strings.ReplaceAll(key, "_", " "), valueOrNone(path))
+
+		case "proxy_url":
+			if err := cfgMgr.SetProxyURL(strings.TrimSpace(value)); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Proxy URL set to %s\n", valueOrNone(strings.TrimSpace(value)))
+
+		case "proxy_username":
+			if err := cfgMgr.SetProxyUsername(strings.TrimSpace(value)); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Proxy username set to %s\n", valueOrNone(strings.TrimSpace(value)))
+
+		case "proxy_password":
+			return fmt.Errorf("proxy_password is not stored in config.yaml: set %s, or store it per instance with 'confluence-mgmt auth set-access --proxy-password'", config.EnvProxyPassword)
+
+		case "no_proxy":
+			if err := cfgMgr.SetNoProxy(strings.TrimSpace(value)); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "No-proxy list set to %s\n", valueOrNone(strings.TrimSpace(value)))
+
+		case "retry_max_attempts":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return fmt.Errorf("retry_max_attempts must be a positive integer, got %q", value)
+			}
+			if err := cfgMgr.SetRetryMaxAttempts(n); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Retry max attempts set to %d\n", n)
+
+		case "retry_base_delay", "retry_max_delay":
+			d, err := time.ParseDuration(value)
+			if err != nil || d < 0 {
+				return fmt.Errorf("%s must be a duration like 500ms or 30s, got %q", key, value)
+			}
+			if key == "retry_base_delay" {
+				err = cfgMgr.SetRetryBaseDelay(d)
+			} else {
+				err = cfgMgr.SetRetryMaxDelay(d)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "%s set to %s\n", strings.ReplaceAll(key, "_", " "), d)
+
+		case "retry_jitter":
+			jitter, err := strconv.ParseFloat(value, 64)
+			if err != nil || jitter < 0 || jitter > 1 {
+				return fmt.Errorf("retry_jitter must be a number between 0 and 1, got %q", value)
+			}
+			if err := cfgMgr.SetRetryJitter(jitter); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Retry jitter set to %v\n", jitter)
+
+		case "retry_honor_retry_after":
+			honor := value == "true" || value == "1" || value == "yes"
+			if err := cfgMgr.SetRetryHonorRetryAfter(honor); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Honor Retry-After set to %v\n", honor)
+
+		case "rate_limit_rps", "rate_limit_write_rps":
+			rps, err := strconv.ParseFloat(value, 64)
+			if err != nil || rps < 0 {
+				return fmt.Errorf("%s must be a non-negative number, got %q", key, value)
+			}
+			if key == "rate_limit_rps" {
+				err = cfgMgr.SetRateLimitRPS(rps)
+			} else {
+				err = cfgMgr.SetRateLimitWriteRPS(rps)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "%s set to %v\n", strings.ReplaceAll(key, "_", " "), rps)
+
+		case "rate_limit_burst", "rate_limit_write_burst":
+			burst, err := strconv.Atoi(value)
+			if err != nil || burst < 1 {
+				return fmt.Errorf("%s must be a positive integer, got %q", key, value)
+			}
+			if key == "rate_limit_burst" {
+				err = cfgMgr.SetRateLimitBurst(burst)
+			} else {
+				err = cfgMgr.SetRateLimitWriteBurst(burst)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "%s set to %d\n", strings.ReplaceAll(key, "_", " "), burst)
+
+		case "space_cache_ttl":
+			ttl, err := time.ParseDuration(value)
+			if err != nil || ttl < 0 {
+				return fmt.Errorf("space_cache_ttl must be a duration like 24h or 0, got %q", value)
+			}
+			if err := cfgMgr.SetSpaceCacheTTL(ttl); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Space cache TTL set to %s\n", ttl)
+
+		case "response_cache":
+			enabled := value == "true" || value == "1" || value == "yes"
+			if err := cfgMgr.SetResponseCache(enabled); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Response cache set to %v\n", enabled)
+
 		default:
-			return fmt.Errorf("unknown config key %q (supported: space, tls_skip_verify)", key)
+			return fmt.Errorf("unknown config key %q (supported: space, instance_type, tls_*, proxy_url, proxy_username, no_proxy, retry_*, rate_limit_*, space_cache_ttl, response_cache; see config set --help)", key)
 		}
 
 		return nil
@@ -78,14 +252,75 @@ var configShowCmd = &cobra.Command{
 		fmt.Fprintf(out, "  config file:    %s\n", cfgMgr.ConfigPath())
 		fmt.Fprintf(out, "  instance:       %s\n", valueOrNone(cfg.InstanceURL))
 		fmt.Fprintf(out, "  instance type:  %s\n", valueOrNone(cfg.InstanceType))
+		fmt.Fprintf(out, "  product version: %s\n", valueOrNone(cfg.ProductVersion))
+		fmt.Fprintf(out, "  capabilities:   %s\n", describeCapabilities(cfg.Capabilities))
 		fmt.Fprintf(out, "  auth type:      %s\n", valueOrNone(cfg.AuthType))
 		fmt.Fprintf(out, "  active space:   %s\n", valueOrNone(cfg.ActiveSpace))
 		fmt.Fprintf(out, "  tls skip verify: %v\n", cfg.TLSSkipVerify)
+		fmt.Fprintf(out, "  tls ca bundle:  %s\n", valueOrNone(cfg.TLSCABundle))
+		fmt.Fprintf(out, "  tls client cert: %s\n", valueOrNone(cfg.TLSClientCert))
+		fmt.Fprintf(out, "  tls client key: %s\n", valueOrNone(cfg.TLSClientKey))
+		fmt.Fprintf(out, "  proxy:          %s\n", describeProxy(proxyFromConfig(cfg, config.Credentials{})))
+
+		retry, err := retryPolicyFromConfig(cfg)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "  retry:          %d attempts, %s base delay, %s max delay, %.2f jitter, honor Retry-After: %v\n",
+			retry.MaxAttempts, retry.BaseDelay, retry.MaxDelay, retry.Jitter, retry.HonorRetryAfter)
+		rl := rateLimitFromConfig(cfg)
+		fmt.Fprintf(out, "  rate limit:     %s overall, %s writes\n",
+			describeRate(rl.RequestsPerSecond, rl.Burst), describeRate(rl.WritesPerSecond, rl.WriteBurst))
+
+		ttl, err := spaceCacheTTLFromConfig(cfg)
+		if err != nil {
+			return err
+		}
+		if ttl > 0 {
+			fmt.Fprintf(out, "  space cache:    %s\n", ttl)
+		} else {
+			fmt.Fprintf(out, "  space cache:    off\n")
+		}
+		fmt.Fprintf(out, "  response cache: %v\n", cfg.ResponseCache == nil || *cfg.ResponseCache)
 
 		return nil
 	},
 }
 
+var configDetectCmd = &cobra.Command{
+	Use:   "detect",
+	Short: "Probe the instance for its type, version and capabilities",
+	Long: `Probe the configured instance and record what it supports in config.yaml.
+
+Commands detect the instance on first use and whenever the instance URL
+changes; run this after an upgrade or migration (e.g. Server to Data Center,
+or a Cloud site moving to a custom domain) to refresh the record.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfgMgr, err := config.NewConfigManager()
+		if err != nil {
+			return err
+		}
+		instanceURL, _ := configuredInstanceURL(getCredentialResolver(), "")
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
+		if err != nil {
+			return err
+		}
+		info, err := client.DetectInstanceContext(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("detecting instance: %w", err)
+		}
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Detected %s\n", instanceURL)
+		printInstanceInfo(out, info)
+		if !info.Complete() {
+			fmt.Fprintf(out, "  %v\n", incompleteProbeError(info))
+			return nil
+		}
+		return storeDetectedInstance(cfgMgr, instanceURL, info)
+	},
+}
+
 func valueOrNone(s string) string {
 	if s == "" {
 		return "(none)"
@@ -96,5 +331,38 @@ func valueOrNone(s string) string {
 func init() {
 	configCmd.AddCommand(configSetCmd)
 	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configDetectCmd)
 	rootCmd.AddCommand(configCmd)
 }
+
+// tlsFilePath resolves a TLS file setting to an absolute path and checks that
+// it exists, so a typo fails at config time rather than on the next request.
+// An empty value clears the setting.
+func tlsFilePath(key, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	if rest, ok := strings.CutPrefix(value, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", key, err)
+		}
+		value = filepath.Join(home, rest)
+	}
+	path, err := filepath.Abs(value)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", key, err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("%s: %w", key, err)
+	}
+	return path, nil
+}
+
+func describeRate(rps float64, burst int) string {
+	if rps <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%g/s (burst %d)", rps, burst)
+}
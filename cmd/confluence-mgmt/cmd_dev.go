@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/relux-works/skill-confluence-management/internal/fakeconfluence"
+	"github.com/spf13/cobra"
+)
+
+var devCmd = &cobra.Command{
+	Use:   "dev",
+	Short: "Developer tools for testing agent workflows locally",
+}
+
+var (
+	fakeServerAddr      string
+	fakeServerSeed      string
+	fakeServerToken     string
+	fakeServerRateLimit int
+)
+
+var devFakeServerCmd = &cobra.Command{
+	Use:   "fake-server",
+	Short: "Serve an in-memory Confluence for end-to-end testing",
+	Long: `Serve an in-memory Confluence that speaks the v2 (Cloud) and v1 (Server/DC)
+REST endpoints this CLI uses. Content lives in memory and is lost on exit.
+
+Without --seed a small sample site (spaces DEV and OPS) is loaded. A seed file
+is YAML:
+
+  spaces:
+    - key: DEV
+      name: Development
+      pages:
+        - title: Home
+          body: <p>Welcome</p>
+          labels: [start]
+          children:
+            - title: Runbook
+              body: <p>Current text</p>
+              history:            # earlier versions, oldest first
+                - body: <p>First draft</p>
+
+Updates must increment the version (409 otherwise), unknown IDs answer 404,
+and --rate-limit makes the server answer 429 with Retry-After once the
+per-second budget is spent.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		seed := fakeconfluence.DemoSeed()
+		if fakeServerSeed != "" {
+			var err error
+			if seed, err = fakeconfluence.LoadSeed(fakeServerSeed); err != nil {
+				return err
+			}
+		}
+		srv, err := fakeconfluence.New(seed, fakeconfluence.Options{
+			Token:     fakeServerToken,
+			RateLimit: fakeServerRateLimit,
+		})
+		if err != nil {
+			return err
+		}
+
+		listener, err := net.Listen("tcp", fakeServerAddr)
+		if err != nil {
+			return fmt.Errorf("listening on %s: %w", fakeServerAddr, err)
+		}
+		base := "http://" + listener.Addr().String()
+		token := fakeServerToken
+		if token == "" {
+			token = "any"
+		}
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Fake Confluence listening on %s\n", base)
+		fmt.Fprintf(out, "  cloud (v2):     %s/wiki\n", base)
+		fmt.Fprintf(out, "  server/dc (v1): %s\n\n", base)
+		fmt.Fprintln(out, "Point the CLI at it, e.g. as Server/DC:")
+		fmt.Fprintf(out, "  CONFLUENCE_MGMT_INSTANCE_URL=%s CONFLUENCE_MGMT_AUTH_TYPE=bearer CONFLUENCE_MGMT_API_TOKEN=%s confluence-mgmt q 'search(\"space = DEV\")'\n", base, token)
+		fmt.Fprintln(out, "The CLI detects Cloud or Server/DC from the URL you point it at.")
+		fmt.Fprintln(out, "Press Ctrl-C to stop.")
+
+		server := &http.Server{Handler: srv, ReadHeaderTimeout: 10 * time.Second}
+		errc := make(chan error, 1)
+		go func() { errc <- server.Serve(listener) }()
+
+		select {
+		case err := <-errc:
+			return err
+		case <-cmd.Context().Done():
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	devFakeServerCmd.Flags().StringVar(&fakeServerAddr, "addr", "127.0.0.1:8090", "Address to listen on")
+	devFakeServerCmd.Flags().StringVar(&fakeServerSeed, "seed", "", "YAML seed file (default: built-in sample site)")
+	devFakeServerCmd.Flags().StringVar(&fakeServerToken, "token", "", "Require this API token or PAT (default: accept any credentials)")
+	devFakeServerCmd.Flags().IntVar(&fakeServerRateLimit, "rate-limit", 0, "Requests per second before answering 429 (0 = unlimited)")
+
+	devCmd.AddCommand(devFakeServerCmd)
+	rootCmd.AddCommand(devCmd)
+}
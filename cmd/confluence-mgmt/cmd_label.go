@@ -19,7 +19,7 @@ var labelAddCmd = &cobra.Command{
 	Short: "Add labels to a page",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := buildConfluenceClientFromConfig()
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
 		if err != nil {
 			return err
 		}
@@ -29,7 +29,7 @@ var labelAddCmd = &cobra.Command{
 			labels[i] = strings.TrimSpace(labels[i])
 		}
 
-		if err := client.AddLabels(args[0], labels); err != nil {
+		if err := client.AddLabelsContext(cmd.Context(), args[0], labels); err != nil {
 			return err
 		}
 
@@ -45,7 +45,7 @@ var labelRemoveCmd = &cobra.Command{
 	Short: "Remove labels from a page",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := buildConfluenceClientFromConfig()
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
 		if err != nil {
 			return err
 		}
@@ -53,7 +53,7 @@ var labelRemoveCmd = &cobra.Command{
 		labels := strings.Split(labelRemoveLabels, ",")
 		for _, l := range labels {
 			l = strings.TrimSpace(l)
-			if err := client.RemoveLabel(args[0], l); err != nil {
+			if err := client.RemoveLabelContext(cmd.Context(), args[0], l); err != nil {
 				return fmt.Errorf("removing label %q: %w", l, err)
 			}
 		}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/relux-works/skill-confluence-management/internal/confluence"
 	"github.com/spf13/cobra"
 )
 
@@ -21,13 +24,14 @@ var (
 	pageCreateBody     string
 	pageCreateBodyFile string
 	pageCreateParent   string
+	pageCreateDraft    bool
 )
 
 var pageCreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a new page",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := buildConfluenceClientFromConfig()
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
 		if err != nil {
 			return err
 		}
@@ -49,7 +53,8 @@ var pageCreateCmd = &cobra.Command{
 			body = string(data)
 		}
 
-		page, err := client.CreatePage(space, pageCreateTitle, body, pageCreateParent)
+		opts := confluence.CreateOptions{Draft: pageCreateDraft}
+		page, err := client.CreatePageWithOptionsContext(cmd.Context(), space, pageCreateTitle, body, pageCreateParent, opts)
 		if err != nil {
 			return err
 		}
@@ -61,10 +66,13 @@ var pageCreateCmd = &cobra.Command{
 // --- page update ---
 
 var (
-	pageUpdateTitle   string
-	pageUpdateBody    string
-	pageUpdateBodyFile string
-	pageUpdateMessage string
+	pageUpdateTitle         string
+	pageUpdateBody          string
+	pageUpdateBodyFile      string
+	pageUpdateMessage       string
+	pageUpdateExpectVersion int
+	pageUpdateMerge         bool
+	pageUpdateDraft         bool
 )
 
 var pageUpdateCmd = &cobra.Command{
@@ -72,7 +80,7 @@ var pageUpdateCmd = &cobra.Command{
 	Short: "Update an existing page",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := buildConfluenceClientFromConfig()
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
 		if err != nil {
 			return err
 		}
@@ -86,7 +94,41 @@ var pageUpdateCmd = &cobra.Command{
 			body = string(data)
 		}
 
-		page, err := client.UpdatePage(args[0], pageUpdateTitle, body, pageUpdateMessage)
+		if pageUpdateMerge && pageUpdateExpectVersion == 0 {
+			return fmt.Errorf("--merge needs --expect-version: the version your edit is based on")
+		}
+		if pageUpdateMerge && body == "" {
+			return fmt.Errorf("--merge needs --body or --body-file: the edit to merge")
+		}
+
+		opts := confluence.UpdateOptions{ExpectVersion: pageUpdateExpectVersion, Merge: pageUpdateMerge, Draft: pageUpdateDraft}
+		page, err := client.UpdatePageWithOptionsContext(cmd.Context(), args[0], pageUpdateTitle, body, pageUpdateMessage, opts)
+		if err != nil {
+			return err
+		}
+
+		return outputResult(cmd, page)
+	},
+}
+
+// --- page publish ---
+
+var pagePublishMessage string
+
+var pagePublishCmd = &cobra.Command{
+	Use:   "publish <page-id>",
+	Short: "Publish a page's draft",
+	Long: `Make a draft live. A page created with page create --draft is published as
+version 1; on Cloud, edits staged on a published page with page update --draft
+become its next version. Fails if the page has no draft.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		page, err := client.PublishPageContext(cmd.Context(), args[0], pagePublishMessage)
 		if err != nil {
 			return err
 		}
@@ -97,17 +139,35 @@ var pageUpdateCmd = &cobra.Command{
 
 // --- page delete ---
 
+var pageDeleteRecursive bool
+
 var pageDeleteCmd = &cobra.Command{
 	Use:   "delete <page-id>",
 	Short: "Delete (trash) a page",
-	Args:  cobra.ExactArgs(1),
+	Long: `Move a page to the trash. Its children stay and move up to its parent.
+
+With --recursive the children are trashed too, deepest first, and a manifest
+of the trashed pages is printed in restore order (parents before children),
+along with the trash restore command that undoes the delete.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := buildConfluenceClientFromConfig()
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
 		if err != nil {
 			return err
 		}
 
-		if err := client.DeletePage(args[0]); err != nil {
+		if pageDeleteRecursive {
+			manifest, err := client.DeletePageTreeContext(cmd.Context(), args[0])
+			if manifest != nil && (err == nil || len(manifest.Pages) > 0) {
+				if outErr := outputResult(cmd, manifest); outErr != nil {
+					return outErr
+				}
+				printRestoreHint(cmd, manifest)
+			}
+			return err
+		}
+
+		if err := client.DeletePageContext(cmd.Context(), args[0]); err != nil {
 			return err
 		}
 
@@ -125,12 +185,206 @@ var pageGetCmd = &cobra.Command{
 	Short: "Get a page by ID",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := buildConfluenceClientFromConfig()
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		page, err := client.GetPageContext(cmd.Context(), args[0], pageGetBody)
+		if err != nil {
+			return err
+		}
+
+		return outputResult(cmd, page)
+	},
+}
+
+// --- page move ---
+
+var (
+	pageMoveParent string
+	pageMoveSpace  string
+	pageMoveBefore string
+	pageMoveAfter  string
+)
+
+var pageMoveCmd = &cobra.Command{
+	Use:   "move <page-id>",
+	Short: "Move a page (with its children) under a new parent or next to a sibling",
+	Long: `Move a page, with its children, to a new place in the page tree. --parent makes
+it the last child of that page; --before or --after places it next to a sibling
+instead. Moving under a page in another space moves the whole subtree there;
+--space names the space you expect the destination to be in and fails if it
+isn't.
+
+Prints the moved page and its new ancestor chain. A title already used in the
+destination space is reported as a warning (Confluence rejects the move).`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		position, target := confluence.MoveAppend, pageMoveParent
+		switch {
+		case pageMoveBefore != "":
+			position, target = confluence.MoveBefore, pageMoveBefore
+		case pageMoveAfter != "":
+			position, target = confluence.MoveAfter, pageMoveAfter
+		case pageMoveParent == "":
+			return fmt.Errorf("--parent is required (or --before/--after a sibling)")
+		}
+
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		checkParent := position != confluence.MoveAppend && pageMoveParent != ""
+		if checkParent || pageMoveSpace != "" {
+			dest, err := client.GetPageContext(cmd.Context(), target, false)
+			if err != nil {
+				return fmt.Errorf("reading destination page %s: %w", target, err)
+			}
+			if checkParent && dest.ParentID != pageMoveParent {
+				return fmt.Errorf("page %s is not a child of %s", target, pageMoveParent)
+			}
+			if pageMoveSpace != "" {
+				if err := checkDestinationSpace(cmd.Context(), client, dest, pageMoveSpace); err != nil {
+					return err
+				}
+			}
+		}
+
+		result, err := client.MovePageContext(cmd.Context(), args[0], position, target)
+		if err != nil {
+			return err
+		}
+		for _, w := range result.Warnings {
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s\n", w)
+		}
+
+		return outputResult(cmd, result)
+	},
+}
+
+// checkDestinationSpace fails unless dest is in the space with key want.
+func checkDestinationSpace(ctx context.Context, client *confluence.Client, dest *confluence.Page, want string) error {
+	key := dest.SpaceKey
+	if key == "" {
+		var err error
+		if key, err = client.SpaceKeyForIDContext(ctx, dest.SpaceID); err != nil {
+			return err
+		}
+	}
+	if !strings.EqualFold(key, want) {
+		return fmt.Errorf("destination page %s is in space %s, not %s", dest.ID, key, want)
+	}
+	return nil
+}
+
+// --- page copy ---
+
+var (
+	pageCopyParent      string
+	pageCopySpace       string
+	pageCopyRecursive   bool
+	pageCopyTitlePrefix string
+	pageCopyAttachments bool
+)
+
+var pageCopyCmd = &cobra.Command{
+	Use:   "copy <page-id>",
+	Short: "Copy a page, or a whole subtree, under another parent",
+	Long: `Copy a page under --to-parent, which may be in another space; --space names the
+space you expect that parent to be in and fails if it isn't. --recursive copies
+the page's children too, keeping the tree's shape.
+
+Copies keep the body and labels, and with --attachments the attachments where
+the instance can copy them. Every copied title gets --title-prefix; within the
+same space, where titles must differ, it defaults to "Copy of ". Links between
+copied pages are rewritten to point at the copies.
+
+Prints the new root page ID and a map of source page IDs to copy IDs. If a
+copy fails part way, the pages copied so far are still printed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if pageCopyParent == "" {
+			return fmt.Errorf("--to-parent is required")
+		}
+
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		if pageCopySpace != "" {
+			dest, err := client.GetPageContext(cmd.Context(), pageCopyParent, false)
+			if err != nil {
+				return fmt.Errorf("reading destination page %s: %w", pageCopyParent, err)
+			}
+			if err := checkDestinationSpace(cmd.Context(), client, dest, pageCopySpace); err != nil {
+				return err
+			}
+		}
+
+		result, err := client.CopyPageContext(cmd.Context(), args[0], pageCopyParent, confluence.CopyOptions{
+			Recursive:   pageCopyRecursive,
+			TitlePrefix: pageCopyTitlePrefix,
+			Attachments: pageCopyAttachments,
+		})
+		if result != nil {
+			for _, w := range result.Warnings {
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s\n", w)
+			}
+		}
+		if err != nil {
+			if result != nil && len(result.IDs) > 0 {
+				if outErr := outputResult(cmd, result); outErr != nil {
+					return outErr
+				}
+			}
+			return err
+		}
+
+		return outputResult(cmd, result)
+	},
+}
+
+// --- page restore ---
+
+var (
+	pageRestoreVersion       int
+	pageRestoreExpectVersion int
+	pageRestoreForce         bool
+	pageRestoreMessage       string
+)
+
+var pageRestoreCmd = &cobra.Command{
+	Use:   "restore <page-id>",
+	Short: "Publish an earlier version of a page as its new current version",
+	Long: `Publish the title and body of an earlier version as a new version, like
+"Restore this version" in the page history. Nothing is lost: the versions in
+between stay in the history.
+
+Pass the current version you inspected (from history() or page get) with
+--expect-version; the restore fails with exit code 6 if the page has been
+edited since. --force skips that check.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if pageRestoreVersion < 1 {
+			return fmt.Errorf("--version is required: the version to restore")
+		}
+		if pageRestoreExpectVersion == 0 && !pageRestoreForce {
+			return fmt.Errorf("pass --expect-version with the current version you inspected, or --force to restore regardless of newer edits")
+		}
+
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
 		if err != nil {
 			return err
 		}
 
-		page, err := client.GetPage(args[0], pageGetBody)
+		opts := confluence.RestoreOptions{Message: pageRestoreMessage}
+		if !pageRestoreForce {
+			opts.ExpectVersion = pageRestoreExpectVersion
+		}
+		page, err := client.RestorePageVersionContext(cmd.Context(), args[0], pageRestoreVersion, opts)
 		if err != nil {
 			return err
 		}
@@ -139,6 +393,76 @@ var pageGetCmd = &cobra.Command{
 	},
 }
 
+// --- page diff ---
+
+var (
+	pageDiffFrom      int
+	pageDiffTo        int
+	pageDiffFile      string
+	pageDiffNormalize bool
+	pageDiffBlocks    bool
+	pageDiffContext   int
+)
+
+var pageDiffCmd = &cobra.Command{
+	Use:   "diff <page-id>",
+	Short: "Compare two versions of a page, or a version and a local file",
+	Long: `Compare the storage bodies of two versions of a page, or of one version and
+a local file. Bodies are split so each block-level element (paragraph, list
+item, table cell, macro) sits on its own line.
+
+Without flags the current version is compared with the one before it.
+--from defaults to the version before --to, and --to to the current version.
+With --file, --from names the version to compare the file against (default:
+current).
+
+--normalize ignores cosmetic differences: whitespace runs and attribute order
+and quoting. --blocks prints the changed blocks as JSON instead of a unified diff.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if pageDiffFile != "" && pageDiffTo != 0 {
+			return fmt.Errorf("--file and --to can't be combined: the file is the newer side")
+		}
+		if pageDiffFrom < 0 || pageDiffTo < 0 {
+			return fmt.Errorf("version numbers must be positive")
+		}
+
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		var diff *confluence.PageDiff
+		var toLabel string
+		if pageDiffFile != "" {
+			data, err := os.ReadFile(pageDiffFile)
+			if err != nil {
+				return fmt.Errorf("reading file: %w", err)
+			}
+			if diff, err = client.DiffPageBodyContext(cmd.Context(), args[0], pageDiffFrom, string(data), pageDiffNormalize); err != nil {
+				return err
+			}
+			toLabel = pageDiffFile
+		} else {
+			if diff, err = client.DiffPageVersionsContext(cmd.Context(), args[0], pageDiffFrom, pageDiffTo, pageDiffNormalize); err != nil {
+				return err
+			}
+			toLabel = fmt.Sprintf("%s v%d", diff.PageID, diff.ToVersion)
+		}
+
+		if pageDiffBlocks {
+			return outputResult(cmd, diff)
+		}
+		fromLabel := fmt.Sprintf("%s v%d", diff.PageID, diff.FromVersion)
+		if !diff.Changed() {
+			fmt.Fprintf(cmd.ErrOrStderr(), "No differences between %s and %s\n", fromLabel, toLabel)
+			return nil
+		}
+		fmt.Fprint(cmd.OutOrStdout(), diff.Unified(fromLabel, toLabel, pageDiffContext))
+		return nil
+	},
+}
+
 func outputResult(cmd *cobra.Command, v interface{}) error {
 	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
@@ -154,17 +478,56 @@ func init() {
 	pageCreateCmd.Flags().StringVar(&pageCreateBody, "body", "", "Page body (storage format)")
 	pageCreateCmd.Flags().StringVar(&pageCreateBodyFile, "body-file", "", "Read body from file")
 	pageCreateCmd.Flags().StringVar(&pageCreateParent, "parent", "", "Parent page ID")
+	pageCreateCmd.Flags().BoolVar(&pageCreateDraft, "draft", false, "Create an unpublished draft (publish it with page publish)")
 
 	pageUpdateCmd.Flags().StringVar(&pageUpdateTitle, "title", "", "New title")
 	pageUpdateCmd.Flags().StringVar(&pageUpdateBody, "body", "", "New body (storage format)")
 	pageUpdateCmd.Flags().StringVar(&pageUpdateBodyFile, "body-file", "", "Read body from file")
 	pageUpdateCmd.Flags().StringVar(&pageUpdateMessage, "message", "", "Version message")
+	pageUpdateCmd.Flags().IntVar(&pageUpdateExpectVersion, "expect-version", 0, "Fail if the page is no longer at this version (the one your edit is based on)")
+	pageUpdateCmd.Flags().BoolVar(&pageUpdateMerge, "merge", false, "With --expect-version: three-way merge with newer changes, writing only if clean")
+	pageUpdateCmd.Flags().BoolVar(&pageUpdateDraft, "draft", false, "Save the edit as a draft for review instead of publishing it")
+	pageUpdateCmd.MarkFlagsMutuallyExclusive("draft", "expect-version")
+	pageUpdateCmd.MarkFlagsMutuallyExclusive("draft", "merge")
+
+	pagePublishCmd.Flags().StringVar(&pagePublishMessage, "message", "", "Version message")
+
+	pageDeleteCmd.Flags().BoolVar(&pageDeleteRecursive, "recursive", false, "Also trash all descendants and print a restore manifest")
 
 	pageGetCmd.Flags().BoolVar(&pageGetBody, "body", false, "Include page body in response")
 
+	pageDiffCmd.Flags().IntVar(&pageDiffFrom, "from", 0, "Older version (default: the one before --to)")
+	pageDiffCmd.Flags().IntVar(&pageDiffTo, "to", 0, "Newer version (default: current)")
+	pageDiffCmd.Flags().StringVar(&pageDiffFile, "file", "", "Compare against this local storage-format file instead of --to")
+	pageDiffCmd.Flags().BoolVar(&pageDiffNormalize, "normalize", false, "Ignore whitespace and attribute order differences")
+	pageDiffCmd.Flags().BoolVar(&pageDiffBlocks, "blocks", false, "Print changed blocks as JSON instead of a unified diff")
+	pageDiffCmd.Flags().IntVar(&pageDiffContext, "context", 3, "Unchanged lines shown around each change")
+
+	pageMoveCmd.Flags().StringVar(&pageMoveParent, "parent", "", "New parent page ID")
+	pageMoveCmd.Flags().StringVar(&pageMoveSpace, "space", "", "Space the destination must be in")
+	pageMoveCmd.Flags().StringVar(&pageMoveBefore, "before", "", "Place the page just before this sibling")
+	pageMoveCmd.Flags().StringVar(&pageMoveAfter, "after", "", "Place the page just after this sibling")
+	pageMoveCmd.MarkFlagsMutuallyExclusive("before", "after")
+
+	pageCopyCmd.Flags().StringVar(&pageCopyParent, "to-parent", "", "Page to copy under")
+	pageCopyCmd.Flags().StringVar(&pageCopySpace, "space", "", "Space the destination parent must be in")
+	pageCopyCmd.Flags().BoolVar(&pageCopyRecursive, "recursive", false, "Copy the page's children too")
+	pageCopyCmd.Flags().StringVar(&pageCopyTitlePrefix, "title-prefix", "", "Prefix for copied titles (default \"Copy of \" within the same space)")
+	pageCopyCmd.Flags().BoolVar(&pageCopyAttachments, "attachments", false, "Copy attachments too, where the instance supports it")
+
+	pageRestoreCmd.Flags().IntVar(&pageRestoreVersion, "version", 0, "Version to restore")
+	pageRestoreCmd.Flags().IntVar(&pageRestoreExpectVersion, "expect-version", 0, "Fail if the page is no longer at this version (the one you inspected)")
+	pageRestoreCmd.Flags().BoolVar(&pageRestoreForce, "force", false, "Restore even if the page changed since you inspected it")
+	pageRestoreCmd.Flags().StringVar(&pageRestoreMessage, "message", "", "Version message (default: \"Restored version N\")")
+
 	pageCmd.AddCommand(pageCreateCmd)
 	pageCmd.AddCommand(pageUpdateCmd)
+	pageCmd.AddCommand(pagePublishCmd)
 	pageCmd.AddCommand(pageDeleteCmd)
 	pageCmd.AddCommand(pageGetCmd)
+	pageCmd.AddCommand(pageDiffCmd)
+	pageCmd.AddCommand(pageRestoreCmd)
+	pageCmd.AddCommand(pageMoveCmd)
+	pageCmd.AddCommand(pageCopyCmd)
 	rootCmd.AddCommand(pageCmd)
 }
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var pagePropCmd = &cobra.Command{
+	Use:   "prop",
+	Short: "Page properties: JSON metadata stored outside the body (list, get, set, delete)",
+}
+
+var pagePropListCmd = &cobra.Command{
+	Use:   "list <page-id>",
+	Short: "List a page's properties",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		props, err := client.ListPagePropertiesContext(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+
+		return outputResult(cmd, props)
+	},
+}
+
+var pagePropGetCmd = &cobra.Command{
+	Use:   "get <page-id> <key>",
+	Short: "Get one page property",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		prop, err := client.GetPagePropertyContext(cmd.Context(), args[0], args[1])
+		if err != nil {
+			return err
+		}
+
+		return outputResult(cmd, prop)
+	},
+}
+
+var (
+	pagePropSetFile   string
+	pagePropSetString bool
+)
+
+var pagePropSetCmd = &cobra.Command{
+	Use:   "set <page-id> <key> [value]",
+	Short: "Create or update a page property",
+	Long: `Store a value under key, creating the property or updating it as its next
+version. The value is taken as JSON when it parses as JSON and as a string
+otherwise; --string always stores a string (e.g. for an all-digit commit SHA).`,
+	Example: `  confluence-mgmt page prop set 12345 owner '{"team":"platform"}'
+  confluence-mgmt page prop set 12345 review-date 2026-11-01
+  confluence-mgmt page prop set 12345 source-commit 1234567 --string
+  confluence-mgmt page prop set 12345 build --value-file build.json`,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var raw string
+		switch {
+		case len(args) == 3 && pagePropSetFile != "":
+			return fmt.Errorf("give the value as an argument or with --value-file, not both")
+		case len(args) == 3:
+			raw = args[2]
+		case pagePropSetFile != "":
+			data, err := os.ReadFile(pagePropSetFile)
+			if err != nil {
+				return fmt.Errorf("reading value file: %w", err)
+			}
+			raw = string(data)
+		default:
+			return fmt.Errorf("value is required (as an argument or with --value-file)")
+		}
+
+		value := json.RawMessage(raw)
+		if pagePropSetString || !json.Valid(value) {
+			quoted, err := json.Marshal(raw)
+			if err != nil {
+				return err
+			}
+			value = quoted
+		}
+
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		prop, err := client.SetPagePropertyContext(cmd.Context(), args[0], args[1], value)
+		if err != nil {
+			return err
+		}
+
+		return outputResult(cmd, prop)
+	},
+}
+
+var pagePropDeleteCmd = &cobra.Command{
+	Use:   "delete <page-id> <key>",
+	Short: "Delete a page property",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		if err := client.DeletePagePropertyContext(cmd.Context(), args[0], args[1]); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Property %s deleted from page %s\n", args[1], args[0])
+		return nil
+	},
+}
+
+func init() {
+	pagePropSetCmd.Flags().StringVar(&pagePropSetFile, "value-file", "", "Read the value from file")
+	pagePropSetCmd.Flags().BoolVar(&pagePropSetString, "string", false, "Store the value as a JSON string even if it parses as JSON")
+
+	pagePropCmd.AddCommand(pagePropListCmd)
+	pagePropCmd.AddCommand(pagePropGetCmd)
+	pagePropCmd.AddCommand(pagePropSetCmd)
+	pagePropCmd.AddCommand(pagePropDeleteCmd)
+	pageCmd.AddCommand(pagePropCmd)
+}
@@ -24,17 +24,23 @@ func newQueryCommand() *cobra.Command {
 Operations:
   get(PAGE_ID)                      — Get page by ID
   get(space=KEY, title="Title")     — Get page by space+title
+  get(POST_ID, type=blogpost)       — Get blog post (also with space+title)
   list(space=KEY)                   — List pages in space
   list(space=KEY, label=NAME)       — List pages with label (CQL)
-  search("CQL query")              — CQL search
+  blogs(space=KEY)                  — List blog posts in space
+  drafts(space=KEY)                 — Unpublished drafts in space
+  search("CQL query")              — CQL search: {results, totalSize, start, limit}
   children(PAGE_ID)                 — Direct children
   ancestors(PAGE_ID)                — Breadcrumb chain
   tree(PAGE_ID)                     — Recursive children (default depth=3)
   tree(PAGE_ID, depth=5)            — Recursive children with depth
   spaces()                          — List all spaces
+  history(PAGE_ID, limit=N)         — Versions, newest first (number, author, date, message)
+  diff(PAGE_ID, from=N, to=M)       — Changed blocks between versions (normalize=true, format=unified)
   schema()                          — Show available operations, fields, presets
 
 Field presets: minimal, default, overview, full
+Extra fields: properties (page properties as {key: value}; one extra call per page, at most 50 pages)
 
 Examples:
   confluence-mgmt q 'spaces(){minimal}' --format json
@@ -42,15 +48,16 @@ Examples:
   confluence-mgmt q 'list(space=DEV){default}' --format json
   confluence-mgmt q 'search("type=page AND space=DEV AND text~\"API\""){default}' --format json
   confluence-mgmt q 'children(12345){minimal}; ancestors(12345){minimal}' --format json
+  confluence-mgmt q 'history(12345, limit=5)' --format json
   confluence-mgmt q 'schema()' --format json`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := buildConfluenceClientFromConfig()
+			client, err := buildConfluenceClientFromConfig(cmd.Context())
 			if err != nil {
 				return err
 			}
 
-			schema := query.NewSchema(client)
+			schema := query.NewSchemaContext(cmd.Context(), client)
 
 			mode, err := parseOutputMode(format)
 			if err != nil {
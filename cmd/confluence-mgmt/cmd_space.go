@@ -13,12 +13,12 @@ var spaceListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List accessible spaces",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := buildConfluenceClientFromConfig()
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
 		if err != nil {
 			return err
 		}
 
-		spaces, err := client.ListSpaces(0)
+		spaces, err := client.ListSpacesContext(cmd.Context(), 0)
 		if err != nil {
 			return err
 		}
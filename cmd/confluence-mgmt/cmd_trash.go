@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/relux-works/skill-confluence-management/internal/confluence"
+	"github.com/spf13/cobra"
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Trash operations (list, restore, purge)",
+}
+
+var trashListLimit int
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the pages in a space's trash",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if flagSpace == "" {
+			return fmt.Errorf("space is required (use --space flag or 'config set space')")
+		}
+
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		pages, err := client.ListTrashContext(cmd.Context(), flagSpace, trashListLimit)
+		if err != nil {
+			return err
+		}
+
+		return outputResult(cmd, pages)
+	},
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <page-id>...",
+	Short: "Restore trashed pages, in the order given",
+	Long: `Restore pages from the trash with their last title and body. A page returns
+under its old parent when that page is live and to the top of the space
+otherwise, so list parents before their children; the manifest printed by
+page delete --recursive is already in that order.
+
+Prints the restored pages. Stops at the first page that can't be restored.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		restored := []*confluence.Page{}
+		for _, id := range args {
+			page, err := client.RestoreFromTrashContext(cmd.Context(), id)
+			if err != nil {
+				if len(restored) > 0 {
+					if outErr := outputResult(cmd, restored); outErr != nil {
+						return outErr
+					}
+				}
+				return fmt.Errorf("restoring page %s: %w", id, err)
+			}
+			restored = append(restored, page)
+		}
+
+		return outputResult(cmd, restored)
+	},
+}
+
+var trashPurgeCmd = &cobra.Command{
+	Use:   "purge <page-id>...",
+	Short: "Permanently delete trashed pages",
+	Long: `Permanently delete pages that are already in the trash. This cannot be undone.
+Pages that are not in the trash are refused; delete them first.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := buildConfluenceClientFromConfig(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		for _, id := range args {
+			if err := client.PurgePageContext(cmd.Context(), id); err != nil {
+				return fmt.Errorf("purging page %s: %w", id, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Page %s purged\n", id)
+		}
+		return nil
+	},
+}
+
+// printRestoreHint tells the user how to undo a recursive delete.
+func printRestoreHint(cmd *cobra.Command, manifest *confluence.DeleteManifest) {
+	if len(manifest.Pages) > 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Restore with: confluence-mgmt trash restore %s\n", strings.Join(manifest.IDs(), " "))
+	}
+}
+
+func init() {
+	trashListCmd.Flags().IntVar(&trashListLimit, "limit", 0, "Maximum number of pages (0 = all)")
+
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashPurgeCmd)
+	rootCmd.AddCommand(trashCmd)
+}
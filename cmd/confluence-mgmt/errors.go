@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/relux-works/skill-confluence-management/internal/confluence"
+)
+
+// Exit codes let scripts and agents branch on the failure kind without parsing stderr.
+const (
+	exitError           = 1 // anything not covered below
+	exitNotFound        = 3
+	exitUnauthorized    = 4
+	exitForbidden       = 5
+	exitVersionConflict = 6
+	exitRateLimited     = 7
+	exitValidation      = 8
+	exitTimeout         = 9
+)
+
+// describeError returns the message to print for err, with a targeted hint
+// for known API failures, and the process exit code.
+func describeError(err error) (string, int) {
+	msg := err.Error()
+
+	var apiErr *confluence.APIError
+	errors.As(err, &apiErr)
+
+	switch {
+	case errors.Is(err, confluence.ErrUnauthorized):
+		var oauthErr *confluence.OAuthError
+		if errors.As(err, &oauthErr) {
+			return msg + "\n\nHint: the OAuth grant was revoked or its refresh token expired — sign in again with 'confluence-mgmt auth login --oauth'", exitUnauthorized
+		}
+		return msg + "\n\nHint: the credentials were rejected — check the token hasn't expired or been revoked, then run 'confluence-mgmt auth set-access'", exitUnauthorized
+	case errors.Is(err, confluence.ErrForbidden):
+		return msg + "\n\nHint: you are signed in but lack permission for this space or page — ask a space admin for access", exitForbidden
+	case errors.Is(err, confluence.ErrNotFound):
+		return msg + "\n\nHint: check the page ID or space key; Confluence also answers 404 when you can't view the content", exitNotFound
+	case errors.Is(err, confluence.ErrVersionConflict):
+		var mergeErr *confluence.MergeConflictError
+		if errors.As(err, &mergeErr) {
+			return msg + "\n\nHint: your edit and the newer changes touch the same content — fetch the page again and reapply your edit by hand", exitVersionConflict
+		}
+		return msg + "\n\nHint: the page was changed by someone else since it was read — fetch it again (history() and page diff show what changed) and retry; page update can instead --merge", exitVersionConflict
+	case errors.Is(err, confluence.ErrRateLimited):
+		hint := "\n\nHint: Confluence is throttling this account"
+		if apiErr != nil && apiErr.RetryAfter > 0 {
+			hint += fmt.Sprintf(" — retry in %s", apiErr.RetryAfter)
+		}
+		return msg + hint + "; lower rate_limit_rps or raise retry_max_attempts for bulk work", exitRateLimited
+	case errors.Is(err, confluence.ErrValidation):
+		if apiErr != nil && len(apiErr.Errors) > 1 {
+			var b strings.Builder
+			b.WriteString(msg)
+			b.WriteString("\n\nThe server rejected the request:")
+			for _, d := range apiErr.Errors {
+				b.WriteString("\n  - ")
+				b.WriteString(d.String())
+			}
+			return b.String(), exitValidation
+		}
+		return msg + "\n\nHint: the server rejected the request body or parameters — check titles, storage-format markup and IDs", exitValidation
+	case errors.Is(err, context.DeadlineExceeded):
+		return msg + "\n\nHint: the command hit --timeout; raise it or narrow the query", exitTimeout
+	}
+	return msg, exitError
+}
@@ -1,7 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/relux-works/skill-confluence-management/internal/config"
 	"github.com/relux-works/skill-confluence-management/internal/confluence"
@@ -21,8 +25,9 @@ var getCredentialResolver = func() *config.Resolver {
 	)
 }
 
-// buildConfluenceClientFromConfig creates a Confluence client from stored config and credentials.
-func buildConfluenceClientFromConfig() (*confluence.Client, error) {
+// buildConfluenceClientFromConfig creates a Confluence client from stored
+// config and credentials. ctx bounds the instance probe run on first use.
+func buildConfluenceClientFromConfig(ctx context.Context) (*confluence.Client, error) {
 	cfgMgr, err := config.NewConfigManager()
 	if err != nil {
 		return nil, fmt.Errorf("config manager: %w", err)
@@ -45,18 +50,58 @@ func buildConfluenceClientFromConfig() (*confluence.Client, error) {
 	}
 
 	instanceType := cfg.InstanceType
-	if instanceType == "" {
+	if instanceType == "" || cfg.DetectedURL != instanceURL {
 		instanceType = string(inferInstanceType(resolved.Credentials.InstanceURL))
 	}
 
-	client, err := confluence.NewClient(confluence.Config{
+	retry, err := retryPolicyFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	rateLimit := rateLimitFromConfig(cfg)
+	spaces, err := spaceCacheFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	responses, err := responseCacheFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	clientCfg := confluence.Config{
 		BaseURL:            resolved.Credentials.InstanceURL,
 		Email:              resolved.Credentials.Email,
 		Token:              resolved.Credentials.APIToken,
 		InstanceType:       confluence.InstanceType(instanceType),
 		AuthType:           confluence.AuthType(resolved.Credentials.AuthType),
 		InsecureSkipVerify: flagInsecure || cfg.TLSSkipVerify,
-	})
+		CABundle:           cfg.TLSCABundle,
+		ClientCert:         cfg.TLSClientCert,
+		ClientKey:          cfg.TLSClientKey,
+		Proxy:              proxyFromConfig(cfg, resolved.Credentials),
+		OAuth:              oauthFromCredentials(resolved.Credentials, persistSourceFor(resolved)),
+		RetryPolicy:        &retry,
+		RateLimit:          &rateLimit,
+		SpaceCache:         spaces,
+		ResponseCache:      responses,
+		OnRetry:            verboseRetryLogger(),
+		OnTrace:            traceHook(),
+	}
+
+	// Probe an instance once; later runs reuse what config.yaml recorded.
+	if cfg.DetectedURL == instanceURL {
+		clientCfg.Capabilities = capabilitiesFromNames(cfg.Capabilities)
+	} else if info, err := detectInstance(ctx, clientCfg); err == nil {
+		clientCfg.InstanceType = info.Type
+		clientCfg.Capabilities = info.AssumedCapabilities()
+		if err := storeDetectedInstance(cfgMgr, instanceURL, info); err == nil {
+			cfg.InstanceType = string(info.Type)
+		}
+	} else if flagVerbose {
+		fmt.Fprintf(os.Stderr, "confluence-mgmt: could not detect the instance type, assuming %s: %v\n", instanceType, err)
+	}
+
+	client, err := confluence.NewClient(clientCfg)
 	if err != nil {
 		return nil, err
 	}
@@ -70,3 +115,276 @@ func buildConfluenceClientFromConfig() (*confluence.Client, error) {
 
 	return client, nil
 }
+
+// detectInstance probes the instance described by clientCfg. It keeps the
+// configured retry policy, so a rate-limited or briefly failing endpoint is
+// retried rather than mistaken for a missing API; a probe that still fails
+// returns an error and nothing is stored.
+func detectInstance(ctx context.Context, clientCfg confluence.Config) (*confluence.InstanceInfo, error) {
+	clientCfg.ResponseCache = nil
+	client, err := confluence.NewClient(clientCfg)
+	if err != nil {
+		return nil, err
+	}
+	return client.DetectInstanceContext(ctx)
+}
+
+// storeDetectedInstance records info as the probe result for instanceURL.
+// An incomplete result isn't recorded, so the next command probes again.
+func storeDetectedInstance(cfgMgr *config.ConfigManager, instanceURL string, info *confluence.InstanceInfo) error {
+	if !info.Complete() {
+		return incompleteProbeError(info)
+	}
+	return cfgMgr.SetDetectedInstance(instanceURL, string(info.Type), info.Version, capabilityNames(info.Capabilities))
+}
+
+// incompleteProbeError explains why an incomplete probe result isn't recorded.
+func incompleteProbeError(info *confluence.InstanceInfo) error {
+	return fmt.Errorf("not recorded: could not determine %s (no visible page to ask about); the next command probes again",
+		strings.Join(capabilityNames(info.Undetermined), ", "))
+}
+
+func capabilityNames(caps []confluence.Capability) []string {
+	names := make([]string, 0, len(caps))
+	for _, c := range caps {
+		names = append(names, string(c))
+	}
+	return names
+}
+
+// capabilitiesFromNames converts stored capability names; none (e.g. a
+// pinned instance_type) yields nil so the client falls back to the type.
+func capabilitiesFromNames(names []string) []confluence.Capability {
+	if len(names) == 0 {
+		return nil
+	}
+	caps := make([]confluence.Capability, 0, len(names))
+	for _, name := range names {
+		caps = append(caps, confluence.Capability(name))
+	}
+	return caps
+}
+
+// describeCapabilities renders stored capability names for status output.
+func describeCapabilities(names []string) string {
+	if len(names) == 0 {
+		return "(not detected)"
+	}
+	return strings.Join(names, ", ")
+}
+
+// oauthFromCredentials returns the client OAuth settings for creds, or nil
+// when they aren't OAuth credentials. Rotated tokens are written back to
+// source; an empty source (env credentials) keeps them in memory only.
+func oauthFromCredentials(creds config.Credentials, source config.Source) *confluence.OAuthConfig {
+	if creds.AuthType != config.AuthTypeOAuth {
+		return nil
+	}
+	oauth := &confluence.OAuthConfig{
+		ClientID:     creds.OAuthClientID,
+		ClientSecret: creds.OAuthClientSecret,
+		CloudID:      creds.CloudID,
+		RefreshToken: creds.RefreshToken,
+		Expiry:       creds.TokenExpiry,
+		AccountID:    creds.AccountID,
+	}
+	if source != "" {
+		oauth.OnRefresh = func(tok confluence.OAuthToken) {
+			updated := creds
+			updated.APIToken = tok.AccessToken
+			updated.RefreshToken = tok.RefreshToken
+			updated.TokenExpiry = tok.Expiry
+			if _, err := getCredentialResolver().SetAccess(source, updated); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: could not store the refreshed OAuth token: %v\n", err)
+			}
+		}
+	}
+	return oauth
+}
+
+// persistSourceFor returns the backend refreshed OAuth tokens are written
+// back to, or "" when the credentials came from the environment.
+func persistSourceFor(resolved config.ResolvedCredentials) config.Source {
+	if resolved.ResolvedFrom == "env" {
+		return ""
+	}
+	return resolved.Source
+}
+
+// proxyFromConfig overlays the credential profile's proxy settings on the
+// config.yaml defaults, field by field. It returns nil when neither names a
+// proxy, which leaves HTTP_PROXY/HTTPS_PROXY/NO_PROXY in charge.
+func proxyFromConfig(cfg config.Config, creds config.Credentials) *confluence.ProxyConfig {
+	proxy := &confluence.ProxyConfig{
+		URL:      firstSet(creds.ProxyURL, cfg.ProxyURL),
+		Username: firstSet(creds.ProxyUsername, cfg.ProxyUsername),
+		Password: firstSet(creds.ProxyPassword, os.Getenv(config.EnvProxyPassword)),
+		NoProxy:  firstSet(creds.NoProxy, cfg.NoProxy),
+	}
+	if proxy.URL == "" {
+		return nil
+	}
+	return proxy
+}
+
+// describeProxy summarises a proxy setting for diagnostics, without the password.
+func describeProxy(proxy *confluence.ProxyConfig) string {
+	if proxy == nil {
+		return "from environment (HTTP_PROXY/HTTPS_PROXY/NO_PROXY)"
+	}
+	desc := proxy.URL
+	if proxy.Username != "" {
+		desc += " as " + proxy.Username
+	}
+	if proxy.NoProxy != "" {
+		desc += ", bypassed for " + proxy.NoProxy
+	}
+	return desc
+}
+
+func firstSet(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// retryPolicyFromConfig overlays the retry_* config keys on the client defaults.
+func retryPolicyFromConfig(cfg config.Config) (confluence.RetryPolicy, error) {
+	policy := confluence.DefaultRetryPolicy()
+	if cfg.RetryMaxAttempts > 0 {
+		policy.MaxAttempts = cfg.RetryMaxAttempts
+	}
+	if cfg.RetryBaseDelay != "" {
+		d, err := time.ParseDuration(cfg.RetryBaseDelay)
+		if err != nil {
+			return policy, fmt.Errorf("invalid retry_base_delay %q in config: %w", cfg.RetryBaseDelay, err)
+		}
+		policy.BaseDelay = d
+	}
+	if cfg.RetryMaxDelay != "" {
+		d, err := time.ParseDuration(cfg.RetryMaxDelay)
+		if err != nil {
+			return policy, fmt.Errorf("invalid retry_max_delay %q in config: %w", cfg.RetryMaxDelay, err)
+		}
+		policy.MaxDelay = d
+	}
+	if cfg.RetryJitter != nil {
+		policy.Jitter = *cfg.RetryJitter
+	}
+	if cfg.RetryHonorRetryAfter != nil {
+		policy.HonorRetryAfter = *cfg.RetryHonorRetryAfter
+	}
+	return policy, nil
+}
+
+// rateLimitFromConfig overlays the rate_limit_* config keys on the client defaults.
+func rateLimitFromConfig(cfg config.Config) confluence.RateLimit {
+	rl := confluence.DefaultRateLimit()
+	if cfg.RateLimitRPS != nil {
+		rl.RequestsPerSecond = *cfg.RateLimitRPS
+	}
+	if cfg.RateLimitBurst > 0 {
+		rl.Burst = cfg.RateLimitBurst
+	}
+	if cfg.RateLimitWriteRPS != nil {
+		rl.WritesPerSecond = *cfg.RateLimitWriteRPS
+	}
+	if cfg.RateLimitWriteBurst > 0 {
+		rl.WriteBurst = cfg.RateLimitWriteBurst
+	}
+	return rl
+}
+
+// spaceCacheTTLFromConfig returns the configured space cache TTL; 0 means the cache isn't persisted.
+func spaceCacheTTLFromConfig(cfg config.Config) (time.Duration, error) {
+	if cfg.SpaceCacheTTL == "" {
+		return confluence.DefaultSpaceCacheTTL, nil
+	}
+	ttl, err := time.ParseDuration(cfg.SpaceCacheTTL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid space_cache_ttl %q in config: %w", cfg.SpaceCacheTTL, err)
+	}
+	return ttl, nil
+}
+
+// spaceCacheFromConfig opens the on-disk space key cache, or returns nil
+// (in-memory only) when it is disabled.
+func spaceCacheFromConfig(cfg config.Config) (*confluence.SpaceCache, error) {
+	ttl, err := spaceCacheTTLFromConfig(cfg)
+	if err != nil || ttl <= 0 {
+		return nil, err
+	}
+	path, err := config.SpaceCachePath()
+	if err != nil {
+		return nil, err
+	}
+	return confluence.NewSpaceCache(path, ttl), nil
+}
+
+// activeResponseCache is the response cache used by this invocation, if any;
+// main flushes its hit/miss counters on exit.
+var activeResponseCache *confluence.ResponseCache
+
+// responseCacheFromConfig opens the on-disk response cache unless --no-cache
+// or response_cache=false turned it off.
+func responseCacheFromConfig(cfg config.Config) (*confluence.ResponseCache, error) {
+	if flagNoCache || (cfg.ResponseCache != nil && !*cfg.ResponseCache) {
+		return nil, nil
+	}
+	dir, err := config.CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	activeResponseCache = confluence.NewResponseCache(dir)
+	return activeResponseCache, nil
+}
+
+// harRecorder collects requests for --har; main writes it out on exit.
+var harRecorder *confluence.HARRecorder
+
+// traceHook returns the OnTrace hook for --trace and --har, or nil when neither is set.
+func traceHook() func(confluence.TraceEntry) {
+	if !flagTrace && flagHAR == "" {
+		return nil
+	}
+	if flagHAR != "" && harRecorder == nil {
+		harRecorder = confluence.NewHARRecorder("confluence-mgmt", version)
+	}
+	return func(e confluence.TraceEntry) {
+		if flagTrace {
+			fmt.Fprintln(os.Stderr, confluence.FormatTrace(e))
+		}
+		if harRecorder != nil {
+			harRecorder.Record(e)
+		}
+	}
+}
+
+// verboseRetryLogger returns an OnRetry hook that reports retries on stderr when --verbose is set.
+func verboseRetryLogger() func(confluence.RetryEvent) {
+	if !flagVerbose {
+		return nil
+	}
+	return func(ev confluence.RetryEvent) {
+		cause := "transport error"
+		if ev.StatusCode != 0 {
+			cause = fmt.Sprintf("HTTP %d", ev.StatusCode)
+		}
+		source := "backoff"
+		if ev.FromServer {
+			source = "Retry-After"
+		}
+		fmt.Fprintf(os.Stderr, "retry %d/%d %s %s: %s, waiting %s (%s)",
+			ev.Attempt, ev.MaxAttempts-1, ev.Method, ev.Path, cause, ev.Delay.Round(time.Millisecond), source)
+		if ev.RateLimit.Remaining != "" {
+			fmt.Fprintf(os.Stderr, ", rate limit remaining %s", ev.RateLimit.Remaining)
+		}
+		if ev.RateLimit.Reason != "" {
+			fmt.Fprintf(os.Stderr, ", reason %s", ev.RateLimit.Reason)
+		}
+		fmt.Fprintln(os.Stderr)
+	}
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/relux-works/skill-confluence-management/internal/config"
 	"github.com/spf13/cobra"
@@ -21,12 +25,39 @@ var (
 	flagSpace    string
 	flagFormat   string
 	flagInsecure bool
+	flagTimeout  time.Duration
+	flagVerbose  bool
+	flagNoCache  bool
+	flagTrace    bool
+	flagHAR      string
 )
 
+// cancelTimeout releases the --timeout context; set by persistentPreRun.
+var cancelTimeout context.CancelFunc = func() {}
+
 func main() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	// First Ctrl-C cancels in-flight requests; a second one kills the process.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		// Restore the default handlers once the first signal has landed.
+		<-ctx.Done()
+		stop()
+	}()
+	err := rootCmd.ExecuteContext(ctx)
+	cancelTimeout()
+	if activeResponseCache != nil {
+		_ = activeResponseCache.FlushStats()
+	}
+	if harRecorder != nil {
+		if harErr := harRecorder.WriteFile(flagHAR); harErr != nil {
+			fmt.Fprintln(os.Stderr, harErr)
+		}
+	}
+	stop()
+	if err != nil {
+		msg, code := describeError(err)
+		fmt.Fprintln(os.Stderr, msg)
+		os.Exit(code)
 	}
 }
 
@@ -51,6 +82,11 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&flagSpace, "space", "", "Confluence space key (overrides config)")
 	rootCmd.PersistentFlags().StringVar(&flagFormat, "format", "json", "Output format: json, compact, or text")
 	rootCmd.PersistentFlags().BoolVar(&flagInsecure, "insecure", false, "Skip TLS certificate verification (for corporate CAs)")
+	rootCmd.PersistentFlags().BoolVarP(&flagVerbose, "verbose", "v", false, "Log retries and rate-limit waits to stderr")
+	rootCmd.PersistentFlags().BoolVar(&flagNoCache, "no-cache", false, "Bypass the on-disk response cache for this command")
+	rootCmd.PersistentFlags().BoolVar(&flagTrace, "trace", false, "Log every HTTP request and response to stderr (credentials redacted)")
+	rootCmd.PersistentFlags().StringVar(&flagHAR, "har", "", "Write all HTTP traffic to a HAR 1.2 file (credentials redacted)")
+	rootCmd.PersistentFlags().DurationVar(&flagTimeout, "timeout", 0, "Abort the whole command after this long, e.g. 30s or 2m (0 = no limit)")
 
 	rootCmd.AddCommand(versionCmd)
 }
@@ -58,6 +94,12 @@ func init() {
 func persistentPreRun(cmd *cobra.Command, args []string) error {
 	loadConfigDefaults(cmd)
 
+	if flagTimeout > 0 {
+		ctx, cancel := context.WithTimeout(cmd.Context(), flagTimeout)
+		cmd.SetContext(ctx)
+		cancelTimeout = cancel
+	}
+
 	if shouldSkipAuthCheck(cmd) {
 		return nil
 	}
@@ -72,7 +114,7 @@ func shouldSkipAuthCheck(cmd *cobra.Command) bool {
 
 	for current := cmd; current != nil; current = current.Parent() {
 		switch current.Name() {
-		case "auth", "config", "help", "completion", "version":
+		case "auth", "config", "cache", "dev", "help", "completion", "version":
 			return true
 		}
 	}
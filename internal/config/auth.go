@@ -9,6 +9,7 @@ import (
 	"runtime"
 	"sort"
 	"strings"
+	"time"
 )
 
 const (
@@ -20,6 +21,18 @@ const (
 	EnvEmail       = "CONFLUENCE_MGMT_EMAIL"
 	EnvAPIToken    = "CONFLUENCE_MGMT_API_TOKEN"
 	EnvAuthType    = "CONFLUENCE_MGMT_AUTH_TYPE"
+
+	// EnvOAuthClientID and EnvOAuthClientSecret identify the OAuth app for
+	// 'auth login --oauth' when the flags are omitted.
+	EnvOAuthClientID     = "CONFLUENCE_MGMT_OAUTH_CLIENT_ID"
+	EnvOAuthClientSecret = "CONFLUENCE_MGMT_OAUTH_CLIENT_SECRET"
+
+	// AuthTypeOAuth marks credentials obtained through 'auth login --oauth'.
+	AuthTypeOAuth = "oauth"
+
+	// EnvProxyPassword supplies the proxy password when it isn't stored in
+	// the credential profile, e.g. for the default proxy in config.yaml.
+	EnvProxyPassword = "CONFLUENCE_MGMT_PROXY_PASSWORD"
 )
 
 // Source identifies the credential backend.
@@ -36,7 +49,22 @@ type Credentials struct {
 	InstanceURL string `json:"instance_url"`
 	Email       string `json:"email,omitempty"` // Required for Basic auth (Cloud), empty for Bearer (Server/DC PAT)
 	APIToken    string `json:"api_token"`
-	AuthType    string `json:"auth_type,omitempty"` // "basic" or "bearer"
+	AuthType    string `json:"auth_type,omitempty"` // "basic", "bearer" or "oauth"; for oauth APIToken is the access token
+
+	// OAuth 2.0 (3LO) grant, set by 'auth login --oauth'. The refresh token
+	// rotates on every refresh, so the store is rewritten each time.
+	RefreshToken      string    `json:"refresh_token,omitempty"`
+	TokenExpiry       time.Time `json:"token_expiry,omitzero"`
+	OAuthClientID     string    `json:"oauth_client_id,omitempty"`
+	OAuthClientSecret string    `json:"oauth_client_secret,omitempty"`
+	CloudID           string    `json:"cloud_id,omitempty"`
+	AccountID         string    `json:"account_id,omitempty"` // signed-in account; scopes the response cache
+
+	// Per-instance proxy; overrides the proxy settings in config.yaml.
+	ProxyURL      string `json:"proxy_url,omitempty"`
+	ProxyUsername string `json:"proxy_username,omitempty"`
+	ProxyPassword string `json:"proxy_password,omitempty"`
+	NoProxy       string `json:"no_proxy,omitempty"`
 }
 
 // Validate checks that all required credential fields are populated.
@@ -45,6 +73,15 @@ func (c Credentials) Validate() error {
 	if normalized.InstanceURL == "" {
 		return errors.New("instance URL is required")
 	}
+	if normalized.AuthType == AuthTypeOAuth {
+		if normalized.APIToken == "" && normalized.RefreshToken == "" {
+			return errors.New("OAuth access or refresh token is required")
+		}
+		if normalized.OAuthClientID == "" || normalized.CloudID == "" {
+			return errors.New("OAuth client ID and cloud ID are required (run 'confluence-mgmt auth login --oauth')")
+		}
+		return nil
+	}
 	if normalized.APIToken == "" {
 		return errors.New("API token is required")
 	}
@@ -614,6 +651,9 @@ func normalizeCredentials(creds Credentials) Credentials {
 	creds.Email = strings.TrimSpace(creds.Email)
 	creds.APIToken = strings.TrimSpace(creds.APIToken)
 	creds.AuthType = normalizeAuthType(strings.TrimSpace(creds.AuthType), creds.Email)
+	creds.ProxyURL = strings.TrimSpace(creds.ProxyURL)
+	creds.ProxyUsername = strings.TrimSpace(creds.ProxyUsername)
+	creds.NoProxy = strings.TrimSpace(creds.NoProxy)
 	return creds
 }
 
@@ -78,6 +78,24 @@ func TestCredentials_Validate(t *testing.T) {
 	}
 }
 
+func TestCredentials_ValidateOAuth(t *testing.T) {
+	creds := Credentials{
+		InstanceURL:   "https://test.atlassian.net/wiki",
+		AuthType:      AuthTypeOAuth,
+		RefreshToken:  "refresh",
+		OAuthClientID: "client",
+		CloudID:       "cloud-1",
+	}
+	if err := creds.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	creds.CloudID = ""
+	if err := creds.Validate(); err == nil {
+		t.Fatal("Validate() without cloud ID should fail")
+	}
+}
+
 func TestKeychainStore_SaveAndLoad(t *testing.T) {
 	store, _ := newTestStore()
 	creds := validCreds()
@@ -182,6 +200,27 @@ func TestFileStore_SaveLoadDelete(t *testing.T) {
 	}
 }
 
+func TestFileStore_KeepsProfileProxy(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "auth.json"))
+	creds := validCreds()
+	creds.ProxyURL = " http://proxy.corp:3128 "
+	creds.ProxyUsername = "alice"
+	creds.ProxyPassword = "s3cret"
+	creds.NoProxy = ".corp.com"
+
+	if err := store.Save(creds); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := store.Load(creds.InstanceURL)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.ProxyURL != "http://proxy.corp:3128" || loaded.ProxyUsername != "alice" ||
+		loaded.ProxyPassword != "s3cret" || loaded.NoProxy != ".corp.com" {
+		t.Fatalf("proxy settings = %+v", loaded)
+	}
+}
+
 func TestResolverResolveAutoFallsBackToFileOnWindows(t *testing.T) {
 	path := filepath.Join(t.TempDir(), "auth.json")
 	store := NewFileStore(path)
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -14,6 +15,7 @@ const (
 	defaultConfigFileName  = "config.yaml"
 	defaultAuthFileName    = "auth.json"
 	defaultInstallFileName = "install.json"
+	spaceCacheFileName     = "space-cache.json"
 )
 
 // Config holds the global user configuration for confluence-mgmt.
@@ -23,6 +25,37 @@ type Config struct {
 	InstanceType  string `yaml:"instance_type,omitempty"`   // "cloud" or "server"
 	AuthType      string `yaml:"auth_type,omitempty"`       // "basic" or "bearer"
 	TLSSkipVerify bool   `yaml:"tls_skip_verify,omitempty"` // skip TLS certificate verification (corporate CAs)
+	TLSCABundle   string `yaml:"tls_ca_bundle,omitempty"`   // PEM file of extra CAs, added to the system pool
+	TLSClientCert string `yaml:"tls_client_cert,omitempty"` // PEM client certificate for mTLS gateways
+	TLSClientKey  string `yaml:"tls_client_key,omitempty"`  // PEM private key for tls_client_cert
+
+	// What probing the instance found. DetectedURL names the instance these
+	// fields describe; a different instance URL triggers a fresh probe.
+	ProductVersion string   `yaml:"product_version,omitempty"` // e.g. "8.5.4"; empty on Cloud
+	Capabilities   []string `yaml:"capabilities,omitempty"`    // e.g. v2, v1, content_properties
+	DetectedURL    string   `yaml:"detected_url,omitempty"`
+
+	// Default proxy for all instances; a credential profile's proxy settings
+	// take precedence. The password is never stored here (see EnvProxyPassword).
+	ProxyURL      string `yaml:"proxy_url,omitempty"`      // e.g. http://proxy.corp:3128; empty uses HTTP(S)_PROXY
+	ProxyUsername string `yaml:"proxy_username,omitempty"` // proxy auth user
+	NoProxy       string `yaml:"no_proxy,omitempty"`       // comma-separated hosts/domains/CIDRs reached directly
+
+	// Retry policy overrides; unset fields fall back to the client defaults.
+	RetryMaxAttempts     int      `yaml:"retry_max_attempts,omitempty"`      // total attempts including the first
+	RetryBaseDelay       string   `yaml:"retry_base_delay,omitempty"`        // Go duration, e.g. "500ms"
+	RetryMaxDelay        string   `yaml:"retry_max_delay,omitempty"`         // Go duration cap for any single wait
+	RetryJitter          *float64 `yaml:"retry_jitter,omitempty"`            // 0-1 fraction of the delay randomised
+	RetryHonorRetryAfter *bool    `yaml:"retry_honor_retry_after,omitempty"` // use Retry-After on 429/503
+
+	// Client-side rate limit overrides; a rate of 0 disables that bucket.
+	RateLimitRPS        *float64 `yaml:"rate_limit_rps,omitempty"`         // requests per second, all methods
+	RateLimitBurst      int      `yaml:"rate_limit_burst,omitempty"`       // back-to-back requests before pacing
+	RateLimitWriteRPS   *float64 `yaml:"rate_limit_write_rps,omitempty"`   // writes per second, on top of the overall rate
+	RateLimitWriteBurst int      `yaml:"rate_limit_write_burst,omitempty"` // back-to-back writes before pacing
+
+	SpaceCacheTTL string `yaml:"space_cache_ttl,omitempty"` // Go duration; "0s" disables the on-disk space key cache
+	ResponseCache *bool  `yaml:"response_cache,omitempty"`  // on-disk page/response cache; default on
 }
 
 // DefaultConfig returns a Config with sensible defaults.
@@ -66,6 +99,24 @@ func InstallStatePath() (string, error) {
 	return filepath.Join(configDir, defaultInstallFileName), nil
 }
 
+// CacheDir returns the per-user directory for cached API responses.
+func CacheDir() (string, error) {
+	baseDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("getting user cache dir: %w", err)
+	}
+	return filepath.Join(baseDir, AppName, "responses"), nil
+}
+
+// SpaceCachePath returns the on-disk space key/ID cache path.
+func SpaceCachePath() (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, spaceCacheFileName), nil
+}
+
 // ConfigManager handles reading and writing the config file.
 type ConfigManager struct {
 	configPath string
@@ -167,6 +218,36 @@ func (m *ConfigManager) SetInstanceType(instanceType string) error {
 	return m.saveConfig(cfg)
 }
 
+// SetDetectedInstance records what probing instanceURL found. An empty
+// capability list pins instanceType without claiming any capabilities.
+func (m *ConfigManager) SetDetectedInstance(instanceURL, instanceType, productVersion string, capabilities []string) error {
+	cfg, err := m.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg.InstanceType = instanceType
+	cfg.ProductVersion = productVersion
+	cfg.Capabilities = capabilities
+	cfg.DetectedURL = instanceURL
+	return m.saveConfig(cfg)
+}
+
+// ClearDetectedInstance forgets the instance type and probe results so the
+// next command detects them again.
+func (m *ConfigManager) ClearDetectedInstance() error {
+	cfg, err := m.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg.InstanceType = ""
+	cfg.ProductVersion = ""
+	cfg.Capabilities = nil
+	cfg.DetectedURL = ""
+	return m.saveConfig(cfg)
+}
+
 // SetAuthType updates the auth type in the config.
 func (m *ConfigManager) SetAuthType(authType string) error {
 	cfg, err := m.GetConfig()
@@ -188,3 +269,191 @@ func (m *ConfigManager) SetTLSSkipVerify(skip bool) error {
 	cfg.TLSSkipVerify = skip
 	return m.saveConfig(cfg)
 }
+
+// SetTLSCABundle sets the PEM CA bundle trusted in addition to the system roots.
+// An empty path removes it.
+func (m *ConfigManager) SetTLSCABundle(path string) error {
+	cfg, err := m.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg.TLSCABundle = path
+	return m.saveConfig(cfg)
+}
+
+// SetTLSClientCert sets the client certificate presented for mutual TLS.
+func (m *ConfigManager) SetTLSClientCert(path string) error {
+	cfg, err := m.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg.TLSClientCert = path
+	return m.saveConfig(cfg)
+}
+
+// SetTLSClientKey sets the private key for the mutual TLS client certificate.
+func (m *ConfigManager) SetTLSClientKey(path string) error {
+	cfg, err := m.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg.TLSClientKey = path
+	return m.saveConfig(cfg)
+}
+
+// SetProxyURL sets the default proxy URL; empty falls back to the environment.
+func (m *ConfigManager) SetProxyURL(proxyURL string) error {
+	cfg, err := m.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg.ProxyURL = proxyURL
+	return m.saveConfig(cfg)
+}
+
+// SetProxyUsername sets the user for the default proxy.
+func (m *ConfigManager) SetProxyUsername(username string) error {
+	cfg, err := m.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg.ProxyUsername = username
+	return m.saveConfig(cfg)
+}
+
+// SetNoProxy sets the hosts that bypass the default proxy.
+func (m *ConfigManager) SetNoProxy(noProxy string) error {
+	cfg, err := m.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg.NoProxy = noProxy
+	return m.saveConfig(cfg)
+}
+
+// SetRetryMaxAttempts updates the total number of attempts per request.
+func (m *ConfigManager) SetRetryMaxAttempts(n int) error {
+	cfg, err := m.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg.RetryMaxAttempts = n
+	return m.saveConfig(cfg)
+}
+
+// SetRetryBaseDelay updates the delay before the first retry.
+func (m *ConfigManager) SetRetryBaseDelay(d time.Duration) error {
+	cfg, err := m.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg.RetryBaseDelay = d.String()
+	return m.saveConfig(cfg)
+}
+
+// SetRetryMaxDelay updates the cap on any single retry wait.
+func (m *ConfigManager) SetRetryMaxDelay(d time.Duration) error {
+	cfg, err := m.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg.RetryMaxDelay = d.String()
+	return m.saveConfig(cfg)
+}
+
+// SetRetryJitter updates the jitter fraction applied to retry delays.
+func (m *ConfigManager) SetRetryJitter(jitter float64) error {
+	cfg, err := m.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg.RetryJitter = &jitter
+	return m.saveConfig(cfg)
+}
+
+// SetRetryHonorRetryAfter updates whether Retry-After headers override the computed backoff.
+func (m *ConfigManager) SetRetryHonorRetryAfter(honor bool) error {
+	cfg, err := m.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg.RetryHonorRetryAfter = &honor
+	return m.saveConfig(cfg)
+}
+
+// SetRateLimitRPS updates the overall requests-per-second budget.
+func (m *ConfigManager) SetRateLimitRPS(rps float64) error {
+	cfg, err := m.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg.RateLimitRPS = &rps
+	return m.saveConfig(cfg)
+}
+
+// SetRateLimitBurst updates the overall burst size.
+func (m *ConfigManager) SetRateLimitBurst(burst int) error {
+	cfg, err := m.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg.RateLimitBurst = burst
+	return m.saveConfig(cfg)
+}
+
+// SetRateLimitWriteRPS updates the writes-per-second budget.
+func (m *ConfigManager) SetRateLimitWriteRPS(rps float64) error {
+	cfg, err := m.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg.RateLimitWriteRPS = &rps
+	return m.saveConfig(cfg)
+}
+
+// SetRateLimitWriteBurst updates the write burst size.
+func (m *ConfigManager) SetRateLimitWriteBurst(burst int) error {
+	cfg, err := m.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg.RateLimitWriteBurst = burst
+	return m.saveConfig(cfg)
+}
+
+// SetSpaceCacheTTL updates how long persisted space key/ID mappings are trusted.
+func (m *ConfigManager) SetSpaceCacheTTL(ttl time.Duration) error {
+	cfg, err := m.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg.SpaceCacheTTL = ttl.String()
+	return m.saveConfig(cfg)
+}
+
+// SetResponseCache enables or disables the on-disk response cache.
+func (m *ConfigManager) SetResponseCache(enabled bool) error {
+	cfg, err := m.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg.ResponseCache = &enabled
+	return m.saveConfig(cfg)
+}
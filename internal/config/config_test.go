@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func tmpConfigPath(t *testing.T) string {
@@ -65,6 +66,27 @@ func TestConfigManager_SetInstanceType(t *testing.T) {
 	}
 }
 
+func TestConfigManager_DetectedInstance(t *testing.T) {
+	m := NewConfigManagerWithPath(tmpConfigPath(t))
+
+	caps := []string{"v1", "content_properties"}
+	if err := m.SetDetectedInstance("https://wiki.corp.example", "server", "8.5.4", caps); err != nil {
+		t.Fatalf("SetDetectedInstance error: %v", err)
+	}
+	cfg, _ := m.GetConfig()
+	if cfg.InstanceType != "server" || cfg.ProductVersion != "8.5.4" || cfg.DetectedURL != "https://wiki.corp.example" || len(cfg.Capabilities) != 2 {
+		t.Errorf("detected instance not stored: %+v", cfg)
+	}
+
+	if err := m.ClearDetectedInstance(); err != nil {
+		t.Fatalf("ClearDetectedInstance error: %v", err)
+	}
+	cfg, _ = m.GetConfig()
+	if cfg.InstanceType != "" || cfg.ProductVersion != "" || cfg.DetectedURL != "" || cfg.Capabilities != nil {
+		t.Errorf("detected instance not cleared: %+v", cfg)
+	}
+}
+
 func TestConfigManager_SetAuthType(t *testing.T) {
 	m := NewConfigManagerWithPath(tmpConfigPath(t))
 
@@ -78,6 +100,33 @@ func TestConfigManager_SetAuthType(t *testing.T) {
 	}
 }
 
+func TestConfigManager_RetrySettings(t *testing.T) {
+	m := NewConfigManagerWithPath(tmpConfigPath(t))
+
+	_ = m.SetRetryMaxAttempts(6)
+	_ = m.SetRetryBaseDelay(500 * time.Millisecond)
+	_ = m.SetRetryMaxDelay(time.Minute)
+	_ = m.SetRetryJitter(0)
+	_ = m.SetRetryHonorRetryAfter(false)
+
+	cfg, err := m.GetConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.RetryMaxAttempts != 6 {
+		t.Errorf("expected 6 attempts, got %d", cfg.RetryMaxAttempts)
+	}
+	if cfg.RetryBaseDelay != "500ms" || cfg.RetryMaxDelay != "1m0s" {
+		t.Errorf("unexpected delays: base %q, max %q", cfg.RetryBaseDelay, cfg.RetryMaxDelay)
+	}
+	if cfg.RetryJitter == nil || *cfg.RetryJitter != 0 {
+		t.Errorf("expected explicit zero jitter, got %v", cfg.RetryJitter)
+	}
+	if cfg.RetryHonorRetryAfter == nil || *cfg.RetryHonorRetryAfter {
+		t.Errorf("expected honor_retry_after=false, got %v", cfg.RetryHonorRetryAfter)
+	}
+}
+
 func TestConfigManager_Exists(t *testing.T) {
 	path := tmpConfigPath(t)
 	m := NewConfigManagerWithPath(path)
@@ -0,0 +1,219 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Blog posts share the page model: the same Page type and fields, minus a
+// parent, since posts sit directly in a space. Cloud serves them from the v2
+// /blogposts endpoints, Server/DC as v1 content of type "blogpost".
+
+// GetBlogPost retrieves a blog post by ID (v2 Cloud, v1 Server/DC).
+func (c *Client) GetBlogPost(postID string, includeBody bool) (*Page, error) {
+	return c.GetBlogPostContext(context.Background(), postID, includeBody)
+}
+
+// GetBlogPostContext is like GetBlogPost but honors ctx for cancellation and deadlines.
+func (c *Client) GetBlogPostContext(ctx context.Context, postID string, includeBody bool) (*Page, error) {
+	if c.Has(CapV2) {
+		q := url.Values{}
+		if includeBody {
+			q.Set("body-format", "storage")
+		}
+		data, err := c.getV2(ctx, "blogposts/"+postID, q)
+		if err != nil {
+			return nil, err
+		}
+		var post Page
+		if err := json.Unmarshal(data, &post); err != nil {
+			return nil, fmt.Errorf("parsing blog post: %w", err)
+		}
+		return &post, nil
+	}
+
+	expand := "version,space,metadata.labels"
+	if includeBody {
+		expand += ",body.storage"
+	}
+	data, err := c.getV1(ctx, "content/"+postID, url.Values{"expand": {expand}})
+	if err != nil {
+		return nil, err
+	}
+	var v1 V1Content
+	if err := json.Unmarshal(data, &v1); err != nil {
+		return nil, fmt.Errorf("parsing v1 blog post: %w", err)
+	}
+	// v1 serves every content type from the same endpoint.
+	if v1.Type != "blogpost" {
+		return nil, fmt.Errorf("%w: content %s is a %s, not a blog post", ErrNotFound, postID, v1.Type)
+	}
+	return v1ToPage(&v1), nil
+}
+
+// ListBlogPosts lists the blog posts in a space, optionally only those with
+// the given title (v2 Cloud, v1 Server/DC).
+func (c *Client) ListBlogPosts(spaceKey string, title string, limit int) ([]Page, error) {
+	return c.ListBlogPostsContext(context.Background(), spaceKey, title, limit)
+}
+
+// ListBlogPostsContext is like ListBlogPosts but honors ctx for cancellation and deadlines.
+func (c *Client) ListBlogPostsContext(ctx context.Context, spaceKey string, title string, limit int) ([]Page, error) {
+	if c.Has(CapV2) {
+		spaceID, err := c.ResolveSpaceKeyContext(ctx, spaceKey)
+		if err != nil {
+			return nil, err
+		}
+		q := url.Values{"space-id": {spaceID}}
+		if title != "" {
+			q.Set("title", title)
+		}
+		return PaginateV2[Page](ctx, c, "blogposts", q, limit).All()
+	}
+
+	q := url.Values{
+		"type":     {"blogpost"},
+		"spaceKey": {spaceKey},
+		"expand":   {"version,space"},
+	}
+	if title != "" {
+		q.Set("title", title)
+	}
+	results, err := PaginateV1[V1Content](ctx, c, "content", q, 0, limit).All()
+	if err != nil {
+		return nil, err
+	}
+	return v1ToPages(results), nil
+}
+
+// CreateBlogPost publishes a new blog post in a space (v2 Cloud, v1 Server/DC).
+func (c *Client) CreateBlogPost(spaceKey, title, body string) (*Page, error) {
+	return c.CreateBlogPostContext(context.Background(), spaceKey, title, body)
+}
+
+// CreateBlogPostContext is like CreateBlogPost but honors ctx for cancellation and deadlines.
+func (c *Client) CreateBlogPostContext(ctx context.Context, spaceKey, title, body string) (*Page, error) {
+	if c.Has(CapV2) {
+		spaceID, err := c.ResolveSpaceKeyContext(ctx, spaceKey)
+		if err != nil {
+			return nil, err
+		}
+		req := CreatePageRequest{SpaceID: spaceID, Status: "current", Title: title}
+		if body != "" {
+			req.Body = &CreatePageBody{Representation: "storage", Value: body}
+		}
+		data, err := c.postV2(ctx, "blogposts", req)
+		if err != nil {
+			return nil, err
+		}
+		var post Page
+		if err := json.Unmarshal(data, &post); err != nil {
+			return nil, fmt.Errorf("parsing created blog post: %w", err)
+		}
+		return &post, nil
+	}
+
+	v1Req := map[string]interface{}{
+		"type":  "blogpost",
+		"title": title,
+		"space": map[string]string{"key": spaceKey},
+	}
+	if body != "" {
+		v1Req["body"] = map[string]interface{}{
+			"storage": map[string]string{"value": body, "representation": "storage"},
+		}
+	}
+	data, err := c.postV1(ctx, "content", v1Req)
+	if err != nil {
+		return nil, err
+	}
+	var v1 V1Content
+	if err := json.Unmarshal(data, &v1); err != nil {
+		return nil, fmt.Errorf("parsing v1 created blog post: %w", err)
+	}
+	return v1ToPage(&v1), nil
+}
+
+// UpdateBlogPost publishes a new version of a blog post, keeping the title
+// when title is empty and the body when body is empty (v2 Cloud, v1
+// Server/DC). Automatically handles version increment.
+func (c *Client) UpdateBlogPost(postID, title, body, message string) (*Page, error) {
+	return c.UpdateBlogPostContext(context.Background(), postID, title, body, message)
+}
+
+// UpdateBlogPostContext is like UpdateBlogPost but honors ctx for cancellation and deadlines.
+func (c *Client) UpdateBlogPostContext(ctx context.Context, postID, title, body, message string) (*Page, error) {
+	current, err := c.GetBlogPostContext(ctx, postID, body == "")
+	if err != nil {
+		return nil, fmt.Errorf("reading current version: %w", err)
+	}
+	version := 1
+	if current.Version != nil {
+		version = current.Version.Number + 1
+	}
+	if title == "" {
+		title = current.Title
+	}
+	if body == "" {
+		body = storageValue(current)
+	}
+
+	if c.Has(CapV2) {
+		req := UpdatePageRequest{
+			ID:      postID,
+			Status:  "current",
+			Title:   title,
+			Body:    &CreatePageBody{Representation: "storage", Value: body},
+			Version: &VersionUpdate{Number: version, Message: message},
+		}
+		data, err := c.putV2(ctx, "blogposts/"+postID, req)
+		if err != nil {
+			return nil, err
+		}
+		var post Page
+		if err := json.Unmarshal(data, &post); err != nil {
+			return nil, fmt.Errorf("parsing updated blog post: %w", err)
+		}
+		return &post, nil
+	}
+
+	v1Req := map[string]interface{}{
+		"type":  "blogpost",
+		"title": title,
+		"body": map[string]interface{}{
+			"storage": map[string]string{"value": body, "representation": "storage"},
+		},
+		"version": map[string]interface{}{"number": version, "message": message},
+	}
+	data, err := c.request(ctx, http.MethodPut, c.v1URL("content", postID), nil, v1Req)
+	if err != nil {
+		return nil, err
+	}
+	var v1 V1Content
+	if err := json.Unmarshal(data, &v1); err != nil {
+		return nil, fmt.Errorf("parsing v1 updated blog post: %w", err)
+	}
+	return v1ToPage(&v1), nil
+}
+
+// DeleteBlogPost trashes a blog post (v2 Cloud, v1 Server/DC).
+func (c *Client) DeleteBlogPost(postID string) error {
+	return c.DeleteBlogPostContext(context.Background(), postID)
+}
+
+// DeleteBlogPostContext is like DeleteBlogPost but honors ctx for cancellation and deadlines.
+func (c *Client) DeleteBlogPostContext(ctx context.Context, postID string) error {
+	if c.Has(CapV2) {
+		_, err := c.deleteV2(ctx, "blogposts/"+postID)
+		return err
+	}
+	// Check the type first: v1 would trash a page with the same call.
+	if _, err := c.GetBlogPostContext(ctx, postID, false); err != nil {
+		return err
+	}
+	_, err := c.request(ctx, http.MethodDelete, c.v1URL("content", postID), nil, nil)
+	return err
+}
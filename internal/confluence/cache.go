@@ -0,0 +1,290 @@
+package confluence
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResponseCache stores GET response bodies on disk so repeated reads of the
+// same page skip the download. Entries are never trusted blindly: page bodies
+// are reused only while the page version is unchanged, and everything else
+// only after the server answers 304 to If-None-Match.
+//
+// Entries are keyed by URL and a hash of the credentials, so two accounts
+// never see each other's responses. It is safe for concurrent use.
+type ResponseCache struct {
+	dir string
+
+	mu    sync.Mutex
+	stats CacheStats
+}
+
+// CacheStats describes the cache contents and the lookups made through it.
+// Hits, Misses and Revalidated are cumulative across processes.
+type CacheStats struct {
+	Entries     int   `json:"entries"`
+	Bytes       int64 `json:"bytes"`
+	Hits        int64 `json:"hits"`        // served from cache without downloading the body
+	Misses      int64 `json:"misses"`      // fetched from the server and stored
+	Revalidated int64 `json:"revalidated"` // hits confirmed by a 304 Not Modified
+}
+
+type cacheEntry struct {
+	URL      string    `json:"url"`
+	ETag     string    `json:"etag,omitempty"`
+	Version  int       `json:"version,omitempty"` // page version the body belongs to, if known
+	StoredAt time.Time `json:"storedAt"`
+	Body     []byte    `json:"body"`
+}
+
+const cacheStatsFile = "stats.json"
+
+// NewResponseCache returns a cache rooted at dir. The directory is created on first write.
+func NewResponseCache(dir string) *ResponseCache {
+	return &ResponseCache{dir: dir}
+}
+
+// Dir returns the directory holding the cache files.
+func (rc *ResponseCache) Dir() string {
+	return rc.dir
+}
+
+func (rc *ResponseCache) entryPath(key string) string {
+	return filepath.Join(rc.dir, key[:2], key+".json")
+}
+
+func (rc *ResponseCache) get(key string) (*cacheEntry, bool) {
+	data, err := os.ReadFile(rc.entryPath(key))
+	if err != nil {
+		return nil, false
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, false
+	}
+	return &e, true
+}
+
+// put writes an entry. Failures are ignored: the cache only ever saves work.
+func (rc *ResponseCache) put(key string, e *cacheEntry) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	path := rc.entryPath(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+	_ = writeFileAtomic(path, data, 0o600)
+}
+
+func (rc *ResponseCache) remove(key string) {
+	_ = os.Remove(rc.entryPath(key))
+}
+
+func (rc *ResponseCache) recordHit(revalidated bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.stats.Hits++
+	if revalidated {
+		rc.stats.Revalidated++
+	}
+}
+
+func (rc *ResponseCache) recordMiss() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.stats.Misses++
+}
+
+// FlushStats adds this process's hit/miss counters to the totals on disk.
+func (rc *ResponseCache) FlushStats() error {
+	rc.mu.Lock()
+	pending := rc.stats
+	rc.stats = CacheStats{}
+	rc.mu.Unlock()
+
+	if pending.Hits == 0 && pending.Misses == 0 {
+		return nil
+	}
+
+	total := rc.readStats()
+	total.Hits += pending.Hits
+	total.Misses += pending.Misses
+	total.Revalidated += pending.Revalidated
+
+	data, err := json.Marshal(total)
+	if err != nil {
+		return fmt.Errorf("marshaling cache stats: %w", err)
+	}
+	if err := os.MkdirAll(rc.dir, 0o700); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	return writeFileAtomic(filepath.Join(rc.dir, cacheStatsFile), data, 0o600)
+}
+
+func (rc *ResponseCache) readStats() CacheStats {
+	var s CacheStats
+	if data, err := os.ReadFile(filepath.Join(rc.dir, cacheStatsFile)); err == nil {
+		_ = json.Unmarshal(data, &s)
+	}
+	return s
+}
+
+// Stats returns the number and size of stored entries plus cumulative counters,
+// including this process's unflushed ones.
+func (rc *ResponseCache) Stats() (CacheStats, error) {
+	s := rc.readStats()
+	s.Entries, s.Bytes = 0, 0
+
+	rc.mu.Lock()
+	s.Hits += rc.stats.Hits
+	s.Misses += rc.stats.Misses
+	s.Revalidated += rc.stats.Revalidated
+	rc.mu.Unlock()
+
+	err := filepath.WalkDir(rc.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() == cacheStatsFile || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		s.Entries++
+		s.Bytes += info.Size()
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return s, fmt.Errorf("reading cache directory: %w", err)
+	}
+	return s, nil
+}
+
+// Clear deletes every entry and resets the counters. It returns the number of entries removed.
+func (rc *ResponseCache) Clear() (int, error) {
+	s, err := rc.Stats()
+	if err != nil {
+		return 0, err
+	}
+	if err := os.RemoveAll(rc.dir); err != nil {
+		return 0, fmt.Errorf("clearing cache: %w", err)
+	}
+	rc.mu.Lock()
+	rc.stats = CacheStats{}
+	rc.mu.Unlock()
+	return s.Entries, nil
+}
+
+// cacheKey identifies a response by credentials and full URL without storing the credentials.
+func (c *Client) cacheKey(fullURL string) string {
+	sum := sha256.Sum256([]byte(c.authHeader + "\x00" + fullURL))
+	return hex.EncodeToString(sum[:])
+}
+
+// getVersioned fetches a page representation through the response cache.
+// A cached body is reused when currentVersion, a cheap metadata call, reports
+// the same version it was stored with; versionOf extracts that version from a fresh body.
+func (c *Client) getVersioned(ctx context.Context, fullURL string, query url.Values,
+	currentVersion func(context.Context) (int, error), versionOf func([]byte) int) ([]byte, error) {
+	if c.cache == nil {
+		return c.request(ctx, http.MethodGet, fullURL, query, nil)
+	}
+	if query != nil {
+		fullURL += "?" + query.Encode()
+	}
+	key := c.cacheKey(fullURL)
+
+	if e, ok := c.cache.get(key); ok && e.Version > 0 {
+		if v, err := currentVersion(ctx); err == nil && v == e.Version {
+			c.cache.recordHit(false)
+			return e.Body, nil
+		}
+	}
+
+	data, err := c.doRequest(ctx, http.MethodGet, fullURL, nil, nil, false)
+	if err != nil {
+		c.cache.remove(key)
+		return nil, err
+	}
+	c.cache.recordMiss()
+	if v := versionOf(data); v > 0 {
+		c.cache.put(key, &cacheEntry{URL: fullURL, Version: v, StoredAt: time.Now(), Body: data})
+	}
+	return data, nil
+}
+
+// evictPage drops the cached bodies of a page after a write to it. Version
+// checks cover edits, but a move or label change leaves the version as it
+// was, and a deleted page shouldn't linger on disk.
+func (c *Client) evictPage(pageID string) {
+	if c.cache == nil {
+		return
+	}
+	for _, fullURL := range []string{
+		c.v2URL("pages", pageID) + "?" + pageBodyQueryV2().Encode(),
+		c.v1URL("content", pageID) + "?" + pageBodyQueryV1().Encode(),
+	} {
+		c.cache.remove(c.cacheKey(fullURL))
+	}
+}
+
+// cachedETag returns the stored entry for an ETag-revalidated GET, if any.
+func (c *Client) cachedETag(key string) *cacheEntry {
+	if c.cache == nil {
+		return nil
+	}
+	if e, ok := c.cache.get(key); ok && e.ETag != "" {
+		return e
+	}
+	return nil
+}
+
+// storeETag remembers a GET response that carries an ETag.
+func (c *Client) storeETag(key, fullURL string, resp *http.Response, body []byte) {
+	if c.cache == nil {
+		return
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		return
+	}
+	c.cache.recordMiss()
+	c.cache.put(key, &cacheEntry{URL: fullURL, ETag: etag, StoredAt: time.Now(), Body: body})
+}
+
+// writeFileAtomic writes data to a temp file in the same directory and renames it into place.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,291 @@
+package confluence
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Cassette is an http.RoundTripper that records real API interactions to a
+// JSON file and replays them offline. Plug it in with
+//
+//	client.SetHTTPClient(cassette.HTTPClient())
+//
+// Requests are matched on method, path and query; the host is ignored, so a
+// cassette recorded against one site replays for any base URL with the same
+// path prefix. Secrets are redacted before anything is written, using the
+// same rules as --trace.
+type Cassette struct {
+	mu           sync.Mutex
+	path         string
+	real         http.RoundTripper // nil in replay mode
+	interactions []CassetteInteraction
+	used         []bool
+	misses       []string
+}
+
+// CassetteInteraction is one recorded request/response pair.
+type CassetteInteraction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+// CassetteRequest identifies a recorded request. Query is canonical: keys
+// sorted, secret-looking parameters redacted.
+type CassetteRequest struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Query  string          `json:"query,omitempty"`
+	JSON   json.RawMessage `json:"json,omitempty"` // request body, for reference; not matched
+}
+
+// CassetteResponse is a recorded response. JSON bodies are stored inline so
+// cassettes stay readable and diffable; anything else is kept as text.
+type CassetteResponse struct {
+	Status int             `json:"status"`
+	Header http.Header     `json:"header,omitempty"`
+	JSON   json.RawMessage `json:"json,omitempty"`
+	Body   string          `json:"body,omitempty"`
+}
+
+type cassetteFile struct {
+	Interactions []CassetteInteraction `json:"interactions"`
+}
+
+// cassetteHeaders are the response headers worth keeping; the rest is
+// per-request noise that would churn on every re-record.
+var cassetteHeaders = []string{"Content-Type", "ETag", "Link", "Retry-After", "X-Ratelimit-Remaining"}
+
+// NewCassetteRecorder returns a cassette that forwards requests to real
+// (http.DefaultTransport when nil) and records them; call Save to write path.
+func NewCassetteRecorder(path string, real http.RoundTripper) *Cassette {
+	if real == nil {
+		real = http.DefaultTransport
+	}
+	return &Cassette{path: path, real: real}
+}
+
+// LoadCassette opens a recorded cassette for offline replay.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading cassette: %w", err)
+	}
+	var f cassetteFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing cassette %s: %w", path, err)
+	}
+	return &Cassette{path: path, interactions: f.Interactions, used: make([]bool, len(f.Interactions))}, nil
+}
+
+// HTTPClient returns an HTTP client that sends every request through the cassette.
+func (c *Cassette) HTTPClient() *http.Client {
+	return &http.Client{Transport: c}
+}
+
+// Recording reports whether the cassette forwards to a real server.
+func (c *Cassette) Recording() bool {
+	return c.real != nil
+}
+
+// Misses returns the requests replay had no recording for, as "METHOD path?query".
+func (c *Cassette) Misses() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.misses...)
+}
+
+// RoundTrip records or replays req.
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+	key := CassetteRequest{Method: req.Method, Path: req.URL.Path, Query: canonicalQuery(req.URL)}
+
+	if c.real != nil {
+		return c.record(req, key, reqBody)
+	}
+	return c.replay(req, key)
+}
+
+func (c *Cassette) record(req *http.Request, key CassetteRequest, reqBody []byte) (*http.Response, error) {
+	resp, err := c.real.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	if json.Valid(reqBody) {
+		key.JSON = unescapeHTML(redactBody(reqBody))
+	}
+	recorded := CassetteResponse{Status: resp.StatusCode, Header: http.Header{}}
+	for _, name := range cassetteHeaders {
+		if v := resp.Header.Values(name); len(v) > 0 {
+			recorded.Header[name] = v
+		}
+	}
+	recorded.Header = redactHeader(recorded.Header)
+	if json.Valid(respBody) {
+		recorded.JSON = unescapeHTML(redactBody(respBody))
+	} else {
+		recorded.Body = string(respBody)
+	}
+
+	c.mu.Lock()
+	c.interactions = append(c.interactions, CassetteInteraction{Request: key, Response: recorded})
+	c.used = append(c.used, true)
+	c.mu.Unlock()
+	return resp, nil
+}
+
+// replay answers with the first unused matching interaction, so sequences
+// like read-update-read play back in order. Once all matches are used the
+// last one is repeated, which keeps incidental extra reads working.
+func (c *Cassette) replay(req *http.Request, key CassetteRequest) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	match := -1
+	for i, it := range c.interactions {
+		if it.Request.Method != key.Method || it.Request.Path != key.Path || it.Request.Query != key.Query {
+			continue
+		}
+		match = i
+		if !c.used[i] {
+			break
+		}
+	}
+	if match < 0 {
+		miss := key.Method + " " + key.Path
+		if key.Query != "" {
+			miss += "?" + key.Query
+		}
+		c.misses = append(c.misses, miss)
+		return nil, fmt.Errorf("cassette %s: no recorded interaction for %s", filepath.Base(c.path), miss)
+	}
+	c.used[match] = true
+
+	recorded := c.interactions[match].Response
+	body := []byte(recorded.Body)
+	if len(recorded.JSON) > 0 {
+		body = recorded.JSON
+	}
+	header := recorded.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		StatusCode:    recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Save writes the recorded interactions to the cassette file.
+func (c *Cassette) Save() error {
+	// Storage-format bodies are full of markup; keep it literal rather than \u003c-escaped.
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	c.mu.Lock()
+	err := enc.Encode(cassetteFile{Interactions: c.interactions})
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("marshaling cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("creating cassette directory: %w", err)
+	}
+	return writeFileAtomic(c.path, buf.Bytes(), 0o644)
+}
+
+// unescapeHTML turns the \u003c, \u003e and \u0026 escapes that Go's JSON
+// encoder adds back into literal characters, leaving other escapes alone.
+func unescapeHTML(data []byte) []byte {
+	if !bytes.Contains(data, []byte(`\u00`)) {
+		return data
+	}
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] != '\\' || i+1 >= len(data) {
+			out = append(out, data[i])
+			continue
+		}
+		if data[i+1] == 'u' && i+6 <= len(data) {
+			switch string(data[i+2 : i+6]) {
+			case "003c":
+				out = append(out, '<')
+				i += 5
+				continue
+			case "003e":
+				out = append(out, '>')
+				i += 5
+				continue
+			case "0026":
+				out = append(out, '&')
+				i += 5
+				continue
+			}
+		}
+		// Copy the escape pair as is, so an escaped backslash can't start a match.
+		out = append(out, data[i], data[i+1])
+		i++
+	}
+	return out
+}
+
+// canonicalQuery encodes u's query with sorted keys and secrets redacted,
+// so recording and replay compare equal regardless of parameter order.
+func canonicalQuery(u *url.URL) string {
+	q := u.Query()
+	if len(q) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		values := q[k]
+		if isSecretName(k) {
+			values = []string{redacted}
+		}
+		for _, v := range values {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(url.QueryEscape(k) + "=" + url.QueryEscape(v))
+		}
+	}
+	return b.String()
+}
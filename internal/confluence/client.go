@@ -2,7 +2,7 @@ package confluence
 
 import (
 	"bytes"
-	"crypto/tls"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -19,19 +19,26 @@ const (
 	// Cloud API paths
 	v2Path = "/api/v2"  // Relative to base URL (which includes /wiki for Cloud)
 	v1Path = "/rest/api" // Relative to base URL
-
-	maxRetries = 3
 )
 
 // Client is the Confluence REST API client (supports Cloud and Server/DC).
 type Client struct {
 	baseURL      string // e.g. "https://company.atlassian.net/wiki" or "https://confluence.company.com"
-	authHeader   string
+	authHeader   string        // static header for basic/bearer; a stable identity for OAuth
+	oauth        *oauthSession // nil unless AuthType is AuthOAuth
+	apiRoot      string        // gateway prefix that site-relative links resolve against; empty off the gateway
 	httpClient   *http.Client
 	instanceType InstanceType
-
-	// spaceKeyCache maps space key -> space ID for v2 operations.
-	spaceKeyCache map[string]string
+	capabilities []Capability // nil until detected; see Has
+	trustPath    string // how TLS certificates are verified, for diagnostics
+	retry        RetryPolicy
+	onRetry      func(RetryEvent)
+	onTrace      func(TraceEntry)
+	limiter      *rateLimiter // shared per instance; nil when rate limiting is disabled
+
+	// spaces maps space keys to IDs and back; v2 addresses spaces by numeric ID.
+	spaces *SpaceCache
+	cache  *ResponseCache // nil disables response caching
 }
 
 // NewClient creates a new Confluence API client.
@@ -39,7 +46,7 @@ func NewClient(cfg Config) (*Client, error) {
 	if cfg.BaseURL == "" {
 		return nil, fmt.Errorf("confluence: base URL is required")
 	}
-	if cfg.Token == "" {
+	if cfg.Token == "" && cfg.AuthType != AuthOAuth {
 		return nil, fmt.Errorf("confluence: token is required")
 	}
 
@@ -54,8 +61,24 @@ func NewClient(cfg Config) (*Client, error) {
 		}
 	}
 
-	var authHeader string
+	var authHeader, apiRoot string
+	var oauth *oauthSession
 	switch authType {
+	case AuthOAuth:
+		// OAuth tokens are only accepted by the API gateway, not the site URL.
+		var err error
+		oauth, apiRoot, err = newOAuthSession(cfg)
+		if err != nil {
+			return nil, err
+		}
+		baseURL = apiRoot + "/wiki"
+		cfg.InstanceType = InstanceCloud
+		// The cache key needs the account, not just the app and site, or
+		// two users of one app would read each other's cached responses.
+		if cfg.OAuth.AccountID == "" {
+			cfg.ResponseCache = nil
+		}
+		authHeader = "OAuth " + cfg.OAuth.CloudID + " " + cfg.OAuth.AccountID // cache identity only; never sent
 	case AuthBearer:
 		authHeader = "Bearer " + cfg.Token
 	default: // AuthBasic
@@ -66,19 +89,42 @@ func NewClient(cfg Config) (*Client, error) {
 		authHeader = "Basic " + base64.StdEncoding.EncodeToString([]byte(creds))
 	}
 
-	httpClient := &http.Client{Timeout: 30 * time.Second}
-	if cfg.InsecureSkipVerify {
-		httpClient.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
+	transport, trustPath, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := &http.Client{Timeout: 30 * time.Second, Transport: transport}
+
+	retry := DefaultRetryPolicy()
+	if cfg.RetryPolicy != nil {
+		retry = cfg.RetryPolicy.normalized()
+	}
+
+	rateLimit := DefaultRateLimit()
+	if cfg.RateLimit != nil {
+		rateLimit = *cfg.RateLimit
+	}
+
+	spaces := cfg.SpaceCache
+	if spaces == nil {
+		spaces = NewSpaceCache("", 0)
 	}
 
 	return &Client{
 		baseURL:       baseURL,
 		authHeader:    authHeader,
+		oauth:         oauth,
+		apiRoot:       apiRoot,
 		httpClient:    httpClient,
 		instanceType:  cfg.InstanceType,
-		spaceKeyCache: make(map[string]string),
+		capabilities:  cfg.Capabilities,
+		trustPath:     trustPath,
+		retry:         retry,
+		onRetry:       cfg.OnRetry,
+		onTrace:       cfg.OnTrace,
+		limiter:       limiterFor(baseURL, rateLimit),
+		spaces:        spaces,
+		cache:         cfg.ResponseCache,
 	}, nil
 }
 
@@ -92,6 +138,12 @@ func (c *Client) BaseURL() string {
 	return c.baseURL
 }
 
+// TrustPath describes how the client verifies the server's TLS certificate
+// (system roots, an extra CA bundle, or disabled) and any client certificate it presents.
+func (c *Client) TrustPath() string {
+	return c.trustPath
+}
+
 // SetHTTPClient overrides the default HTTP client (useful for testing).
 func (c *Client) SetHTTPClient(hc *http.Client) {
 	c.httpClient = hc
@@ -112,77 +164,156 @@ func (c *Client) v1URL(segments ...string) string {
 // --- Space key resolver ---
 
 // ResolveSpaceKey translates a space key to a space ID (v2 uses numeric IDs).
-// Results are cached in memory.
+// Results are cached, on disk too when the client was given a persistent SpaceCache.
 func (c *Client) ResolveSpaceKey(key string) (string, error) {
-	if id, ok := c.spaceKeyCache[key]; ok {
+	return c.ResolveSpaceKeyContext(context.Background(), key)
+}
+
+// ResolveSpaceKeyContext is like ResolveSpaceKey but honors ctx for cancellation and deadlines.
+func (c *Client) ResolveSpaceKeyContext(ctx context.Context, key string) (string, error) {
+	if id, ok := c.spaces.lookupID(c.baseURL, key); ok {
 		return id, nil
 	}
 
-	q := url.Values{"keys": {key}}
-	data, err := c.getV2("spaces", q)
+	var data []byte
+	var err error
+	if c.Has(CapV2) {
+		data, err = c.getV2(ctx, "spaces", url.Values{"keys": {key}})
+	} else {
+		data, err = c.getV1(ctx, "space", url.Values{"spaceKey": {key}})
+	}
 	if err != nil {
 		return "", fmt.Errorf("resolving space key %q: %w", key, err)
 	}
 
-	var page CursorPage[Space]
-	if err := json.Unmarshal(data, &page); err != nil {
-		return "", fmt.Errorf("parsing space response: %w", err)
+	spaces, err := c.parseSpaceLookup(data)
+	if err != nil {
+		return "", err
+	}
+	for _, s := range spaces {
+		if s.Key == key {
+			c.spaces.store(c.baseURL, s)
+			return s.ID, nil
+		}
+	}
+	return "", fmt.Errorf("space %q not found", key)
+}
+
+// SpaceKeyForID translates a space ID back to its key, using the same cache as ResolveSpaceKey.
+func (c *Client) SpaceKeyForID(id string) (string, error) {
+	return c.SpaceKeyForIDContext(context.Background(), id)
+}
+
+// SpaceKeyForIDContext is like SpaceKeyForID but honors ctx for cancellation and deadlines.
+func (c *Client) SpaceKeyForIDContext(ctx context.Context, id string) (string, error) {
+	if key, ok := c.spaces.lookupKey(c.baseURL, id); ok {
+		return key, nil
+	}
+
+	var data []byte
+	var err error
+	if c.Has(CapV2) {
+		data, err = c.getV2(ctx, "spaces", url.Values{"ids": {id}})
+	} else {
+		data, err = c.getV1(ctx, "space", url.Values{"spaceId": {id}})
+	}
+	if err != nil {
+		return "", fmt.Errorf("resolving space ID %s: %w", id, err)
+	}
+
+	spaces, err := c.parseSpaceLookup(data)
+	if err != nil {
+		return "", err
 	}
+	for _, s := range spaces {
+		if s.ID == id {
+			c.spaces.store(c.baseURL, s)
+			return s.Key, nil
+		}
+	}
+	return "", fmt.Errorf("space ID %s not found", id)
+}
 
-	if len(page.Results) == 0 {
-		return "", fmt.Errorf("space %q not found", key)
+// parseSpaceLookup decodes a single-page space list from either API version.
+func (c *Client) parseSpaceLookup(data []byte) ([]Space, error) {
+	if c.Has(CapV2) {
+		var page CursorPage[Space]
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("parsing space response: %w", err)
+		}
+		return page.Results, nil
 	}
 
-	id := page.Results[0].ID
-	c.spaceKeyCache[key] = id
-	return id, nil
+	var page OffsetPage[V1Space]
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, fmt.Errorf("parsing space response: %w", err)
+	}
+	return v1ToSpaces(page.Results), nil
 }
 
 // --- Convenience methods (versioned) ---
 
 // getV2 performs a GET request to the v2 API.
-func (c *Client) getV2(path string, query url.Values) ([]byte, error) {
+func (c *Client) getV2(ctx context.Context, path string, query url.Values) ([]byte, error) {
 	fullURL := c.v2URL(path)
-	return c.request(http.MethodGet, fullURL, query, nil)
+	return c.request(ctx, http.MethodGet, fullURL, query, nil)
 }
 
 // getV1 performs a GET request to the v1 API.
-func (c *Client) getV1(path string, query url.Values) ([]byte, error) {
+func (c *Client) getV1(ctx context.Context, path string, query url.Values) ([]byte, error) {
 	fullURL := c.v1URL(path)
-	return c.request(http.MethodGet, fullURL, query, nil)
+	return c.request(ctx, http.MethodGet, fullURL, query, nil)
 }
 
 // postV2 performs a POST request to the v2 API.
-func (c *Client) postV2(path string, body interface{}) ([]byte, error) {
+func (c *Client) postV2(ctx context.Context, path string, body interface{}) ([]byte, error) {
 	fullURL := c.v2URL(path)
-	return c.request(http.MethodPost, fullURL, nil, body)
+	return c.request(ctx, http.MethodPost, fullURL, nil, body)
 }
 
 // putV2 performs a PUT request to the v2 API.
-func (c *Client) putV2(path string, body interface{}) ([]byte, error) {
+func (c *Client) putV2(ctx context.Context, path string, body interface{}) ([]byte, error) {
 	fullURL := c.v2URL(path)
-	return c.request(http.MethodPut, fullURL, nil, body)
+	return c.request(ctx, http.MethodPut, fullURL, nil, body)
 }
 
 // deleteV2 performs a DELETE request to the v2 API.
-func (c *Client) deleteV2(path string) ([]byte, error) {
+func (c *Client) deleteV2(ctx context.Context, path string) ([]byte, error) {
 	fullURL := c.v2URL(path)
-	return c.request(http.MethodDelete, fullURL, nil, nil)
+	return c.request(ctx, http.MethodDelete, fullURL, nil, nil)
 }
 
 // Get performs a raw GET request (for custom paths).
 func (c *Client) Get(fullPath string, query url.Values) ([]byte, error) {
+	return c.GetContext(context.Background(), fullPath, query)
+}
+
+// GetContext is like Get but honors ctx for cancellation and deadlines.
+func (c *Client) GetContext(ctx context.Context, fullPath string, query url.Values) ([]byte, error) {
 	fullURL := c.baseURL + fullPath
-	return c.request(http.MethodGet, fullURL, query, nil)
+	return c.request(ctx, http.MethodGet, fullURL, query, nil)
 }
 
 // --- Internal HTTP helpers ---
 
-func (c *Client) request(method, fullURL string, query url.Values, body interface{}) ([]byte, error) {
+// request performs an API call with retries. GETs are revalidated against
+// the response cache with If-None-Match when the client has one.
+func (c *Client) request(ctx context.Context, method, fullURL string, query url.Values, body interface{}) ([]byte, error) {
+	return c.doRequest(ctx, method, fullURL, query, body, c.cache != nil && method == http.MethodGet)
+}
+
+func (c *Client) doRequest(ctx context.Context, method, fullURL string, query url.Values, body interface{}, useETag bool) ([]byte, error) {
 	if query != nil {
 		fullURL += "?" + query.Encode()
 	}
 
+	var cacheKey string
+	var cached *cacheEntry
+	if useETag {
+		cacheKey = c.cacheKey(fullURL)
+		cached = c.cachedETag(cacheKey)
+	}
+
 	var bodyReader io.Reader
 	var bodyBytes []byte
 	if body != nil {
@@ -195,95 +326,145 @@ func (c *Client) request(method, fullURL string, query url.Values, body interfac
 	}
 
 	var lastErr error
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		req, err := http.NewRequest(method, fullURL, bodyReader)
+	reauthorized := false
+	for attempt := 0; attempt < c.retry.MaxAttempts; attempt++ {
+		if bodyBytes != nil {
+			bodyReader = bytes.NewReader(bodyBytes)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, fullURL, bodyReader)
 		if err != nil {
 			return nil, fmt.Errorf("confluence: failed to create request: %w", err)
 		}
 
-		req.Header.Set("Authorization", c.authHeader)
+		auth, err := c.authorization(ctx, false)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", auth)
 		req.Header.Set("Accept", "application/json")
 		if body != nil {
 			req.Header.Set("Content-Type", "application/json")
 		}
+		if cached != nil {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+
+		if err := c.limiter.wait(ctx, method); err != nil {
+			return nil, err
+		}
 
+		started := time.Now()
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
+			c.traceAttempt(req, bodyBytes, nil, nil, attempt, started, err)
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, fmt.Errorf("confluence: %s %s: %w", method, req.URL.Path, ctxErr)
+			}
 			lastErr = fmt.Errorf("confluence: request failed: %w", err)
 			if isNetworkError(err) {
 				return nil, fmt.Errorf("%w\n\nHint: could not reach %s — check your network connection or corporate VPN", lastErr, c.baseURL)
 			}
-			if attempt < maxRetries {
-				time.Sleep(backoff(attempt))
-				if bodyBytes != nil {
-					bodyReader = bytes.NewReader(bodyBytes)
-				}
-				continue
+			if err := c.waitRetry(ctx, req, nil, attempt, lastErr); err != nil {
+				return nil, err
 			}
-			return nil, lastErr
+			continue
 		}
 
 		respBody, err := io.ReadAll(resp.Body)
 		resp.Body.Close()
+		c.traceAttempt(req, bodyBytes, resp, respBody, attempt, started, err)
 		if err != nil {
 			return nil, fmt.Errorf("confluence: failed to read response body: %w", err)
 		}
 
 		// Success.
 		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			if useETag {
+				c.storeETag(cacheKey, fullURL, resp, respBody)
+			}
 			return respBody, nil
 		}
+		if resp.StatusCode == http.StatusNotModified && cached != nil {
+			c.cache.recordHit(true)
+			return cached.Body, nil
+		}
+
+		apiErr := parseAPIError(resp.StatusCode, respBody)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+			if d, ok := retryAfter(resp.Header, time.Now()); ok {
+				apiErr.RetryAfter = d
+			}
+		}
 
-		// Rate limited — retry after backoff.
-		if resp.StatusCode == http.StatusTooManyRequests {
-			lastErr = parseAPIError(resp.StatusCode, respBody)
-			if attempt < maxRetries {
-				time.Sleep(backoff(attempt))
-				if bodyBytes != nil {
-					bodyReader = bytes.NewReader(bodyBytes)
-				}
-				continue
+		// Rate limited or server error — retry with backoff (or Retry-After).
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			lastErr = apiErr
+			if err := c.waitRetry(ctx, req, resp, attempt, lastErr); err != nil {
+				return nil, err
 			}
-			return nil, lastErr
+			continue
 		}
 
-		// Server errors — retry with backoff.
-		if resp.StatusCode >= 500 {
-			lastErr = parseAPIError(resp.StatusCode, respBody)
-			if attempt < maxRetries {
-				time.Sleep(backoff(attempt))
-				if bodyBytes != nil {
-					bodyReader = bytes.NewReader(bodyBytes)
-				}
-				continue
+		// A revoked or early-expired OAuth token: refresh once and retry this attempt.
+		if resp.StatusCode == http.StatusUnauthorized && c.oauth != nil && !reauthorized {
+			reauthorized = true
+			if _, err := c.authorization(ctx, true); err != nil {
+				return nil, err
 			}
-			return nil, lastErr
+			attempt--
+			continue
 		}
 
 		// Client errors (4xx) — don't retry.
-		return nil, parseAPIError(resp.StatusCode, respBody)
+		return nil, apiErr
 	}
 
 	return nil, lastErr
 }
 
-func backoff(attempt int) time.Duration {
-	d := time.Duration(1<<uint(attempt)) * time.Second
-	if d > 60*time.Second {
-		d = 60 * time.Second
+// waitRetry sleeps before the next attempt, reporting it through onRetry.
+// It returns lastErr when the policy has no attempts left, or the context error if cancelled.
+func (c *Client) waitRetry(ctx context.Context, req *http.Request, resp *http.Response, attempt int, lastErr error) error {
+	if attempt+1 >= c.retry.MaxAttempts {
+		return lastErr
 	}
-	return d
-}
 
-func parseAPIError(statusCode int, body []byte) *APIError {
-	apiErr := &APIError{StatusCode: statusCode}
-	if err := json.Unmarshal(body, apiErr); err != nil {
-		apiErr.Message = fmt.Sprintf("HTTP %d: %s", statusCode, string(body))
+	var hintResp *http.Response
+	if resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+		hintResp = resp
+	}
+	delay, fromServer := c.retry.delay(attempt, hintResp)
+
+	if c.onRetry != nil {
+		ev := RetryEvent{
+			Method:      req.Method,
+			Path:        req.URL.Path,
+			Attempt:     attempt + 1,
+			MaxAttempts: c.retry.MaxAttempts,
+			Err:         lastErr,
+			Delay:       delay,
+			FromServer:  fromServer,
+		}
+		if resp != nil {
+			ev.StatusCode = resp.StatusCode
+			ev.RateLimit = rateLimitFromHeader(resp.Header)
+		}
+		c.onRetry(ev)
 	}
-	if apiErr.Message == "" && apiErr.ErrorMessage == "" {
-		apiErr.Message = fmt.Sprintf("HTTP %d", statusCode)
+
+	return sleepContext(ctx, delay)
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("confluence: retry backoff interrupted: %w", ctx.Err())
+	case <-t.C:
+		return nil
 	}
-	return apiErr
 }
 
 func isNetworkError(err error) bool {
@@ -0,0 +1,290 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// defaultCopyPrefix names copies made in the source page's own space, where
+// titles must differ, when the caller didn't choose a prefix.
+const defaultCopyPrefix = "Copy of "
+
+var (
+	riPagePattern    = regexp.MustCompile(`<ri:page\s[^>]*>`)
+	contentIDPattern = regexp.MustCompile(`(ri:content-id=")(\d+)(")`)
+	pageURLPattern   = regexp.MustCompile(`(pageId=|/pages/)(\d+)`)
+	titleAttrPattern = regexp.MustCompile(`ri:content-title="([^"]*)"`)
+	spaceAttrPattern = regexp.MustCompile(`ri:space-key="([^"]*)"`)
+)
+
+// CopyOptions controls CopyPage.
+type CopyOptions struct {
+	Recursive   bool   // copy the page's whole subtree
+	TitlePrefix string // prepended to every copied title; "Copy of " within the same space
+	Attachments bool   // also copy attachments (only where the native copy endpoint exists)
+}
+
+// CopyResult maps copied pages to their copies.
+type CopyResult struct {
+	RootID   string            `json:"rootId"` // the copy of the page CopyPage was called with
+	IDs      map[string]string `json:"ids"`    // source page ID -> copy's page ID
+	Warnings []string          `json:"warnings,omitempty"`
+}
+
+// CopyPage copies a page, or with opts.Recursive its whole subtree, under
+// parentID, which may be in another space. Bodies and labels are kept and
+// links between copied pages are rewritten to point at the copies.
+//
+// Pages are copied one at a time with the v1 copy endpoint where it exists,
+// walking the tree with GetChildren so every copy's ID is known; elsewhere
+// each page is recreated from its body and labels. On error the result
+// lists the copies made so far.
+func (c *Client) CopyPage(pageID, parentID string, opts CopyOptions) (*CopyResult, error) {
+	return c.CopyPageContext(context.Background(), pageID, parentID, opts)
+}
+
+// CopyPageContext is like CopyPage but honors ctx for cancellation and deadlines.
+func (c *Client) CopyPageContext(ctx context.Context, pageID, parentID string, opts CopyOptions) (*CopyResult, error) {
+	src, err := c.GetPageContext(ctx, pageID, false)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := c.GetPageContext(ctx, parentID, false)
+	if err != nil {
+		return nil, fmt.Errorf("reading destination page %s: %w", parentID, err)
+	}
+	srcSpace, err := c.pageSpaceKey(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	destSpace, err := c.pageSpaceKey(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Recursive {
+		if parentID == pageID {
+			return nil, fmt.Errorf("confluence: cannot copy page %s into its own subtree", pageID)
+		}
+		ancestors, err := c.GetAncestorsContext(ctx, parentID)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range ancestors {
+			if a.ID == pageID {
+				return nil, fmt.Errorf("confluence: cannot copy page %s into its own subtree", pageID)
+			}
+		}
+	}
+	if opts.TitlePrefix == "" && strings.EqualFold(srcSpace, destSpace) {
+		opts.TitlePrefix = defaultCopyPrefix
+	}
+
+	cp := &pageCopier{
+		c:         c,
+		opts:      opts,
+		native:    c.Has(CapV1),
+		srcSpace:  srcSpace,
+		destSpace: destSpace,
+		titles:    map[string]string{},
+		result:    &CopyResult{IDs: map[string]string{}},
+	}
+	if err := cp.copyTree(ctx, src, parentID); err != nil {
+		return cp.result, err
+	}
+	cp.result.RootID = cp.result.IDs[pageID]
+	if err := cp.rewriteLinks(ctx); err != nil {
+		return cp.result, err
+	}
+	return cp.result, nil
+}
+
+// pageCopier carries the state of one CopyPage call.
+type pageCopier struct {
+	c         *Client
+	opts      CopyOptions
+	native    bool // the v1 copy endpoint is believed to exist
+	srcSpace  string
+	destSpace string
+	titles    map[string]string // source title -> copy's title
+	result    *CopyResult
+	warned    bool // attachment warning already given
+}
+
+func (cp *pageCopier) copyTree(ctx context.Context, src *Page, parentID string) error {
+	newID, err := cp.copyOne(ctx, src, parentID)
+	if err != nil {
+		return fmt.Errorf("copying page %s: %w", src.ID, err)
+	}
+	cp.result.IDs[src.ID] = newID
+	cp.titles[src.Title] = cp.opts.TitlePrefix + src.Title
+	if cp.result.RootID == "" {
+		cp.result.RootID = newID
+	}
+	if !cp.opts.Recursive {
+		return nil
+	}
+
+	children, err := cp.c.GetChildrenContext(ctx, src.ID, 0)
+	if err != nil {
+		return fmt.Errorf("listing children of %s: %w", src.ID, err)
+	}
+	for i := range children {
+		if err := cp.copyTree(ctx, &children[i], newID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyOne copies a single page under parentID and returns the copy's ID.
+func (cp *pageCopier) copyOne(ctx context.Context, src *Page, parentID string) (string, error) {
+	title := cp.opts.TitlePrefix + src.Title
+	if cp.native {
+		id, err := cp.copyNative(ctx, src.ID, parentID, title)
+		if err == nil {
+			return id, nil
+		}
+		if !endpointMissing(err) {
+			return "", err
+		}
+		cp.native = false
+	}
+
+	full, err := cp.c.GetPageContext(ctx, src.ID, true)
+	if err != nil {
+		return "", err
+	}
+	created, err := cp.c.CreatePageContext(ctx, cp.destSpace, title, storageValue(full), parentID)
+	if err != nil {
+		return "", err
+	}
+	labels, err := cp.c.GetLabelsContext(ctx, src.ID)
+	if err != nil {
+		return created.ID, err
+	}
+	if len(labels) > 0 {
+		names := make([]string, len(labels))
+		for i, l := range labels {
+			names[i] = l.Name
+		}
+		if err := cp.c.AddLabelsContext(ctx, created.ID, names); err != nil {
+			return created.ID, err
+		}
+	}
+	if cp.opts.Attachments && !cp.warned {
+		cp.result.Warnings = append(cp.result.Warnings, "attachments were not copied: this instance has no page copy endpoint")
+		cp.warned = true
+	}
+	return created.ID, nil
+}
+
+func (cp *pageCopier) copyNative(ctx context.Context, pageID, parentID, title string) (string, error) {
+	req := map[string]any{
+		"copyAttachments":    cp.opts.Attachments,
+		"copyLabels":         true,
+		"copyProperties":     true,
+		"copyPermissions":    false,
+		"copyCustomContents": false,
+		"destination":        map[string]string{"type": "parent_page", "value": parentID},
+		"pageTitle":          title,
+	}
+	data, err := cp.c.postV1(ctx, "content/"+pageID+"/copy", req)
+	if err != nil {
+		return "", err
+	}
+	var v1 V1Content
+	if err := json.Unmarshal(data, &v1); err != nil {
+		return "", fmt.Errorf("parsing copied page: %w", err)
+	}
+	return v1.ID, nil
+}
+
+// rewriteLinks points links in the copies that target other copied pages at
+// those copies, publishing a new version only where something changed.
+func (cp *pageCopier) rewriteLinks(ctx context.Context) error {
+	sources := make([]string, 0, len(cp.result.IDs))
+	for src := range cp.result.IDs {
+		sources = append(sources, src)
+	}
+	slices.Sort(sources)
+
+	for _, src := range sources {
+		id := cp.result.IDs[src]
+		page, err := cp.c.GetPageContext(ctx, id, true)
+		if err != nil {
+			return fmt.Errorf("reading copy %s: %w", id, err)
+		}
+		body := storageValue(page)
+		rewritten := rewriteCopyLinks(body, cp.result.IDs, cp.titles, cp.srcSpace, cp.destSpace)
+		if rewritten == body {
+			continue
+		}
+		if _, err := cp.c.UpdatePageContext(ctx, id, "", rewritten, "Point links at copied pages"); err != nil {
+			return fmt.Errorf("rewriting links in copy %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// rewriteCopyLinks rewrites a copied body so that links to copied pages, by
+// ID or by title, reach the copies. When the copy lives in another space,
+// title links without a space key to pages that weren't copied get the
+// source space key so they keep reaching the originals.
+func rewriteCopyLinks(body string, ids, titles map[string]string, srcSpace, destSpace string) string {
+	body = contentIDPattern.ReplaceAllStringFunc(body, func(m string) string {
+		sub := contentIDPattern.FindStringSubmatch(m)
+		if id, ok := ids[sub[2]]; ok {
+			return sub[1] + id + sub[3]
+		}
+		return m
+	})
+	body = pageURLPattern.ReplaceAllStringFunc(body, func(m string) string {
+		sub := pageURLPattern.FindStringSubmatch(m)
+		if id, ok := ids[sub[2]]; ok {
+			return sub[1] + id
+		}
+		return m
+	})
+
+	crossSpace := !strings.EqualFold(srcSpace, destSpace)
+	return riPagePattern.ReplaceAllStringFunc(body, func(tag string) string {
+		title := titleAttrPattern.FindStringSubmatch(tag)
+		if title == nil {
+			return tag
+		}
+		linkSpace := srcSpace
+		space := spaceAttrPattern.FindStringSubmatch(tag)
+		if space != nil {
+			linkSpace = space[1]
+		}
+		if !strings.EqualFold(linkSpace, srcSpace) {
+			return tag
+		}
+
+		newTitle, copied := titles[html.UnescapeString(title[1])]
+		switch {
+		case copied:
+			tag = strings.Replace(tag, title[0], `ri:content-title="`+html.EscapeString(newTitle)+`"`, 1)
+			if space != nil && crossSpace {
+				tag = strings.Replace(tag, space[0], `ri:space-key="`+html.EscapeString(destSpace)+`"`, 1)
+			}
+		case space == nil && crossSpace:
+			tag = strings.Replace(tag, "<ri:page", `<ri:page ri:space-key="`+html.EscapeString(srcSpace)+`"`, 1)
+		}
+		return tag
+	})
+}
+
+// pageSpaceKey returns the key of the space p is in.
+func (c *Client) pageSpaceKey(ctx context.Context, p *Page) (string, error) {
+	if p.SpaceKey != "" {
+		return p.SpaceKey, nil
+	}
+	return c.SpaceKeyForIDContext(ctx, p.SpaceID)
+}
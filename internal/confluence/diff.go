@@ -0,0 +1,295 @@
+package confluence
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// blockElements start a new line when bodies are split for diffing, so a
+// change shows up as the paragraph, list item or cell it touched rather than
+// as the whole (often single-line) body.
+var blockElements = map[string]bool{
+	"p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"ul": true, "ol": true, "li": true, "blockquote": true, "pre": true, "div": true, "hr": true,
+	"table": true, "colgroup": true, "col": true, "thead": true, "tbody": true, "tr": true, "th": true, "td": true,
+	"ac:structured-macro": true, "ac:parameter": true, "ac:rich-text-body": true, "ac:plain-text-body": true,
+	"ac:task-list": true, "ac:task": true, "ac:layout": true, "ac:layout-section": true, "ac:layout-cell": true,
+}
+
+// attrPattern matches one attribute of a start tag: name, and a double-quoted,
+// single-quoted or bare value.
+var attrPattern = regexp.MustCompile(`([^\s=/>]+)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+)))?`)
+
+// StorageDiff is a line-by-line comparison of two storage-format bodies.
+// Each block-level element sits on its own line.
+type StorageDiff struct {
+	From   []string    `json:"-"`
+	To     []string    `json:"-"`
+	Blocks []DiffBlock `json:"blocks"`
+
+	ops []diffOp
+}
+
+// DiffBlock is one changed region. FromLine and ToLine are the 1-based lines
+// where it starts in each body; for pure additions FromLine is the line the
+// new lines were inserted before, and likewise ToLine for removals.
+type DiffBlock struct {
+	Kind     string   `json:"kind"` // "added", "removed" or "changed"
+	FromLine int      `json:"fromLine"`
+	ToLine   int      `json:"toLine"`
+	Removed  []string `json:"removed,omitempty"`
+	Added    []string `json:"added,omitempty"`
+}
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	text string
+	from int // 0-based line in From before this op
+	to   int // 0-based line in To before this op
+}
+
+// DiffStorage compares two storage bodies. With normalize, whitespace runs
+// are collapsed and tag attributes sorted first, so re-serialized but
+// otherwise identical markup compares equal.
+func DiffStorage(from, to string, normalize bool) (*StorageDiff, error) {
+	d := &StorageDiff{From: storageLines(from, normalize), To: storageLines(to, normalize), Blocks: []DiffBlock{}}
+	match, err := lcsMatches(d.From, d.To)
+	if err != nil {
+		return nil, err
+	}
+
+	i, j := 0, 0
+	for i < len(d.From) || j < len(d.To) {
+		switch {
+		case i < len(d.From) && match[i] == j:
+			d.ops = append(d.ops, diffOp{' ', d.From[i], i, j})
+			i, j = i+1, j+1
+		case i < len(d.From) && match[i] < 0:
+			d.ops = append(d.ops, diffOp{'-', d.From[i], i, j})
+			i++
+		default:
+			d.ops = append(d.ops, diffOp{'+', d.To[j], i, j})
+			j++
+		}
+	}
+
+	for k := 0; k < len(d.ops); {
+		if d.ops[k].kind == ' ' {
+			k++
+			continue
+		}
+		b := DiffBlock{FromLine: d.ops[k].from + 1, ToLine: d.ops[k].to + 1}
+		for ; k < len(d.ops) && d.ops[k].kind != ' '; k++ {
+			if d.ops[k].kind == '-' {
+				b.Removed = append(b.Removed, d.ops[k].text)
+			} else {
+				b.Added = append(b.Added, d.ops[k].text)
+			}
+		}
+		switch {
+		case b.Removed == nil:
+			b.Kind = "added"
+		case b.Added == nil:
+			b.Kind = "removed"
+		default:
+			b.Kind = "changed"
+		}
+		d.Blocks = append(d.Blocks, b)
+	}
+	return d, nil
+}
+
+// Changed reports whether the bodies differ.
+func (d *StorageDiff) Changed() bool {
+	return len(d.Blocks) > 0
+}
+
+// Unified renders the diff in unified format with context lines around each
+// change, labelling the sides fromLabel and toLabel. It is empty when
+// nothing changed.
+func (d *StorageDiff) Unified(fromLabel, toLabel string, context int) string {
+	if !d.Changed() {
+		return ""
+	}
+	context = max(context, 0)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromLabel, toLabel)
+	for k := 0; k < len(d.ops); {
+		if d.ops[k].kind == ' ' {
+			k++
+			continue
+		}
+		// A hunk runs from context lines before this change to context lines
+		// after the last change that is no more than 2*context lines away.
+		start := max(k-context, 0)
+		end := k
+		for end < len(d.ops) {
+			if d.ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(d.ops) && d.ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(d.ops) || next-end > 2*context {
+				end = min(end+context, len(d.ops))
+				break
+			}
+			end = next
+		}
+
+		hunk := d.ops[start:end]
+		fromCount, toCount := 0, 0
+		for _, op := range hunk {
+			if op.kind != '+' {
+				fromCount++
+			}
+			if op.kind != '-' {
+				toCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(hunk[0].from, fromCount), hunkRange(hunk[0].to, toCount))
+		for _, op := range hunk {
+			out.WriteByte(op.kind)
+			out.WriteString(op.text)
+			out.WriteByte('\n')
+		}
+		k = end
+	}
+	return out.String()
+}
+
+// hunkRange formats a unified-diff line range. An empty range names the
+// line before it, as diff(1) does.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// NormalizeStorage canonicalizes cosmetic details of a storage body: runs of
+// whitespace become one space, whitespace-only runs between tags are dropped,
+// and attributes are sorted and double-quoted.
+func NormalizeStorage(s string) string {
+	var out strings.Builder
+	for _, tok := range tokenizeStorage(s) {
+		out.WriteString(normalizeToken(tok))
+	}
+	return out.String()
+}
+
+func normalizeToken(tok string) string {
+	if !strings.HasPrefix(tok, "<") {
+		if strings.TrimSpace(tok) == "" {
+			return ""
+		}
+		text := strings.Join(strings.Fields(tok), " ")
+		if strings.TrimLeft(tok, " \t\r\n") != tok {
+			text = " " + text
+		}
+		if strings.TrimRight(tok, " \t\r\n") != tok {
+			text += " "
+		}
+		return text
+	}
+	// Comments, CDATA and processing instructions are kept verbatim.
+	if strings.HasPrefix(tok, "<!") || strings.HasPrefix(tok, "<?") || !strings.HasSuffix(tok, ">") {
+		return tok
+	}
+	if strings.HasPrefix(tok, "</") {
+		return "</" + strings.TrimSpace(tok[2:len(tok)-1]) + ">"
+	}
+
+	inner := strings.TrimSpace(tok[1 : len(tok)-1])
+	selfClosing := strings.HasSuffix(inner, "/")
+	inner = strings.TrimSpace(strings.TrimSuffix(inner, "/"))
+	name, rest, _ := strings.Cut(inner, " ")
+	if i := strings.IndexAny(name, "\t\n\r"); i >= 0 {
+		name, rest = name[:i], name[i:]+" "+rest
+	}
+
+	var attrs []string
+	for _, m := range attrPattern.FindAllStringSubmatch(rest, -1) {
+		value := m[2] + m[3] + m[4]
+		attrs = append(attrs, m[1]+`="`+strings.ReplaceAll(value, `"`, "&quot;")+`"`)
+	}
+	slices.Sort(attrs)
+
+	var b strings.Builder
+	b.WriteString("<" + name)
+	for _, a := range attrs {
+		b.WriteString(" " + a)
+	}
+	if selfClosing {
+		b.WriteString(" /")
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// storageLines splits a body into lines for diffing: one per block-level
+// element, plus any line breaks already in the markup.
+func storageLines(s string, normalize bool) []string {
+	var lines []string
+	var cur strings.Builder
+	flush := func() {
+		for _, line := range strings.Split(cur.String(), "\n") {
+			if normalize {
+				line = strings.TrimSpace(line)
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+		}
+		cur.Reset()
+	}
+
+	for _, tok := range tokenizeStorage(s) {
+		if normalize {
+			tok = normalizeToken(tok)
+		}
+		name, closing, selfClosing := tagName(tok)
+		if !blockElements[name] {
+			cur.WriteString(tok)
+			continue
+		}
+		if !closing {
+			flush()
+		}
+		cur.WriteString(tok)
+		if closing || selfClosing {
+			flush()
+		}
+	}
+	flush()
+	return lines
+}
+
+// tagName returns the lower-cased element name of a tag token, or "" for
+// text and markup that isn't an element tag.
+func tagName(tok string) (name string, closing, selfClosing bool) {
+	if !strings.HasPrefix(tok, "<") || !strings.HasSuffix(tok, ">") || strings.HasPrefix(tok, "<!") || strings.HasPrefix(tok, "<?") {
+		return "", false, false
+	}
+	inner := tok[1 : len(tok)-1]
+	if strings.HasPrefix(inner, "/") {
+		closing = true
+		inner = inner[1:]
+	}
+	selfClosing = strings.HasSuffix(inner, "/")
+	end := strings.IndexFunc(inner, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '/'
+	})
+	if end < 0 {
+		end = len(inner)
+	}
+	return strings.ToLower(inner[:end]), closing, selfClosing
+}
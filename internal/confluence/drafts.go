@@ -0,0 +1,178 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Drafts come in two kinds. A page created as a draft has status "draft"
+// until it is first published. On Cloud a published page can also carry a
+// pending draft: unpublished edits saved next to the live version. Server/DC
+// exposes only the first kind through REST.
+
+// GetDraft returns a page's draft with its storage body: the page itself if
+// it was never published, its pending draft on Cloud, and otherwise the
+// published page (status "current"), meaning there is no draft.
+func (c *Client) GetDraft(pageID string) (*Page, error) {
+	return c.GetDraftContext(context.Background(), pageID)
+}
+
+// GetDraftContext is like GetDraft but honors ctx for cancellation and deadlines.
+func (c *Client) GetDraftContext(ctx context.Context, pageID string) (*Page, error) {
+	if c.Has(CapV2) {
+		q := url.Values{"get-draft": {"true"}, "body-format": {"storage"}}
+		data, err := c.getV2(ctx, "pages/"+pageID, q)
+		if err != nil {
+			return nil, err
+		}
+		var page Page
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("parsing draft: %w", err)
+		}
+		return &page, nil
+	}
+
+	q := url.Values{"status": {"draft"}, "expand": {"version,space,ancestors,body.storage"}}
+	data, err := c.getV1(ctx, "content/"+pageID, q)
+	if err != nil {
+		return nil, err
+	}
+	var v1 V1Content
+	if err := json.Unmarshal(data, &v1); err != nil {
+		return nil, fmt.Errorf("parsing v1 draft: %w", err)
+	}
+	return v1ToPage(&v1), nil
+}
+
+// ListDrafts lists the drafts in a space, including pending drafts of
+// published pages on Cloud (v2 Cloud, v1 Server/DC).
+func (c *Client) ListDrafts(spaceKey string, limit int) ([]Page, error) {
+	return c.ListDraftsContext(context.Background(), spaceKey, limit)
+}
+
+// ListDraftsContext is like ListDrafts but honors ctx for cancellation and deadlines.
+func (c *Client) ListDraftsContext(ctx context.Context, spaceKey string, limit int) ([]Page, error) {
+	return c.listPagesByStatus(ctx, spaceKey, "draft", limit)
+}
+
+// saveDraft stores an edit as the page's draft. Drafts aren't versioned:
+// a never-published page stays at version 1, and a pending draft on Cloud
+// carries the number it will be published as.
+func (c *Client) saveDraft(ctx context.Context, pageID, title, body, message string) (*Page, error) {
+	draft, err := c.GetDraftContext(ctx, pageID)
+	if err != nil {
+		return nil, fmt.Errorf("reading draft: %w", err)
+	}
+	if title == "" {
+		title = draft.Title
+	}
+	if body == "" {
+		body = storageValue(draft)
+	}
+	version := 1
+	if draft.Version != nil {
+		version = draft.Version.Number
+		if draft.Status != "draft" {
+			version++
+		}
+	}
+
+	if c.Has(CapV2) {
+		req := UpdatePageRequest{
+			ID:      pageID,
+			Status:  "draft",
+			Title:   title,
+			Body:    &CreatePageBody{Representation: "storage", Value: body},
+			Version: &VersionUpdate{Number: version, Message: message},
+		}
+		data, err := c.putV2(ctx, "pages/"+pageID, req)
+		if err != nil {
+			return nil, err
+		}
+		var page Page
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("parsing draft: %w", err)
+		}
+		return &page, nil
+	}
+
+	if draft.Status != "draft" {
+		return nil, fmt.Errorf("confluence: page %s is published, and Server/DC can only save drafts of pages that were created as drafts", pageID)
+	}
+	return c.putDraftV1(ctx, pageID, "draft", title, body, message)
+}
+
+// PublishPage makes a page's draft live: a never-published page becomes
+// current at version 1, a pending draft on Cloud becomes the next version.
+// Pages without a draft are refused.
+func (c *Client) PublishPage(pageID, message string) (*Page, error) {
+	return c.PublishPageContext(context.Background(), pageID, message)
+}
+
+// PublishPageContext is like PublishPage but honors ctx for cancellation and deadlines.
+func (c *Client) PublishPageContext(ctx context.Context, pageID, message string) (*Page, error) {
+	draft, err := c.GetDraftContext(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Status != "draft" {
+		return nil, fmt.Errorf("confluence: page %s has no draft to publish", pageID)
+	}
+
+	if !c.Has(CapV2) {
+		return c.putDraftV1(ctx, pageID, "current", draft.Title, storageValue(draft), message)
+	}
+
+	// A page that was never published has no current version to follow.
+	version := 1
+	published, err := c.getPageV2(ctx, pageID, false)
+	switch {
+	case err == nil && published.Version != nil:
+		version = published.Version.Number + 1
+	case err != nil && !errors.Is(err, ErrNotFound):
+		return nil, err
+	}
+	req := UpdatePageRequest{
+		ID:      pageID,
+		Status:  "current",
+		Title:   draft.Title,
+		Body:    &CreatePageBody{Representation: "storage", Value: storageValue(draft)},
+		Version: &VersionUpdate{Number: version, Message: message},
+	}
+	data, err := c.putV2(ctx, "pages/"+pageID, req)
+	if err != nil {
+		return nil, err
+	}
+	var page Page
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, fmt.Errorf("parsing published page: %w", err)
+	}
+	return &page, nil
+}
+
+// putDraftV1 saves (status "draft") or publishes (status "current") a v1
+// draft, which is addressed with status=draft and stays at version 1.
+func (c *Client) putDraftV1(ctx context.Context, pageID, status, title, body, message string) (*Page, error) {
+	v1Req := map[string]interface{}{
+		"type":   "page",
+		"status": status,
+		"title":  title,
+		"body": map[string]interface{}{
+			"storage": map[string]string{"value": body, "representation": "storage"},
+		},
+		"version": map[string]interface{}{"number": 1, "message": message},
+	}
+	data, err := c.request(ctx, http.MethodPut, c.v1URL("content", pageID), url.Values{"status": {"draft"}}, v1Req)
+	if err != nil {
+		return nil, err
+	}
+	var v1 V1Content
+	if err := json.Unmarshal(data, &v1); err != nil {
+		return nil, fmt.Errorf("parsing v1 draft: %w", err)
+	}
+	return v1ToPage(&v1), nil
+}
@@ -0,0 +1,165 @@
+package confluence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors matched by errors.Is against an *APIError, e.g.
+//
+//	if errors.Is(err, confluence.ErrNotFound) { ... }
+var (
+	ErrNotFound        = errors.New("confluence: not found")
+	ErrUnauthorized    = errors.New("confluence: unauthorized")
+	ErrForbidden       = errors.New("confluence: forbidden")
+	ErrVersionConflict = errors.New("confluence: version conflict")
+	ErrRateLimited     = errors.New("confluence: rate limited")
+	ErrValidation      = errors.New("confluence: validation failed")
+	// ErrNotSupported is returned without a request when the instance was
+	// detected to lack the capability an operation needs.
+	ErrNotSupported = errors.New("confluence: not supported on this instance")
+)
+
+// Is reports whether the API error belongs to one of the sentinel categories.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrVersionConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrValidation:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	}
+	return false
+}
+
+// endpointMissing reports whether err says the instance doesn't offer an
+// endpoint at all (older Server/DC releases), as opposed to rejecting this
+// particular request. Callers check the content exists first.
+func endpointMissing(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// v1ErrorData is the "data" object Server/DC and v1 Cloud attach to validation failures.
+type v1ErrorData struct {
+	Errors []struct {
+		Message struct {
+			Key         string `json:"key"`
+			Translation string `json:"translation"`
+		} `json:"message"`
+	} `json:"errors"`
+}
+
+func parseAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: statusCode}
+	var payload struct {
+		Data *v1ErrorData `json:"data"`
+	}
+	err := json.Unmarshal(body, apiErr)
+	// v1 sends statusCode as a number; keep whatever else decoded fine.
+	var typeErr *json.UnmarshalTypeError
+	if err != nil && !errors.As(err, &typeErr) {
+		apiErr.Message = fmt.Sprintf("HTTP %d: %s", statusCode, string(body))
+		return apiErr
+	}
+
+	if json.Unmarshal(body, &payload) == nil && payload.Data != nil {
+		for _, e := range payload.Data.Errors {
+			apiErr.Errors = append(apiErr.Errors, ErrorDetail{
+				Status: statusCode,
+				Code:   e.Message.Key,
+				Detail: e.Message.Translation,
+			})
+		}
+	}
+
+	if apiErr.Message == "" && apiErr.ErrorMessage == "" {
+		if len(apiErr.Errors) > 0 {
+			details := make([]string, len(apiErr.Errors))
+			for i, d := range apiErr.Errors {
+				details[i] = d.String()
+			}
+			apiErr.Message = fmt.Sprintf("HTTP %d: %s", statusCode, strings.Join(details, "; "))
+		} else {
+			apiErr.Message = fmt.Sprintf("HTTP %d", statusCode)
+		}
+	}
+	return apiErr
+}
+
+// VersionMismatchError reports that a page moved past the version an update was based on.
+// It matches ErrVersionConflict.
+type VersionMismatchError struct {
+	PageID   string
+	Expected int
+	Current  int
+}
+
+func (e *VersionMismatchError) Error() string {
+	return fmt.Sprintf("page %s is at version %d, expected %d: it was edited since you read it", e.PageID, e.Current, e.Expected)
+}
+
+func (e *VersionMismatchError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+// MergeConflictError reports that an automatic three-way merge found overlapping edits.
+// It matches ErrVersionConflict.
+type MergeConflictError struct {
+	PageID    string
+	Base      int
+	Current   int
+	Conflicts int
+}
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("page %s: cannot merge your edit of version %d into version %d: %d conflicting region(s)", e.PageID, e.Base, e.Current, e.Conflicts)
+}
+
+func (e *MergeConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+// OAuthError is a failed OAuth token request, such as a revoked or
+// already-rotated refresh token. It matches ErrUnauthorized.
+type OAuthError struct {
+	StatusCode  int
+	Code        string // OAuth error code, e.g. "invalid_grant"
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	msg := "confluence: OAuth token request failed"
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(" (HTTP %d)", e.StatusCode)
+	}
+	if e.Code != "" {
+		msg += ": " + e.Code
+	}
+	if e.Description != "" {
+		msg += ": " + e.Description
+	}
+	return msg
+}
+
+// Is reports whether target is ErrUnauthorized.
+func (e *OAuthError) Is(target error) bool {
+	return target == ErrUnauthorized
+}
@@ -1,6 +1,7 @@
 package confluence
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -8,21 +9,18 @@ import (
 
 // GetLabels retrieves labels for a page.
 func (c *Client) GetLabels(pageID string) ([]Label, error) {
-	if c.IsCloud() {
-		data, err := c.getV2("pages/"+pageID+"/labels", nil)
-		if err != nil {
-			return nil, err
-		}
-		var page CursorPage[Label]
-		if err := json.Unmarshal(data, &page); err != nil {
-			return nil, fmt.Errorf("parsing labels: %w", err)
-		}
-		return page.Results, nil
+	return c.GetLabelsContext(context.Background(), pageID)
+}
+
+// GetLabelsContext is like GetLabels but honors ctx for cancellation and deadlines.
+func (c *Client) GetLabelsContext(ctx context.Context, pageID string) ([]Label, error) {
+	if c.Has(CapV2) {
+		return PaginateV2[Label](ctx, c, "pages/"+pageID+"/labels", nil, 0).All()
 	}
 
 	// V1
 	q := url.Values{"expand": {"metadata.labels"}}
-	data, err := c.getV1("content/"+pageID, q)
+	data, err := c.getV1(ctx, "content/"+pageID, q)
 	if err != nil {
 		return nil, err
 	}
@@ -38,12 +36,18 @@ func (c *Client) GetLabels(pageID string) ([]Label, error) {
 
 // AddLabels adds labels to a page.
 func (c *Client) AddLabels(pageID string, labels []string) error {
-	if c.IsCloud() {
+	return c.AddLabelsContext(context.Background(), pageID, labels)
+}
+
+// AddLabelsContext is like AddLabels but honors ctx for cancellation and deadlines.
+func (c *Client) AddLabelsContext(ctx context.Context, pageID string, labels []string) error {
+	defer c.evictPage(pageID)
+	if c.Has(CapV2) {
 		entries := make(AddLabelsRequest, len(labels))
 		for i, l := range labels {
 			entries[i] = AddLabelEntry{Prefix: "global", Name: l}
 		}
-		_, err := c.postV2("pages/"+pageID+"/labels", entries)
+		_, err := c.postV2(ctx, "pages/"+pageID+"/labels", entries)
 		return err
 	}
 
@@ -52,21 +56,27 @@ func (c *Client) AddLabels(pageID string, labels []string) error {
 	for i, l := range labels {
 		entries[i] = map[string]string{"prefix": "global", "name": l}
 	}
-	_, err := c.postV1("content/"+pageID+"/label", entries)
+	_, err := c.postV1(ctx, "content/"+pageID+"/label", entries)
 	return err
 }
 
 // RemoveLabel removes a label from a page.
 func (c *Client) RemoveLabel(pageID string, labelName string) error {
-	if c.IsCloud() {
+	return c.RemoveLabelContext(context.Background(), pageID, labelName)
+}
+
+// RemoveLabelContext is like RemoveLabel but honors ctx for cancellation and deadlines.
+func (c *Client) RemoveLabelContext(ctx context.Context, pageID string, labelName string) error {
+	defer c.evictPage(pageID)
+	if c.Has(CapV2) {
 		// V2 delete requires label ID — need to look it up first.
-		labels, err := c.GetLabels(pageID)
+		labels, err := c.GetLabelsContext(ctx, pageID)
 		if err != nil {
 			return err
 		}
 		for _, l := range labels {
 			if l.Name == labelName {
-				_, err := c.deleteV2("pages/" + pageID + "/labels/" + l.ID)
+				_, err := c.deleteV2(ctx, "pages/"+pageID+"/labels/"+l.ID)
 				return err
 			}
 		}
@@ -75,6 +85,6 @@ func (c *Client) RemoveLabel(pageID string, labelName string) error {
 
 	// V1: delete by name.
 	fullURL := c.v1URL("content/" + pageID + "/label/" + labelName)
-	_, err := c.request("DELETE", fullURL, nil, nil)
+	_, err := c.request(ctx, "DELETE", fullURL, nil, nil)
 	return err
 }
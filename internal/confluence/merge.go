@@ -0,0 +1,161 @@
+package confluence
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxMergeCells bounds the LCS table built for the changed middle of a body
+// (after common prefix and suffix are trimmed). Larger rewrites aren't merged.
+const maxMergeCells = 4_000_000
+
+// MergeResult is the outcome of a three-way merge of storage bodies.
+type MergeResult struct {
+	Body      string // merged body; only meaningful when Conflicts == 0
+	Conflicts int    // regions changed differently on both sides
+}
+
+// MergeStorage three-way merges two edits of a storage-format body against
+// their common ancestor. Bodies are compared as a sequence of tags and text
+// runs, so edits to different elements on the same (often single) line merge cleanly.
+func MergeStorage(base, ours, theirs string) (MergeResult, error) {
+	if ours == theirs {
+		return MergeResult{Body: ours}, nil
+	}
+	if ours == base {
+		return MergeResult{Body: theirs}, nil
+	}
+	if theirs == base {
+		return MergeResult{Body: ours}, nil
+	}
+
+	b, o, t := tokenizeStorage(base), tokenizeStorage(ours), tokenizeStorage(theirs)
+	matchO, err := lcsMatches(b, o)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	matchT, err := lcsMatches(b, t)
+	if err != nil {
+		return MergeResult{}, err
+	}
+
+	var out strings.Builder
+	conflicts := 0
+	i, oi, ti := 0, 0, 0
+	for i < len(b) || oi < len(o) || ti < len(t) {
+		// Stable: the next base token is kept, in place, by both sides.
+		if i < len(b) && matchO[i] == oi && matchT[i] == ti {
+			out.WriteString(b[i])
+			i, oi, ti = i+1, oi+1, ti+1
+			continue
+		}
+
+		// Unstable chunk: runs until the next base token both sides kept.
+		// It may be empty on the base side when a side only inserted tokens.
+		j := i
+		for j < len(b) && (matchO[j] < 0 || matchT[j] < 0) {
+			j++
+		}
+		oe, te := len(o), len(t)
+		if j < len(b) {
+			oe, te = matchO[j], matchT[j]
+		} else {
+			j = len(b)
+		}
+
+		baseChunk := strings.Join(b[i:j], "")
+		oursChunk := strings.Join(o[oi:oe], "")
+		theirsChunk := strings.Join(t[ti:te], "")
+		switch {
+		case oursChunk == theirsChunk, theirsChunk == baseChunk:
+			out.WriteString(oursChunk)
+		case oursChunk == baseChunk:
+			out.WriteString(theirsChunk)
+		default:
+			conflicts++
+		}
+		i, oi, ti = j, oe, te
+	}
+
+	return MergeResult{Body: out.String(), Conflicts: conflicts}, nil
+}
+
+// tokenizeStorage splits markup into tags and the text between them.
+func tokenizeStorage(s string) []string {
+	var tokens []string
+	for len(s) > 0 {
+		var n int
+		if s[0] == '<' {
+			n = strings.IndexByte(s, '>') + 1
+			if n == 0 {
+				n = len(s)
+			}
+		} else {
+			n = strings.IndexByte(s, '<')
+			if n < 0 {
+				n = len(s)
+			}
+		}
+		tokens = append(tokens, s[:n])
+		s = s[n:]
+	}
+	return tokens
+}
+
+// lcsMatches returns, for each index of a, the index of the matching token in b
+// under a longest common subsequence alignment, or -1 when it was removed.
+func lcsMatches(a, b []string) ([]int, error) {
+	match := make([]int, len(a))
+	for i := range match {
+		match[i] = -1
+	}
+
+	// Common prefix and suffix align trivially; only the middle needs a table.
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		match[pre] = pre
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		match[len(a)-1-suf] = len(b) - 1 - suf
+		suf++
+	}
+
+	am, bm := a[pre:len(a)-suf], b[pre:len(b)-suf]
+	n, m := len(am), len(bm)
+	if n == 0 || m == 0 {
+		return match, nil
+	}
+	if n*m > maxMergeCells {
+		return nil, fmt.Errorf("confluence: changes too large to compare automatically (%d x %d tokens)", n, m)
+	}
+
+	// lengths[i][j] = LCS length of am[i:] and bm[j:].
+	lengths := make([][]int32, n+1)
+	for i := range lengths {
+		lengths[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if am[i] == bm[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case am[i] == bm[j]:
+			match[pre+i] = pre + j
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return match, nil
+}
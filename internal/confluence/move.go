@@ -0,0 +1,155 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MovePosition says where a moved page lands relative to the target page.
+type MovePosition string
+
+const (
+	MoveAppend MovePosition = "append" // last child of the target
+	MoveBefore MovePosition = "before" // sibling just before the target
+	MoveAfter  MovePosition = "after"  // sibling just after the target
+)
+
+// MoveResult is a moved page and where it ended up.
+type MoveResult struct {
+	Page      *Page      `json:"page"`
+	Ancestors []Ancestor `json:"ancestors"` // new breadcrumb chain, root first
+	Warnings  []string   `json:"warnings,omitempty"`
+}
+
+// MovePage moves a page and its children relative to targetID, into the
+// target's space if that differs. REST v1 (Server/DC and Cloud) supports
+// every position and cross-space moves; sites with only v2 can re-parent
+// within a space.
+func (c *Client) MovePage(pageID string, position MovePosition, targetID string) (*MoveResult, error) {
+	return c.MovePageContext(context.Background(), pageID, position, targetID)
+}
+
+// MovePageContext is like MovePage but honors ctx for cancellation and deadlines.
+func (c *Client) MovePageContext(ctx context.Context, pageID string, position MovePosition, targetID string) (*MoveResult, error) {
+	switch position {
+	case MoveAppend, MoveBefore, MoveAfter:
+	default:
+		return nil, fmt.Errorf("confluence: unknown move position %q (want append, before or after)", position)
+	}
+	if pageID == targetID {
+		return nil, fmt.Errorf("confluence: cannot move page %s relative to itself", pageID)
+	}
+
+	page, err := c.GetPageContext(ctx, pageID, false)
+	if err != nil {
+		return nil, err
+	}
+	target, err := c.GetPageContext(ctx, targetID, false)
+	if err != nil {
+		return nil, fmt.Errorf("reading target page %s: %w", targetID, err)
+	}
+
+	// Titles are unique per space, so only a move into another space can
+	// collide, and the whole subtree moves with the page.
+	var warnings []string
+	if target.SpaceID != page.SpaceID {
+		warnings, err = c.titleCollisions(ctx, target, page)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.Has(CapV1) {
+		err = c.movePageV1(ctx, pageID, position, targetID)
+	} else {
+		err = c.movePageV2(ctx, page, position, target)
+	}
+	c.evictPage(pageID)
+	if err != nil {
+		if len(warnings) > 0 {
+			return nil, fmt.Errorf("%w (%s)", err, strings.Join(warnings, "; "))
+		}
+		return nil, err
+	}
+
+	moved, err := c.GetPageContext(ctx, pageID, false)
+	if err != nil {
+		return nil, err
+	}
+	ancestors, err := c.GetAncestorsContext(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+	return &MoveResult{Page: moved, Ancestors: ancestors, Warnings: warnings}, nil
+}
+
+// titleCollisions describes the pages in target's space that already have
+// the title of page or one of its descendants.
+func (c *Client) titleCollisions(ctx context.Context, target, page *Page) ([]string, error) {
+	spaceKey, err := c.pageSpaceKey(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	var warnings []string
+	subtree := []Page{*page}
+	for len(subtree) > 0 {
+		p := subtree[0]
+		subtree = subtree[1:]
+
+		existing, err := c.ListPagesContext(ctx, spaceKey, p.Title, 0)
+		if err != nil {
+			return nil, fmt.Errorf("checking titles in space %s: %w", spaceKey, err)
+		}
+		for _, e := range existing {
+			if e.ID != p.ID && strings.EqualFold(e.Title, p.Title) {
+				warnings = append(warnings, fmt.Sprintf("space %s already has a page titled %q (%s); Confluence rejects duplicate titles in a space, so rename one of them", spaceKey, p.Title, e.ID))
+				break
+			}
+		}
+
+		children, err := c.GetChildrenContext(ctx, p.ID, 0)
+		if err != nil {
+			return nil, fmt.Errorf("reading children of %s: %w", p.ID, err)
+		}
+		subtree = append(subtree, children...)
+	}
+	return warnings, nil
+}
+
+func (c *Client) movePageV1(ctx context.Context, pageID string, position MovePosition, targetID string) error {
+	fullURL := c.v1URL("content", pageID, "move", string(position), targetID)
+	_, err := c.request(ctx, http.MethodPut, fullURL, nil, nil)
+	return err
+}
+
+// movePageV2 re-parents a page through a regular update, which v2 only
+// allows within a space and without choosing the position among siblings.
+func (c *Client) movePageV2(ctx context.Context, page *Page, position MovePosition, target *Page) error {
+	if position != MoveAppend {
+		return fmt.Errorf("confluence: this site only offers REST v2, which cannot place a page before or after a sibling")
+	}
+	if target.SpaceID != page.SpaceID {
+		return fmt.Errorf("confluence: this site only offers REST v2, which cannot move pages between spaces")
+	}
+
+	current, err := c.GetPageContext(ctx, page.ID, true)
+	if err != nil {
+		return err
+	}
+	version := 0
+	if current.Version != nil {
+		version = current.Version.Number
+	}
+	req := UpdatePageRequest{
+		ID:       page.ID,
+		Status:   "current",
+		Title:    current.Title,
+		ParentID: target.ID,
+		Body:     &CreatePageBody{Representation: "storage", Value: storageValue(current)},
+		Version:  &VersionUpdate{Number: version + 1, Message: "Moved under " + target.Title},
+	}
+	_, err = c.putV2(ctx, "pages/"+page.ID, req)
+	return err
+}
@@ -0,0 +1,352 @@
+package confluence
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Atlassian OAuth 2.0 (3LO) endpoints.
+const (
+	AtlassianAuthorizeURL = "https://auth.atlassian.com/authorize"
+	AtlassianTokenURL     = "https://auth.atlassian.com/oauth/token"
+	AtlassianAPIURL       = "https://api.atlassian.com"
+)
+
+// oauthExpiryLeeway refreshes access tokens slightly early so a token doesn't
+// expire between the check and the server receiving the request.
+const oauthExpiryLeeway = time.Minute
+
+// OAuthConfig configures OAuth 2.0 (3LO) access for Confluence Cloud. With
+// AuthType AuthOAuth, Config.Token is the current access token and requests
+// go to the API gateway at {APIURL}/ex/confluence/{CloudID}.
+type OAuthConfig struct {
+	ClientID     string
+	ClientSecret string    // optional for PKCE apps that don't issue a secret
+	CloudID      string    // site ID from the accessible-resources endpoint
+	RefreshToken string    // requires the offline_access scope
+	Expiry       time.Time // access token expiry; zero means unknown
+	AccountID    string    // signed-in account; without it responses aren't cached
+
+	AuthorizeURL string // default AtlassianAuthorizeURL
+	TokenURL     string // default AtlassianTokenURL
+	APIURL       string // default AtlassianAPIURL
+
+	// OnRefresh is called with every new token pair. Atlassian rotates refresh
+	// tokens, so callers must persist it or the next run can't refresh.
+	OnRefresh func(OAuthToken)
+}
+
+// OAuthToken is an access/refresh token pair returned by the token endpoint.
+type OAuthToken struct {
+	AccessToken  string
+	RefreshToken string
+	Expiry       time.Time
+	Scope        string
+}
+
+// expired reports whether t needs refreshing at now.
+func (t OAuthToken) expired(now time.Time) bool {
+	return t.AccessToken == "" || (!t.Expiry.IsZero() && now.Add(oauthExpiryLeeway).After(t.Expiry))
+}
+
+// AccessibleResource is a site the OAuth grant can access.
+type AccessibleResource struct {
+	ID     string   `json:"id"` // the cloud ID
+	URL    string   `json:"url"`
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// OAuthApp performs the token-endpoint side of the 3LO flow: building the
+// consent URL, exchanging the authorization code, and refreshing tokens.
+type OAuthApp struct {
+	cfg        OAuthConfig
+	httpClient *http.Client
+	onTrace    func(TraceEntry)
+}
+
+// NewOAuthApp returns an app for cfg.OAuth that uses the same proxy and TLS
+// settings as a client built from cfg.
+func NewOAuthApp(cfg Config) (*OAuthApp, error) {
+	if cfg.OAuth == nil || cfg.OAuth.ClientID == "" {
+		return nil, fmt.Errorf("confluence: OAuth client ID is required")
+	}
+	transport, _, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &OAuthApp{
+		cfg:        cfg.OAuth.withDefaults(),
+		httpClient: &http.Client{Timeout: 30 * time.Second, Transport: transport},
+		onTrace:    cfg.OnTrace,
+	}, nil
+}
+
+func (o OAuthConfig) withDefaults() OAuthConfig {
+	if o.AuthorizeURL == "" {
+		o.AuthorizeURL = AtlassianAuthorizeURL
+	}
+	if o.TokenURL == "" {
+		o.TokenURL = AtlassianTokenURL
+	}
+	if o.APIURL == "" {
+		o.APIURL = AtlassianAPIURL
+	}
+	o.APIURL = strings.TrimRight(o.APIURL, "/")
+	return o
+}
+
+// NewPKCE returns a random PKCE code verifier and its S256 challenge.
+func NewPKCE() (verifier, challenge string, err error) {
+	verifier, err = randomURLToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	return verifier, pkceChallenge(verifier), nil
+}
+
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// NewOAuthState returns a random value for the authorization request's state parameter.
+func NewOAuthState() (string, error) {
+	return randomURLToken(16)
+}
+
+func randomURLToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("confluence: generating random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// AuthorizeURL returns the consent page URL the user opens in a browser.
+func (a *OAuthApp) AuthorizeURL(redirectURI, state, challenge string, scopes []string) string {
+	q := url.Values{}
+	q.Set("audience", "api.atlassian.com")
+	q.Set("client_id", a.cfg.ClientID)
+	q.Set("scope", strings.Join(scopes, " "))
+	q.Set("redirect_uri", redirectURI)
+	q.Set("state", state)
+	q.Set("response_type", "code")
+	q.Set("prompt", "consent")
+	q.Set("code_challenge", challenge)
+	q.Set("code_challenge_method", "S256")
+	return a.cfg.AuthorizeURL + "?" + q.Encode()
+}
+
+// Exchange trades an authorization code for tokens.
+func (a *OAuthApp) Exchange(ctx context.Context, code, redirectURI, verifier string) (OAuthToken, error) {
+	return a.token(ctx, a.httpClient, map[string]string{
+		"grant_type":    "authorization_code",
+		"code":          code,
+		"redirect_uri":  redirectURI,
+		"code_verifier": verifier,
+	})
+}
+
+// Refresh obtains a new token pair from a refresh token.
+func (a *OAuthApp) Refresh(ctx context.Context, refreshToken string) (OAuthToken, error) {
+	return a.token(ctx, a.httpClient, map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+	})
+}
+
+// AccessibleResources lists the sites an access token is valid for.
+func (a *OAuthApp) AccessibleResources(ctx context.Context, accessToken string) ([]AccessibleResource, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.cfg.APIURL+"/oauth/token/accessible-resources", nil)
+	if err != nil {
+		return nil, fmt.Errorf("confluence: failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	respBody, status, err := a.do(req, nil, a.httpClient)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, parseAPIError(status, respBody)
+	}
+	var resources []AccessibleResource
+	if err := json.Unmarshal(respBody, &resources); err != nil {
+		return nil, fmt.Errorf("confluence: parsing accessible resources: %w", err)
+	}
+	return resources, nil
+}
+
+// CurrentAccountID returns the account ID of the user accessToken was issued
+// to, read from the site's current-user endpoint through the gateway.
+func (a *OAuthApp) CurrentAccountID(ctx context.Context, accessToken, cloudID string) (string, error) {
+	u := a.cfg.APIURL + "/ex/confluence/" + url.PathEscape(cloudID) + "/wiki/rest/api/user/current"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", fmt.Errorf("confluence: failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	respBody, status, err := a.do(req, nil, a.httpClient)
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusOK {
+		return "", parseAPIError(status, respBody)
+	}
+	var user struct {
+		AccountID string `json:"accountId"`
+	}
+	if err := json.Unmarshal(respBody, &user); err != nil {
+		return "", fmt.Errorf("confluence: parsing current user: %w", err)
+	}
+	if user.AccountID == "" {
+		return "", fmt.Errorf("confluence: current user has no account ID")
+	}
+	return user.AccountID, nil
+}
+
+// token posts a grant to the token endpoint. Client credentials are added here.
+func (a *OAuthApp) token(ctx context.Context, hc *http.Client, grant map[string]string) (OAuthToken, error) {
+	grant["client_id"] = a.cfg.ClientID
+	if a.cfg.ClientSecret != "" {
+		grant["client_secret"] = a.cfg.ClientSecret
+	}
+	body, err := json.Marshal(grant)
+	if err != nil {
+		return OAuthToken{}, fmt.Errorf("confluence: failed to marshal token request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.cfg.TokenURL, bytes.NewReader(body))
+	if err != nil {
+		return OAuthToken{}, fmt.Errorf("confluence: failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	respBody, status, err := a.do(req, body, hc)
+	if err != nil {
+		return OAuthToken{}, err
+	}
+
+	var resp struct {
+		AccessToken      string `json:"access_token"`
+		RefreshToken     string `json:"refresh_token"`
+		ExpiresIn        int    `json:"expires_in"`
+		Scope            string `json:"scope"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	_ = json.Unmarshal(respBody, &resp)
+	if status != http.StatusOK || resp.AccessToken == "" {
+		return OAuthToken{}, &OAuthError{StatusCode: status, Code: resp.Error, Description: resp.ErrorDescription}
+	}
+
+	tok := OAuthToken{
+		AccessToken:  resp.AccessToken,
+		RefreshToken: resp.RefreshToken,
+		Scope:        resp.Scope,
+	}
+	if resp.ExpiresIn > 0 {
+		tok.Expiry = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+	return tok, nil
+}
+
+func (a *OAuthApp) do(req *http.Request, reqBody []byte, hc *http.Client) ([]byte, int, error) {
+	started := time.Now()
+	resp, err := hc.Do(req)
+	if err != nil {
+		traceHTTP(a.onTrace, req, reqBody, nil, nil, 0, 1, started, err)
+		return nil, 0, fmt.Errorf("confluence: %s %s: %w", req.Method, req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	traceHTTP(a.onTrace, req, reqBody, resp, respBody, 0, 1, started, err)
+	if err != nil {
+		return nil, 0, fmt.Errorf("confluence: failed to read response body: %w", err)
+	}
+	return respBody, resp.StatusCode, nil
+}
+
+// --- Client integration ---
+
+// oauthSession holds the current token for a client and refreshes it on demand.
+type oauthSession struct {
+	app *OAuthApp
+
+	mu    sync.Mutex
+	token OAuthToken
+}
+
+// authorization returns the Authorization header for the next attempt,
+// refreshing the OAuth access token first when it has expired or force is set.
+func (c *Client) authorization(ctx context.Context, force bool) (string, error) {
+	if c.oauth == nil {
+		return c.authHeader, nil
+	}
+	s := c.oauth
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !force && !s.token.expired(time.Now()) {
+		return "Bearer " + s.token.AccessToken, nil
+	}
+	if s.token.RefreshToken == "" {
+		return "", &OAuthError{Code: "invalid_grant", Description: "access token expired and no refresh token is stored"}
+	}
+
+	tok, err := s.app.token(ctx, c.httpClient, map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": s.token.RefreshToken,
+	})
+	if err != nil {
+		return "", fmt.Errorf("confluence: refreshing OAuth token: %w", err)
+	}
+	if tok.RefreshToken == "" {
+		tok.RefreshToken = s.token.RefreshToken
+	}
+	s.token = tok
+	if s.app.cfg.OnRefresh != nil {
+		s.app.cfg.OnRefresh(tok)
+	}
+	return "Bearer " + tok.AccessToken, nil
+}
+
+// newOAuthSession validates cfg for AuthOAuth and returns the session and
+// the gateway base URL requests are sent to.
+func newOAuthSession(cfg Config) (*oauthSession, string, error) {
+	if cfg.OAuth == nil {
+		return nil, "", fmt.Errorf("confluence: OAuth settings are required for oauth auth")
+	}
+	if cfg.OAuth.CloudID == "" {
+		return nil, "", fmt.Errorf("confluence: OAuth cloud ID is required")
+	}
+	if cfg.Token == "" && cfg.OAuth.RefreshToken == "" {
+		return nil, "", fmt.Errorf("confluence: OAuth access or refresh token is required")
+	}
+	// Token requests reuse the client's HTTP client, so no transport is built here.
+	app := &OAuthApp{cfg: cfg.OAuth.withDefaults(), onTrace: cfg.OnTrace}
+	session := &oauthSession{
+		app: app,
+		token: OAuthToken{
+			AccessToken:  cfg.Token,
+			RefreshToken: cfg.OAuth.RefreshToken,
+			Expiry:       cfg.OAuth.Expiry,
+		},
+	}
+	return session, app.cfg.APIURL + "/ex/confluence/" + url.PathEscape(cfg.OAuth.CloudID), nil
+}
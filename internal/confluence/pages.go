@@ -1,6 +1,7 @@
 package confluence
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -10,19 +11,33 @@ import (
 
 // GetPage retrieves a page by ID (v2 Cloud, v1 Server/DC).
 func (c *Client) GetPage(pageID string, includeBody bool) (*Page, error) {
-	if c.IsCloud() {
-		return c.getPageV2(pageID, includeBody)
+	return c.GetPageContext(context.Background(), pageID, includeBody)
+}
+
+// GetPageContext is like GetPage but honors ctx for cancellation and deadlines.
+func (c *Client) GetPageContext(ctx context.Context, pageID string, includeBody bool) (*Page, error) {
+	if c.Has(CapV2) {
+		return c.getPageV2(ctx, pageID, includeBody)
 	}
-	return c.getPageV1(pageID, includeBody)
+	return c.getPageV1(ctx, pageID, includeBody)
 }
 
-func (c *Client) getPageV2(pageID string, includeBody bool) (*Page, error) {
-	q := url.Values{}
+func (c *Client) getPageV2(ctx context.Context, pageID string, includeBody bool) (*Page, error) {
+	var data []byte
+	var err error
 	if includeBody {
-		q.Set("body-format", "storage")
+		data, err = c.getVersioned(ctx, c.v2URL("pages", pageID), pageBodyQueryV2(),
+			func(ctx context.Context) (int, error) { return c.pageVersionV2(ctx, pageID) },
+			func(data []byte) int {
+				var p Page
+				if json.Unmarshal(data, &p) != nil || p.Version == nil {
+					return 0
+				}
+				return p.Version.Number
+			})
+	} else {
+		data, err = c.getV2(ctx, "pages/"+pageID, nil)
 	}
-
-	data, err := c.getV2("pages/"+pageID, q)
 	if err != nil {
 		return nil, err
 	}
@@ -34,15 +49,27 @@ func (c *Client) getPageV2(pageID string, includeBody bool) (*Page, error) {
 	return &page, nil
 }
 
-func (c *Client) getPageV1(pageID string, includeBody bool) (*Page, error) {
-	q := url.Values{}
+// pageVersionV2 fetches only the page metadata (no body) to learn its current version.
+func (c *Client) pageVersionV2(ctx context.Context, pageID string) (int, error) {
+	page, err := c.getPageV2(ctx, pageID, false)
+	if err != nil {
+		return 0, err
+	}
+	if page.Version == nil {
+		return 0, fmt.Errorf("page %s has no version", pageID)
+	}
+	return page.Version.Number, nil
+}
+
+func (c *Client) getPageV1(ctx context.Context, pageID string, includeBody bool) (*Page, error) {
+	// Labels and ancestors change without a new page version, so only the
+	// body goes through the version-checked cache; the metadata is always
+	// fetched fresh and doubles as the version check.
 	expand := "version,space,ancestors,metadata.labels"
-	if includeBody {
+	if includeBody && c.cache == nil {
 		expand += ",body.storage"
 	}
-	q.Set("expand", expand)
-
-	data, err := c.getV1("content/"+pageID, q)
+	data, err := c.getV1(ctx, "content/"+pageID, url.Values{"expand": {expand}})
 	if err != nil {
 		return nil, err
 	}
@@ -51,19 +78,62 @@ func (c *Client) getPageV1(pageID string, includeBody bool) (*Page, error) {
 	if err := json.Unmarshal(data, &v1); err != nil {
 		return nil, fmt.Errorf("parsing v1 page: %w", err)
 	}
+	if includeBody && c.cache != nil {
+		if v1.Body, err = c.pageBodyV1(ctx, pageID, v1.Version); err != nil {
+			return nil, err
+		}
+	}
 	return v1ToPage(&v1), nil
 }
 
+// pageBodyV1 fetches a page's storage body through the response cache,
+// reusing a cached body stored for version.
+func (c *Client) pageBodyV1(ctx context.Context, pageID string, version *V1Version) (*V1Body, error) {
+	data, err := c.getVersioned(ctx, c.v1URL("content", pageID), pageBodyQueryV1(),
+		func(context.Context) (int, error) {
+			if version == nil {
+				return 0, fmt.Errorf("page %s has no version", pageID)
+			}
+			return version.Number, nil
+		},
+		func(data []byte) int {
+			var v1 V1Content
+			if json.Unmarshal(data, &v1) != nil || v1.Version == nil {
+				return 0
+			}
+			return v1.Version.Number
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	var v1 V1Content
+	if err := json.Unmarshal(data, &v1); err != nil {
+		return nil, fmt.Errorf("parsing v1 page body: %w", err)
+	}
+	return v1.Body, nil
+}
+
+// pageBodyQueryV2 and pageBodyQueryV1 are the queries of the cached page
+// body requests; evictPage rebuilds their cache keys from them.
+func pageBodyQueryV2() url.Values { return url.Values{"body-format": {"storage"}} }
+func pageBodyQueryV1() url.Values { return url.Values{"expand": {"body.storage,version"}} }
+
 // ListPages lists pages in a space (v2 Cloud, v1 Server/DC).
 func (c *Client) ListPages(spaceKey string, title string, limit int) ([]Page, error) {
-	if c.IsCloud() {
-		return c.listPagesV2(spaceKey, title, limit)
+	return c.ListPagesContext(context.Background(), spaceKey, title, limit)
+}
+
+// ListPagesContext is like ListPages but honors ctx for cancellation and deadlines.
+func (c *Client) ListPagesContext(ctx context.Context, spaceKey string, title string, limit int) ([]Page, error) {
+	if c.Has(CapV2) {
+		return c.listPagesV2(ctx, spaceKey, title, limit)
 	}
-	return c.listPagesV1(spaceKey, title, limit)
+	return c.listPagesV1(ctx, spaceKey, title, limit)
 }
 
-func (c *Client) listPagesV2(spaceKey string, title string, limit int) ([]Page, error) {
-	spaceID, err := c.ResolveSpaceKey(spaceKey)
+func (c *Client) listPagesV2(ctx context.Context, spaceKey string, title string, limit int) ([]Page, error) {
+	spaceID, err := c.ResolveSpaceKeyContext(ctx, spaceKey)
 	if err != nil {
 		return nil, err
 	}
@@ -72,23 +142,36 @@ func (c *Client) listPagesV2(spaceKey string, title string, limit int) ([]Page,
 	if title != "" {
 		q.Set("title", title)
 	}
-	if limit > 0 {
-		q.Set("limit", strconv.Itoa(limit))
+
+	return PaginateV2[Page](ctx, c, "pages", q, limit).All()
+}
+
+// listPagesByStatus lists a space's pages in another status than current,
+// such as "trashed" or "draft".
+func (c *Client) listPagesByStatus(ctx context.Context, spaceKey, status string, limit int) ([]Page, error) {
+	if c.Has(CapV2) {
+		spaceID, err := c.ResolveSpaceKeyContext(ctx, spaceKey)
+		if err != nil {
+			return nil, err
+		}
+		q := url.Values{"space-id": {spaceID}, "status": {status}}
+		return PaginateV2[Page](ctx, c, "pages", q, limit).All()
 	}
 
-	data, err := c.getV2("pages", q)
+	q := url.Values{
+		"type":     {"page"},
+		"spaceKey": {spaceKey},
+		"status":   {status},
+		"expand":   {"version,space"},
+	}
+	results, err := PaginateV1[V1Content](ctx, c, "content", q, 0, limit).All()
 	if err != nil {
 		return nil, err
 	}
-
-	var page CursorPage[Page]
-	if err := json.Unmarshal(data, &page); err != nil {
-		return nil, fmt.Errorf("parsing pages: %w", err)
-	}
-	return page.Results, nil
+	return v1ToPages(results), nil
 }
 
-func (c *Client) listPagesV1(spaceKey string, title string, limit int) ([]Page, error) {
+func (c *Client) listPagesV1(ctx context.Context, spaceKey string, title string, limit int) ([]Page, error) {
 	q := url.Values{
 		"type":     {"page"},
 		"spaceKey": {spaceKey},
@@ -97,93 +180,55 @@ func (c *Client) listPagesV1(spaceKey string, title string, limit int) ([]Page,
 	if title != "" {
 		q.Set("title", title)
 	}
-	if limit > 0 {
-		q.Set("limit", strconv.Itoa(limit))
-	}
 
-	data, err := c.getV1("content", q)
+	results, err := PaginateV1[V1Content](ctx, c, "content", q, 0, limit).All()
 	if err != nil {
 		return nil, err
 	}
-
-	var result V1PageResults
-	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, fmt.Errorf("parsing v1 pages: %w", err)
-	}
-
-	pages := make([]Page, len(result.Results))
-	for i := range result.Results {
-		pages[i] = *v1ToPage(&result.Results[i])
-	}
-	return pages, nil
+	return v1ToPages(results), nil
 }
 
 // GetChildren retrieves direct children of a page (v2 Cloud, v1 Server/DC).
 func (c *Client) GetChildren(pageID string, limit int) ([]Page, error) {
-	if c.IsCloud() {
-		return c.getChildrenV2(pageID, limit)
-	}
-	return c.getChildrenV1(pageID, limit)
+	return c.GetChildrenContext(context.Background(), pageID, limit)
 }
 
-func (c *Client) getChildrenV2(pageID string, limit int) ([]Page, error) {
-	q := url.Values{}
-	if limit > 0 {
-		q.Set("limit", strconv.Itoa(limit))
-	}
-
-	data, err := c.getV2("pages/"+pageID+"/children", q)
-	if err != nil {
-		return nil, err
+// GetChildrenContext is like GetChildren but honors ctx for cancellation and deadlines.
+func (c *Client) GetChildrenContext(ctx context.Context, pageID string, limit int) ([]Page, error) {
+	if c.Has(CapV2) {
+		return c.getChildrenV2(ctx, pageID, limit)
 	}
+	return c.getChildrenV1(ctx, pageID, limit)
+}
 
-	var page CursorPage[Page]
-	if err := json.Unmarshal(data, &page); err != nil {
-		return nil, fmt.Errorf("parsing children: %w", err)
-	}
-	return page.Results, nil
+func (c *Client) getChildrenV2(ctx context.Context, pageID string, limit int) ([]Page, error) {
+	return PaginateV2[Page](ctx, c, "pages/"+pageID+"/children", nil, limit).All()
 }
 
-func (c *Client) getChildrenV1(pageID string, limit int) ([]Page, error) {
+func (c *Client) getChildrenV1(ctx context.Context, pageID string, limit int) ([]Page, error) {
 	q := url.Values{"expand": {"version"}}
-	if limit > 0 {
-		q.Set("limit", strconv.Itoa(limit))
-	}
 
-	data, err := c.getV1("content/"+pageID+"/child/page", q)
+	results, err := PaginateV1[V1Content](ctx, c, "content/"+pageID+"/child/page", q, 0, limit).All()
 	if err != nil {
 		return nil, err
 	}
-
-	var result V1PageResults
-	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, fmt.Errorf("parsing v1 children: %w", err)
-	}
-
-	pages := make([]Page, len(result.Results))
-	for i := range result.Results {
-		pages[i] = *v1ToPage(&result.Results[i])
-	}
-	return pages, nil
+	return v1ToPages(results), nil
 }
 
 // GetAncestors retrieves the breadcrumb chain for a page (v2 Cloud only, v1 via expand).
 func (c *Client) GetAncestors(pageID string) ([]Ancestor, error) {
-	if c.IsCloud() {
-		data, err := c.getV2("pages/"+pageID+"/ancestors", nil)
-		if err != nil {
-			return nil, err
-		}
-		var result CursorPage[Ancestor]
-		if err := json.Unmarshal(data, &result); err != nil {
-			return nil, fmt.Errorf("parsing ancestors: %w", err)
-		}
-		return result.Results, nil
+	return c.GetAncestorsContext(context.Background(), pageID)
+}
+
+// GetAncestorsContext is like GetAncestors but honors ctx for cancellation and deadlines.
+func (c *Client) GetAncestorsContext(ctx context.Context, pageID string) ([]Ancestor, error) {
+	if c.Has(CapV2) {
+		return PaginateV2[Ancestor](ctx, c, "pages/"+pageID+"/ancestors", nil, 0).All()
 	}
 
 	// V1: get page with ancestors expanded.
 	q := url.Values{"expand": {"ancestors"}}
-	data, err := c.getV1("content/"+pageID, q)
+	data, err := c.getV1(ctx, "content/"+pageID, q)
 	if err != nil {
 		return nil, err
 	}
@@ -200,21 +245,47 @@ func (c *Client) GetAncestors(pageID string) ([]Ancestor, error) {
 
 // CreatePage creates a new page (v2 Cloud, v1 Server/DC).
 func (c *Client) CreatePage(spaceKey, title, body, parentID string) (*Page, error) {
-	if c.IsCloud() {
-		return c.createPageV2(spaceKey, title, body, parentID)
+	return c.CreatePageContext(context.Background(), spaceKey, title, body, parentID)
+}
+
+// CreatePageContext is like CreatePage but honors ctx for cancellation and deadlines.
+func (c *Client) CreatePageContext(ctx context.Context, spaceKey, title, body, parentID string) (*Page, error) {
+	return c.CreatePageWithOptionsContext(ctx, spaceKey, title, body, parentID, CreateOptions{})
+}
+
+// CreateOptions tunes page creation.
+type CreateOptions struct {
+	// Draft creates an unpublished draft that only its author sees until
+	// PublishPage makes it current.
+	Draft bool
+}
+
+// CreatePageWithOptions is like CreatePage but can create a draft instead of publishing.
+func (c *Client) CreatePageWithOptions(spaceKey, title, body, parentID string, opts CreateOptions) (*Page, error) {
+	return c.CreatePageWithOptionsContext(context.Background(), spaceKey, title, body, parentID, opts)
+}
+
+// CreatePageWithOptionsContext is like CreatePageWithOptions but honors ctx for cancellation and deadlines.
+func (c *Client) CreatePageWithOptionsContext(ctx context.Context, spaceKey, title, body, parentID string, opts CreateOptions) (*Page, error) {
+	status := "current"
+	if opts.Draft {
+		status = "draft"
+	}
+	if c.Has(CapV2) {
+		return c.createPageV2(ctx, spaceKey, title, body, parentID, status)
 	}
-	return c.createPageV1(spaceKey, title, body, parentID)
+	return c.createPageV1(ctx, spaceKey, title, body, parentID, status)
 }
 
-func (c *Client) createPageV2(spaceKey, title, body, parentID string) (*Page, error) {
-	spaceID, err := c.ResolveSpaceKey(spaceKey)
+func (c *Client) createPageV2(ctx context.Context, spaceKey, title, body, parentID, status string) (*Page, error) {
+	spaceID, err := c.ResolveSpaceKeyContext(ctx, spaceKey)
 	if err != nil {
 		return nil, err
 	}
 
 	req := CreatePageRequest{
 		SpaceID:  spaceID,
-		Status:   "current",
+		Status:   status,
 		Title:    title,
 		ParentID: parentID,
 	}
@@ -225,7 +296,7 @@ func (c *Client) createPageV2(spaceKey, title, body, parentID string) (*Page, er
 		}
 	}
 
-	data, err := c.postV2("pages", req)
+	data, err := c.postV2(ctx, "pages", req)
 	if err != nil {
 		return nil, err
 	}
@@ -237,11 +308,12 @@ func (c *Client) createPageV2(spaceKey, title, body, parentID string) (*Page, er
 	return &page, nil
 }
 
-func (c *Client) createPageV1(spaceKey, title, body, parentID string) (*Page, error) {
+func (c *Client) createPageV1(ctx context.Context, spaceKey, title, body, parentID, status string) (*Page, error) {
 	v1Req := map[string]interface{}{
-		"type":  "page",
-		"title": title,
-		"space": map[string]string{"key": spaceKey},
+		"type":   "page",
+		"status": status,
+		"title":  title,
+		"space":  map[string]string{"key": spaceKey},
 	}
 	if body != "" {
 		v1Req["body"] = map[string]interface{}{
@@ -255,7 +327,7 @@ func (c *Client) createPageV1(spaceKey, title, body, parentID string) (*Page, er
 		v1Req["ancestors"] = []map[string]string{{"id": parentID}}
 	}
 
-	data, err := c.postV1("content", v1Req)
+	data, err := c.postV1(ctx, "content", v1Req)
 	if err != nil {
 		return nil, err
 	}
@@ -270,8 +342,49 @@ func (c *Client) createPageV1(spaceKey, title, body, parentID string) (*Page, er
 // UpdatePage updates an existing page (v2 Cloud, v1 Server/DC).
 // Automatically handles version increment.
 func (c *Client) UpdatePage(pageID, title, body, message string) (*Page, error) {
-	// First, get current version.
-	current, err := c.GetPage(pageID, false)
+	return c.UpdatePageContext(context.Background(), pageID, title, body, message)
+}
+
+// UpdatePageContext is like UpdatePage but honors ctx for cancellation and deadlines.
+func (c *Client) UpdatePageContext(ctx context.Context, pageID, title, body, message string) (*Page, error) {
+	return c.UpdatePageWithOptionsContext(ctx, pageID, title, body, message, UpdateOptions{})
+}
+
+// UpdateOptions adds optimistic concurrency to a page update.
+type UpdateOptions struct {
+	// ExpectVersion is the version the caller's edit is based on. When the page
+	// has moved past it the update fails with a *VersionMismatchError. 0 skips the check.
+	ExpectVersion int
+	// Merge three-way merges body with the changes made since ExpectVersion
+	// instead of failing, and writes only when the merge is clean. It needs
+	// ExpectVersion and a body.
+	Merge bool
+	// Draft saves the edit as an unpublished draft for review instead of a
+	// new version; PublishPage makes it live. It can't be combined with
+	// ExpectVersion or Merge.
+	Draft bool
+}
+
+// UpdatePageWithOptions is like UpdatePage but refuses to overwrite changes the caller hasn't seen.
+func (c *Client) UpdatePageWithOptions(pageID, title, body, message string, opts UpdateOptions) (*Page, error) {
+	return c.UpdatePageWithOptionsContext(context.Background(), pageID, title, body, message, opts)
+}
+
+// UpdatePageWithOptionsContext is like UpdatePageWithOptions but honors ctx for cancellation and deadlines.
+func (c *Client) UpdatePageWithOptionsContext(ctx context.Context, pageID, title, body, message string, opts UpdateOptions) (*Page, error) {
+	if opts.Draft {
+		if opts.ExpectVersion > 0 || opts.Merge {
+			return nil, fmt.Errorf("confluence: draft updates don't take ExpectVersion or Merge")
+		}
+		return c.saveDraft(ctx, pageID, title, body, message)
+	}
+	if opts.Merge && (opts.ExpectVersion <= 0 || body == "") {
+		return nil, fmt.Errorf("confluence: Merge needs ExpectVersion and a body to merge")
+	}
+	merging := opts.Merge
+
+	// First, get current version (and body, if we may need to merge into it).
+	current, err := c.GetPageContext(ctx, pageID, merging)
 	if err != nil {
 		return nil, fmt.Errorf("reading current version: %w", err)
 	}
@@ -281,17 +394,62 @@ func (c *Client) UpdatePage(pageID, title, body, message string) (*Page, error)
 		currentVersion = current.Version.Number
 	}
 
+	if opts.ExpectVersion > 0 && currentVersion != opts.ExpectVersion {
+		if !merging {
+			return nil, &VersionMismatchError{PageID: pageID, Expected: opts.ExpectVersion, Current: currentVersion}
+		}
+		body, err = c.mergeIntoCurrent(ctx, current, currentVersion, opts.ExpectVersion, body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if title == "" {
 		title = current.Title
 	}
 
-	if c.IsCloud() {
-		return c.updatePageV2(pageID, title, body, message, currentVersion+1)
+	defer c.evictPage(pageID)
+	if c.Has(CapV2) {
+		return c.updatePageV2(ctx, pageID, title, body, message, currentVersion+1)
 	}
-	return c.updatePageV1(pageID, title, body, message, currentVersion+1)
+	return c.updatePageV1(ctx, pageID, title, body, message, currentVersion+1)
+}
+
+// mergeIntoCurrent three-way merges body (edited from baseVersion) with current's body.
+func (c *Client) mergeIntoCurrent(ctx context.Context, current *Page, currentVersion, baseVersion int, body string) (string, error) {
+	base, err := c.getPageAtVersion(ctx, current.ID, baseVersion)
+	if err != nil {
+		return "", fmt.Errorf("reading base version %d: %w", baseVersion, err)
+	}
+
+	result, err := MergeStorage(storageValue(base), body, storageValue(current))
+	if err != nil {
+		return "", err
+	}
+	if result.Conflicts > 0 {
+		return "", &MergeConflictError{
+			PageID:    current.ID,
+			Base:      baseVersion,
+			Current:   currentVersion,
+			Conflicts: result.Conflicts,
+		}
+	}
+	return result.Body, nil
+}
+
+// getPageAtVersion fetches a historical version of a page with its storage body.
+func (c *Client) getPageAtVersion(ctx context.Context, pageID string, version int) (*Page, error) {
+	return c.GetPageVersionContext(ctx, pageID, version, true)
 }
 
-func (c *Client) updatePageV2(pageID, title, body, message string, versionNumber int) (*Page, error) {
+func storageValue(p *Page) string {
+	if p == nil || p.Body == nil || p.Body.Storage == nil {
+		return ""
+	}
+	return p.Body.Storage.Value
+}
+
+func (c *Client) updatePageV2(ctx context.Context, pageID, title, body, message string, versionNumber int) (*Page, error) {
 	req := UpdatePageRequest{
 		ID:     pageID,
 		Status: "current",
@@ -308,7 +466,7 @@ func (c *Client) updatePageV2(pageID, title, body, message string, versionNumber
 		}
 	}
 
-	data, err := c.putV2("pages/"+pageID, req)
+	data, err := c.putV2(ctx, "pages/"+pageID, req)
 	if err != nil {
 		return nil, err
 	}
@@ -320,7 +478,7 @@ func (c *Client) updatePageV2(pageID, title, body, message string, versionNumber
 	return &page, nil
 }
 
-func (c *Client) updatePageV1(pageID, title, body, message string, versionNumber int) (*Page, error) {
+func (c *Client) updatePageV1(ctx context.Context, pageID, title, body, message string, versionNumber int) (*Page, error) {
 	v1Req := map[string]interface{}{
 		"type":  "page",
 		"title": title,
@@ -339,7 +497,7 @@ func (c *Client) updatePageV1(pageID, title, body, message string, versionNumber
 	}
 
 	fullURL := c.v1URL("content/" + pageID)
-	data, err := c.request(http.MethodPut, fullURL, nil, v1Req)
+	data, err := c.request(ctx, http.MethodPut, fullURL, nil, v1Req)
 	if err != nil {
 		return nil, err
 	}
@@ -353,19 +511,25 @@ func (c *Client) updatePageV1(pageID, title, body, message string, versionNumber
 
 // DeletePage trashes a page (v2 Cloud, v1 Server/DC).
 func (c *Client) DeletePage(pageID string) error {
-	if c.IsCloud() {
-		_, err := c.deleteV2("pages/" + pageID)
+	return c.DeletePageContext(context.Background(), pageID)
+}
+
+// DeletePageContext is like DeletePage but honors ctx for cancellation and deadlines.
+func (c *Client) DeletePageContext(ctx context.Context, pageID string) error {
+	defer c.evictPage(pageID)
+	if c.Has(CapV2) {
+		_, err := c.deleteV2(ctx, "pages/"+pageID)
 		return err
 	}
 	fullURL := c.v1URL("content/" + pageID)
-	_, err := c.request(http.MethodDelete, fullURL, nil, nil)
+	_, err := c.request(ctx, http.MethodDelete, fullURL, nil, nil)
 	return err
 }
 
 // postV1 performs a POST request to the v1 API.
-func (c *Client) postV1(path string, body interface{}) ([]byte, error) {
+func (c *Client) postV1(ctx context.Context, path string, body interface{}) ([]byte, error) {
 	fullURL := c.v1URL(path)
-	return c.request(http.MethodPost, fullURL, nil, body)
+	return c.request(ctx, http.MethodPost, fullURL, nil, body)
 }
 
 // --- V1 to V2 type conversion ---
@@ -379,22 +543,16 @@ func v1ToPage(v1 *V1Content) *Page {
 
 	if v1.Space != nil {
 		p.SpaceID = strconv.Itoa(v1.Space.ID)
+		p.SpaceKey = v1.Space.Key
+	}
+
+	if n := len(v1.Ancestors); n > 0 {
+		p.ParentID = v1.Ancestors[n-1].ID
+		p.ParentType = "page"
 	}
 
 	if v1.Version != nil {
-		p.Version = &Version{
-			Number:  v1.Version.Number,
-			Message: v1.Version.Message,
-		}
-		if v1.Version.When != "" {
-			p.Version.CreatedAt = v1.Version.When
-		}
-		if v1.Version.By != nil {
-			p.Version.AuthorID = v1.Version.By.AccountID
-			if p.Version.AuthorID == "" {
-				p.Version.AuthorID = v1.Version.By.Username
-			}
-		}
+		p.Version = v1ToVersion(v1.Version)
 	}
 
 	if v1.Body != nil && v1.Body.Storage != nil {
@@ -412,3 +570,11 @@ func v1ToPage(v1 *V1Content) *Page {
 
 	return p
 }
+
+func v1ToPages(results []V1Content) []Page {
+	pages := make([]Page, len(results))
+	for i := range results {
+		pages[i] = *v1ToPage(&results[i])
+	}
+	return pages
+}
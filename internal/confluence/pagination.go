@@ -0,0 +1,295 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// maxPageSize is the largest page size accepted by the v2 list endpoints.
+const maxPageSize = 250
+
+// CursorIterator walks a v2 cursor-paginated collection, following
+// _links.next until the collection is exhausted or the limit is reached.
+//
+//	it := PaginateV2[Page](ctx, c, "pages", q, 0)
+//	for it.Next() {
+//		p := it.Value()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type CursorIterator[T any] struct {
+	ctx     context.Context
+	client  *Client
+	nextURL string     // full URL of the next page; empty when exhausted
+	query   url.Values // query for the first page only (cursors embed their own)
+	limit   int        // max items to yield; <= 0 means no limit
+
+	buf     []T
+	current T
+	yielded int
+	started bool
+	err     error
+}
+
+// PaginateV2 returns an iterator over a v2 list endpoint (path relative to /api/v2).
+// limit caps the total number of items yielded; <= 0 follows every cursor.
+func PaginateV2[T any](ctx context.Context, c *Client, path string, query url.Values, limit int) *CursorIterator[T] {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	pageSize := maxPageSize
+	if limit > 0 && limit < pageSize {
+		pageSize = limit
+	}
+	q.Set("limit", strconv.Itoa(pageSize))
+
+	return &CursorIterator[T]{
+		ctx:     ctx,
+		client:  c,
+		nextURL: c.v2URL(path),
+		query:   q,
+		limit:   limit,
+	}
+}
+
+// Next advances to the next item, fetching the following page when needed.
+// It returns false when the collection is exhausted, the limit is reached, or an error occurred.
+func (it *CursorIterator[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.limit > 0 && it.yielded >= it.limit {
+		return false
+	}
+
+	for len(it.buf) == 0 {
+		if it.started && it.nextURL == "" {
+			return false
+		}
+		if err := it.fetch(); err != nil {
+			it.err = err
+			return false
+		}
+	}
+
+	it.current = it.buf[0]
+	it.buf = it.buf[1:]
+	it.yielded++
+	return true
+}
+
+// Value returns the current item. Only valid after Next returned true.
+func (it *CursorIterator[T]) Value() T {
+	return it.current
+}
+
+// Err returns the first error encountered while paginating.
+func (it *CursorIterator[T]) Err() error {
+	return it.err
+}
+
+// All drains the iterator into a slice.
+func (it *CursorIterator[T]) All() ([]T, error) {
+	var items []T
+	for it.Next() {
+		items = append(items, it.Value())
+	}
+	return items, it.Err()
+}
+
+func (it *CursorIterator[T]) fetch() error {
+	var query url.Values
+	if !it.started {
+		query = it.query
+	}
+	it.started = true
+
+	data, err := it.client.request(it.ctx, http.MethodGet, it.nextURL, query, nil)
+	if err != nil {
+		return err
+	}
+
+	var page CursorPage[T]
+	if err := json.Unmarshal(data, &page); err != nil {
+		return fmt.Errorf("parsing paginated response: %w", err)
+	}
+
+	it.buf = page.Results
+	it.nextURL = ""
+	if page.HasMore() {
+		next, err := it.client.resolveLink(page.Links.Next)
+		if err != nil {
+			return err
+		}
+		it.nextURL = next
+	}
+	return nil
+}
+
+// resolveLink turns a _links.next value into an absolute URL.
+// Cloud returns links relative to the site root (e.g. "/wiki/api/v2/pages?cursor=...").
+// Through the OAuth gateway those paths live under the gateway prefix instead.
+func (c *Client) resolveLink(link string) (string, error) {
+	if c.apiRoot != "" && strings.HasPrefix(link, "/") {
+		return c.apiRoot + link, nil
+	}
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return "", fmt.Errorf("parsing base URL: %w", err)
+	}
+	ref, err := url.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("parsing pagination link %q: %w", link, err)
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// maxV1PageSize is the page size requested from v1 start/limit endpoints.
+// Servers may clamp it lower; the iterator advances by the size actually returned.
+const maxV1PageSize = 100
+
+// OffsetIterator walks a v1 start/limit-paginated collection, following
+// _links.next (or start+size when no link is present) until the collection
+// is exhausted or the limit is reached.
+type OffsetIterator[T any] struct {
+	ctx     context.Context
+	client  *Client
+	path    string     // path relative to /rest/api
+	query   url.Values // base query without start/limit
+	nextURL string     // full URL from _links.next; takes precedence over start
+	start   int        // offset of the next page to request
+	limit   int        // max items to yield; <= 0 means no limit
+
+	buf       []T
+	current   T
+	yielded   int
+	totalSize int
+	done      bool
+	err       error
+}
+
+// PaginateV1 returns an iterator over a v1 list endpoint (path relative to /rest/api),
+// beginning at offset start. limit caps the total number of items yielded; <= 0 reads everything.
+func PaginateV1[T any](ctx context.Context, c *Client, path string, query url.Values, start, limit int) *OffsetIterator[T] {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	if start < 0 {
+		start = 0
+	}
+	return &OffsetIterator[T]{
+		ctx:    ctx,
+		client: c,
+		path:   path,
+		query:  q,
+		start:  start,
+		limit:  limit,
+	}
+}
+
+// Next advances to the next item, fetching the following page when needed.
+func (it *OffsetIterator[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.limit > 0 && it.yielded >= it.limit {
+		return false
+	}
+
+	for len(it.buf) == 0 {
+		if it.done {
+			return false
+		}
+		if err := it.fetch(); err != nil {
+			it.err = err
+			return false
+		}
+	}
+
+	it.current = it.buf[0]
+	it.buf = it.buf[1:]
+	it.yielded++
+	return true
+}
+
+// Value returns the current item. Only valid after Next returned true.
+func (it *OffsetIterator[T]) Value() T {
+	return it.current
+}
+
+// Err returns the first error encountered while paginating.
+func (it *OffsetIterator[T]) Err() error {
+	return it.err
+}
+
+// TotalSize returns the totalSize reported by the server, or 0 when the endpoint doesn't report it.
+func (it *OffsetIterator[T]) TotalSize() int {
+	return it.totalSize
+}
+
+// All drains the iterator into a slice.
+func (it *OffsetIterator[T]) All() ([]T, error) {
+	var items []T
+	for it.Next() {
+		items = append(items, it.Value())
+	}
+	return items, it.Err()
+}
+
+func (it *OffsetIterator[T]) fetch() error {
+	pageSize := maxV1PageSize
+	if it.limit > 0 && it.limit-it.yielded < pageSize {
+		pageSize = it.limit - it.yielded
+	}
+
+	var data []byte
+	var err error
+	if it.nextURL != "" {
+		data, err = it.client.request(it.ctx, http.MethodGet, it.nextURL, nil, nil)
+	} else {
+		q := url.Values{}
+		for k, v := range it.query {
+			q[k] = v
+		}
+		q.Set("start", strconv.Itoa(it.start))
+		q.Set("limit", strconv.Itoa(pageSize))
+		data, err = it.client.getV1(it.ctx, it.path, q)
+	}
+	if err != nil {
+		return err
+	}
+
+	var page OffsetPage[T]
+	if err := json.Unmarshal(data, &page); err != nil {
+		return fmt.Errorf("parsing paginated response: %w", err)
+	}
+
+	if page.TotalSize > it.totalSize {
+		it.totalSize = page.TotalSize
+	}
+	it.buf = page.Results
+
+	size := len(page.Results)
+	if page.Start > it.start {
+		it.start = page.Start
+	}
+	it.start += size
+	it.nextURL = ""
+	switch {
+	case size == 0:
+		it.done = true
+	case page.HasMore():
+		it.nextURL = it.client.baseURL + page.Links.Next
+	case it.totalSize > it.start:
+		// No next link, but the server says there's more — continue by offset.
+	default:
+		it.done = true
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+)
+
+// Capability names an API feature an instance was found to support.
+type Capability string
+
+const (
+	CapV2                Capability = "v2"                 // REST v2 (/api/v2) answers
+	CapV1                Capability = "v1"                 // REST v1 (/rest/api) and CQL search answer
+	CapContentProperties Capability = "content_properties" // content properties can be read and written
+)
+
+// InstanceInfo is what DetectInstance learned about an instance.
+type InstanceInfo struct {
+	Type         InstanceType
+	Version      string // product version, e.g. "8.5.4"; empty on Cloud, which is unversioned
+	BuildNumber  string // build number (Server/DC) or commit hash (Cloud)
+	Edition      string // reported edition, if any
+	Capabilities []Capability
+	// Undetermined lists capabilities the probe could not decide either
+	// way, e.g. content properties on an instance with no visible page.
+	Undetermined []Capability
+}
+
+// Has reports whether info lists capability.
+func (info InstanceInfo) Has(capability Capability) bool {
+	return slices.Contains(info.Capabilities, capability)
+}
+
+// Complete reports whether every capability was decided. An incomplete
+// result is fine to use for one run but shouldn't be recorded.
+func (info InstanceInfo) Complete() bool {
+	return len(info.Undetermined) == 0
+}
+
+// AssumedCapabilities returns the detected capabilities plus the
+// undetermined ones, which a client should try rather than rule out.
+func (info InstanceInfo) AssumedCapabilities() []Capability {
+	return append(slices.Clone(info.Capabilities), info.Undetermined...)
+}
+
+// Has reports whether the instance supports capability. Clients built
+// without detected capabilities fall back to the instance type: v2 is
+// assumed on Cloud only, everything else everywhere.
+func (c *Client) Has(capability Capability) bool {
+	if c.capabilities == nil {
+		if capability == CapV2 {
+			return c.IsCloud()
+		}
+		return true
+	}
+	return slices.Contains(c.capabilities, capability)
+}
+
+// DetectInstance probes the instance instead of guessing from its URL. It
+// asks the v2 spaces endpoint, the v1 space list, the properties of the
+// first visible page and systemInfo, and on Server/DC the application links
+// manifest for the product version. Only endpoints that answer 404, 405 or
+// 501 count as absent; any other failure is returned rather than recorded.
+func (c *Client) DetectInstance() (*InstanceInfo, error) {
+	return c.DetectInstanceContext(context.Background())
+}
+
+// DetectInstanceContext is like DetectInstance but honors ctx for cancellation and deadlines.
+func (c *Client) DetectInstanceContext(ctx context.Context) (*InstanceInfo, error) {
+	info := &InstanceInfo{Type: InstanceServer}
+
+	hasV2, err := c.probe(ctx, c.v2URL("spaces"))
+	if err != nil {
+		return nil, err
+	}
+	hasV1, err := c.probe(ctx, c.v1URL("space"))
+	if err != nil {
+		return nil, err
+	}
+	if !hasV2 && !hasV1 {
+		return nil, fmt.Errorf("confluence: neither /api/v2 nor /rest/api answered at %s; check the instance URL (Cloud URLs end in /wiki)", c.baseURL)
+	}
+	if hasV2 {
+		info.Type = InstanceCloud
+		info.Capabilities = append(info.Capabilities, CapV2)
+	}
+	if hasV1 {
+		info.Capabilities = append(info.Capabilities, CapV1)
+	}
+	hasProps, known, err := c.probeContentProperties(ctx, hasV2)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case !known:
+		info.Undetermined = append(info.Undetermined, CapContentProperties)
+	case hasProps:
+		info.Capabilities = append(info.Capabilities, CapContentProperties)
+	}
+
+	data, err := c.getV1(ctx, "settings/systemInfo", nil)
+	if err != nil && !endpointMissing(err) {
+		return nil, err
+	}
+	if err == nil {
+		var sys struct {
+			CloudID    string `json:"cloudId"`
+			CommitHash string `json:"commitHash"`
+			Edition    string `json:"edition"`
+		}
+		if json.Unmarshal(data, &sys) == nil {
+			if sys.CloudID != "" {
+				info.Type = InstanceCloud
+			}
+			info.BuildNumber = sys.CommitHash
+			info.Edition = sys.Edition
+		}
+	}
+
+	if info.Type == InstanceServer {
+		data, err := c.request(ctx, http.MethodGet, c.baseURL+"/rest/applinks/1.0/manifest", nil, nil)
+		if err != nil && !endpointMissing(err) {
+			return nil, err
+		}
+		if err == nil {
+			var manifest struct {
+				Version     string `json:"version"`
+				BuildNumber string `json:"buildNumber"`
+			}
+			if json.Unmarshal(data, &manifest) == nil {
+				info.Version = manifest.Version
+				if manifest.BuildNumber != "" {
+					info.BuildNumber = manifest.BuildNumber
+				}
+			}
+		}
+	}
+	return info, nil
+}
+
+// probe reports whether a GET of fullURL succeeds. Only a missing endpoint
+// (404, 405, 501) is a "no"; bad credentials, rate limiting, server and
+// network errors are returned, since they say nothing about which APIs exist.
+func (c *Client) probe(ctx context.Context, fullURL string) (bool, error) {
+	_, err := c.request(ctx, http.MethodGet, fullURL, url.Values{"limit": {"1"}}, nil)
+	if err == nil {
+		return true, nil
+	}
+	if endpointMissing(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// probeContentProperties reports whether the properties of the first
+// visible page can be listed. When no page can be listed there is nothing
+// to ask, and known is false.
+func (c *Client) probeContentProperties(ctx context.Context, v2 bool) (supported, known bool, err error) {
+	var pageID string
+	if v2 {
+		data, err := c.getV2(ctx, "pages", url.Values{"limit": {"1"}})
+		if err != nil {
+			if endpointMissing(err) {
+				return false, false, nil
+			}
+			return false, false, err
+		}
+		var pages CursorPage[Page]
+		if err := json.Unmarshal(data, &pages); err != nil {
+			return false, false, fmt.Errorf("parsing pages: %w", err)
+		}
+		if len(pages.Results) == 0 {
+			return false, false, nil
+		}
+		pageID = pages.Results[0].ID
+	} else {
+		data, err := c.getV1(ctx, "content", url.Values{"type": {"page"}, "limit": {"1"}})
+		if err != nil {
+			if endpointMissing(err) {
+				return false, false, nil
+			}
+			return false, false, err
+		}
+		var pages OffsetPage[V1Content]
+		if err := json.Unmarshal(data, &pages); err != nil {
+			return false, false, fmt.Errorf("parsing v1 pages: %w", err)
+		}
+		if len(pages.Results) == 0 {
+			return false, false, nil
+		}
+		pageID = pages.Results[0].ID
+	}
+
+	fullURL := c.v1URL("content", pageID, "property")
+	if v2 {
+		fullURL = c.v2URL("pages", pageID, "properties")
+	}
+	supported, err = c.probe(ctx, fullURL)
+	return supported, err == nil, err
+}
@@ -0,0 +1,193 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ContentProperty is a JSON value stored on a page under a key, outside
+// the body. Confluence versions properties separately from the page.
+type ContentProperty struct {
+	ID      string          `json:"id,omitempty"`
+	Key     string          `json:"key"`
+	Value   json.RawMessage `json:"value"`
+	Version *Version        `json:"version,omitempty"`
+}
+
+// V1ContentProperty is a content property in the v1 API.
+type V1ContentProperty struct {
+	ID      string          `json:"id,omitempty"`
+	Key     string          `json:"key"`
+	Value   json.RawMessage `json:"value"`
+	Version *V1Version      `json:"version,omitempty"`
+}
+
+// propertyUpdate is the body of property create and update calls; v2 and
+// v1 take the same shape.
+type propertyUpdate struct {
+	Key     string          `json:"key"`
+	Value   json.RawMessage `json:"value"`
+	Version *VersionUpdate  `json:"version,omitempty"`
+}
+
+// ListPageProperties returns all properties of a page (v2 Cloud, v1 Server/DC).
+func (c *Client) ListPageProperties(pageID string) ([]ContentProperty, error) {
+	return c.ListPagePropertiesContext(context.Background(), pageID)
+}
+
+// ListPagePropertiesContext is like ListPageProperties but honors ctx for cancellation and deadlines.
+func (c *Client) ListPagePropertiesContext(ctx context.Context, pageID string) ([]ContentProperty, error) {
+	if err := c.requireContentProperties(); err != nil {
+		return nil, err
+	}
+	if c.Has(CapV2) {
+		return PaginateV2[ContentProperty](ctx, c, "pages/"+pageID+"/properties", nil, 0).All()
+	}
+
+	q := url.Values{"expand": {"version"}}
+	results, err := PaginateV1[V1ContentProperty](ctx, c, "content/"+pageID+"/property", q, 0, 0).All()
+	if err != nil {
+		return nil, err
+	}
+	props := make([]ContentProperty, len(results))
+	for i := range results {
+		props[i] = *v1ToProperty(&results[i])
+	}
+	return props, nil
+}
+
+// GetPageProperty returns the page property stored under key. A missing
+// key is an error matching ErrNotFound (v2 Cloud, v1 Server/DC).
+func (c *Client) GetPageProperty(pageID, key string) (*ContentProperty, error) {
+	return c.GetPagePropertyContext(context.Background(), pageID, key)
+}
+
+// GetPagePropertyContext is like GetPageProperty but honors ctx for cancellation and deadlines.
+func (c *Client) GetPagePropertyContext(ctx context.Context, pageID, key string) (*ContentProperty, error) {
+	if err := c.requireContentProperties(); err != nil {
+		return nil, err
+	}
+	if c.Has(CapV2) {
+		// v2 addresses properties by ID; look the key up instead.
+		props, err := PaginateV2[ContentProperty](ctx, c, "pages/"+pageID+"/properties", url.Values{"key": {key}}, 1).All()
+		if err != nil {
+			return nil, err
+		}
+		if len(props) == 0 {
+			return nil, fmt.Errorf("%w: page %s has no property %q", ErrNotFound, pageID, key)
+		}
+		return &props[0], nil
+	}
+
+	data, err := c.getV1(ctx, "content/"+pageID+"/property/"+url.PathEscape(key), url.Values{"expand": {"version"}})
+	if err != nil {
+		return nil, err
+	}
+	var v1 V1ContentProperty
+	if err := json.Unmarshal(data, &v1); err != nil {
+		return nil, fmt.Errorf("parsing v1 property: %w", err)
+	}
+	return v1ToProperty(&v1), nil
+}
+
+// SetPageProperty stores value, which must be valid JSON, under key:
+// it creates the property, or updates it as the next version of the one
+// already there (v2 Cloud, v1 Server/DC).
+func (c *Client) SetPageProperty(pageID, key string, value json.RawMessage) (*ContentProperty, error) {
+	return c.SetPagePropertyContext(context.Background(), pageID, key, value)
+}
+
+// SetPagePropertyContext is like SetPageProperty but honors ctx for cancellation and deadlines.
+func (c *Client) SetPagePropertyContext(ctx context.Context, pageID, key string, value json.RawMessage) (*ContentProperty, error) {
+	if err := c.requireContentProperties(); err != nil {
+		return nil, err
+	}
+	if !json.Valid(value) {
+		return nil, fmt.Errorf("confluence: value of property %q is not valid JSON", key)
+	}
+
+	current, err := c.GetPagePropertyContext(ctx, pageID, key)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("reading property %q: %w", key, err)
+	}
+	req := propertyUpdate{Key: key, Value: value}
+	if current != nil {
+		version := 0
+		if current.Version != nil {
+			version = current.Version.Number
+		}
+		req.Version = &VersionUpdate{Number: version + 1}
+	}
+
+	var data []byte
+	switch {
+	case c.Has(CapV2) && current == nil:
+		data, err = c.postV2(ctx, "pages/"+pageID+"/properties", req)
+	case c.Has(CapV2):
+		data, err = c.putV2(ctx, "pages/"+pageID+"/properties/"+current.ID, req)
+	case current == nil:
+		data, err = c.postV1(ctx, "content/"+pageID+"/property", req)
+	default:
+		data, err = c.request(ctx, http.MethodPut, c.v1URL("content", pageID, "property", url.PathEscape(key)), nil, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Has(CapV2) {
+		var prop ContentProperty
+		if err := json.Unmarshal(data, &prop); err != nil {
+			return nil, fmt.Errorf("parsing property: %w", err)
+		}
+		return &prop, nil
+	}
+	var v1 V1ContentProperty
+	if err := json.Unmarshal(data, &v1); err != nil {
+		return nil, fmt.Errorf("parsing v1 property: %w", err)
+	}
+	return v1ToProperty(&v1), nil
+}
+
+// DeletePageProperty removes the page property stored under key (v2 Cloud,
+// v1 Server/DC).
+func (c *Client) DeletePageProperty(pageID, key string) error {
+	return c.DeletePagePropertyContext(context.Background(), pageID, key)
+}
+
+// DeletePagePropertyContext is like DeletePageProperty but honors ctx for cancellation and deadlines.
+func (c *Client) DeletePagePropertyContext(ctx context.Context, pageID, key string) error {
+	if err := c.requireContentProperties(); err != nil {
+		return err
+	}
+	if c.Has(CapV2) {
+		prop, err := c.GetPagePropertyContext(ctx, pageID, key)
+		if err != nil {
+			return err
+		}
+		_, err = c.deleteV2(ctx, "pages/"+pageID+"/properties/"+prop.ID)
+		return err
+	}
+	_, err := c.request(ctx, http.MethodDelete, c.v1URL("content", pageID, "property", url.PathEscape(key)), nil, nil)
+	return err
+}
+
+// requireContentProperties fails when detection found the instance has no
+// content properties.
+func (c *Client) requireContentProperties() error {
+	if !c.Has(CapContentProperties) {
+		return fmt.Errorf("%w: content properties", ErrNotSupported)
+	}
+	return nil
+}
+
+func v1ToProperty(v1 *V1ContentProperty) *ContentProperty {
+	p := &ContentProperty{ID: v1.ID, Key: v1.Key, Value: v1.Value}
+	if v1.Version != nil {
+		p.Version = v1ToVersion(v1.Version)
+	}
+	return p
+}
@@ -0,0 +1,151 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimit configures the client-side token buckets that pace requests.
+// Every request draws from the overall bucket; writes (POST/PUT/PATCH/DELETE)
+// also draw from the write bucket. A rate <= 0 disables that bucket.
+type RateLimit struct {
+	RequestsPerSecond float64 // sustained rate for all requests
+	Burst             int     // requests allowed back-to-back before pacing kicks in
+	WritesPerSecond   float64 // sustained rate for writes, on top of the overall budget
+	WriteBurst        int
+}
+
+// DefaultRateLimit returns the limits used when Config.RateLimit is nil.
+// They sit comfortably under Cloud's per-user cost budget for typical read-heavy use.
+func DefaultRateLimit() RateLimit {
+	return RateLimit{
+		RequestsPerSecond: 10,
+		Burst:             20,
+		WritesPerSecond:   5,
+		WriteBurst:        10,
+	}
+}
+
+// tokenBucket is a goroutine-safe token bucket. Callers reserve a token and
+// wait for it; tokens may go negative so that waiters queue in arrival order.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // tokens added per second
+	burst  float64 // bucket capacity
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes one token and returns how long the caller must wait before using it.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel returns a token reserved by a caller that gave up waiting.
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.burst, b.tokens+1)
+}
+
+// wait blocks until a token is available or ctx is done.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+	d := b.reserve(time.Now())
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		b.cancel()
+		return fmt.Errorf("confluence: rate limit wait interrupted: %w", ctx.Err())
+	case <-t.C:
+		return nil
+	}
+}
+
+// rateLimiter pairs the overall and write buckets for one instance.
+type rateLimiter struct {
+	all    *tokenBucket
+	writes *tokenBucket
+}
+
+// wait paces a request according to its method.
+func (l *rateLimiter) wait(ctx context.Context, method string) error {
+	if l == nil {
+		return nil
+	}
+	if isWriteMethod(method) {
+		if err := l.writes.wait(ctx); err != nil {
+			return err
+		}
+	}
+	return l.all.wait(ctx)
+}
+
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+var (
+	sharedLimitersMu sync.Mutex
+	sharedLimiters   = map[string]*rateLimiter{}
+)
+
+// limiterFor returns the limiter shared by every Client pointed at baseURL
+// with the same limits, so separate clients in one process share one budget.
+func limiterFor(baseURL string, rl RateLimit) *rateLimiter {
+	if rl.RequestsPerSecond <= 0 && rl.WritesPerSecond <= 0 {
+		return nil
+	}
+	key := fmt.Sprintf("%s|%g|%d|%g|%d", baseURL, rl.RequestsPerSecond, rl.Burst, rl.WritesPerSecond, rl.WriteBurst)
+
+	sharedLimitersMu.Lock()
+	defer sharedLimitersMu.Unlock()
+	if l, ok := sharedLimiters[key]; ok {
+		return l
+	}
+	l := &rateLimiter{
+		all:    newTokenBucket(rl.RequestsPerSecond, rl.Burst),
+		writes: newTokenBucket(rl.WritesPerSecond, rl.WriteBurst),
+	}
+	sharedLimiters[key] = l
+	return l
+}
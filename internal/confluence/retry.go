@@ -0,0 +1,142 @@
+package confluence
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how request() retries 429 and 5xx responses and transport failures.
+type RetryPolicy struct {
+	MaxAttempts     int           // total attempts including the first; 1 disables retries
+	BaseDelay       time.Duration // delay before the first retry, doubled on each subsequent one
+	MaxDelay        time.Duration // upper bound for any single wait, including Retry-After
+	Jitter          float64       // fraction of the delay randomised in both directions (0-1)
+	HonorRetryAfter bool          // use Retry-After / X-RateLimit-Reset on 429 and 503 when present
+}
+
+// DefaultRetryPolicy returns the policy used when Config.RetryPolicy is nil.
+// Values follow Atlassian's guidance: exponential backoff with jitter, 30s cap, honor Retry-After.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:     4,
+		BaseDelay:       time.Second,
+		MaxDelay:        30 * time.Second,
+		Jitter:          0.2,
+		HonorRetryAfter: true,
+	}
+}
+
+// normalized clamps out-of-range values so a hand-built policy can't disable requests entirely.
+func (p RetryPolicy) normalized() RetryPolicy {
+	if p.MaxAttempts < 1 {
+		p.MaxAttempts = 1
+	}
+	if p.BaseDelay < 0 {
+		p.BaseDelay = 0
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultRetryPolicy().MaxDelay
+	}
+	if p.Jitter < 0 {
+		p.Jitter = 0
+	}
+	if p.Jitter > 1 {
+		p.Jitter = 1
+	}
+	return p
+}
+
+// backoff returns the jittered exponential delay before retry number attempt (0-based).
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 0; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if p.Jitter > 0 && d > 0 {
+		spread := float64(d) * p.Jitter
+		d += time.Duration(spread * (2*rand.Float64() - 1))
+	}
+	if d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d < 0 {
+		d = 0
+	}
+	return d
+}
+
+// delay picks the wait before the next retry. Server hints win over the
+// computed backoff when HonorRetryAfter is set; both are capped at MaxDelay.
+func (p RetryPolicy) delay(attempt int, resp *http.Response) (time.Duration, bool) {
+	if p.HonorRetryAfter && resp != nil {
+		if d, ok := retryAfter(resp.Header, time.Now()); ok {
+			if d > p.MaxDelay {
+				d = p.MaxDelay
+			}
+			return d, true
+		}
+	}
+	return p.backoff(attempt), false
+}
+
+// retryAfter reads Retry-After (seconds or HTTP date), falling back to
+// Cloud's X-RateLimit-Reset (ISO 8601 timestamp).
+func retryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	if v := strings.TrimSpace(h.Get("Retry-After")); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return nonNegative(t.Sub(now)), true
+		}
+	}
+	if v := strings.TrimSpace(h.Get("X-RateLimit-Reset")); v != "" {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return nonNegative(t.Sub(now)), true
+		}
+	}
+	return 0, false
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// RateLimitInfo holds the rate limit headers Confluence Cloud sends with responses.
+// Fields are empty when the server (e.g. Server/DC) doesn't send them.
+type RateLimitInfo struct {
+	Limit     string
+	Remaining string
+	Reset     string
+	NearLimit bool
+	Reason    string
+}
+
+func rateLimitFromHeader(h http.Header) RateLimitInfo {
+	return RateLimitInfo{
+		Limit:     h.Get("X-RateLimit-Limit"),
+		Remaining: h.Get("X-RateLimit-Remaining"),
+		Reset:     h.Get("X-RateLimit-Reset"),
+		NearLimit: strings.EqualFold(h.Get("X-RateLimit-NearLimit"), "true"),
+		Reason:    h.Get("RateLimit-Reason"),
+	}
+}
+
+// RetryEvent describes a retry about to happen. It is passed to Config.OnRetry.
+type RetryEvent struct {
+	Method      string
+	Path        string
+	Attempt     int           // 1-based number of the retry about to be made
+	MaxAttempts int           // total attempts allowed by the policy
+	StatusCode  int           // 0 when the previous attempt failed at the transport level
+	Err         error         // error from the previous attempt
+	Delay       time.Duration // wait before the retry
+	FromServer  bool          // Delay came from Retry-After / X-RateLimit-Reset
+	RateLimit   RateLimitInfo
+}
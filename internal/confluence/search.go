@@ -1,50 +1,69 @@
 package confluence
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
 	"net/url"
-	"strconv"
 )
 
 // SearchCQL performs a CQL search (always v1 — no v2 search endpoint exists).
+// limit caps the number of results; <= 0 reads the whole result set.
 func (c *Client) SearchCQL(cql string, limit int) (*SearchResult, error) {
-	q := url.Values{"cql": {cql}}
-	if limit > 0 {
-		q.Set("limit", strconv.Itoa(limit))
-	}
+	return c.SearchCQLContext(context.Background(), cql, limit)
+}
+
+// SearchCQLContext is like SearchCQL but honors ctx for cancellation and deadlines.
+func (c *Client) SearchCQLContext(ctx context.Context, cql string, limit int) (*SearchResult, error) {
+	return c.SearchCQLFromContext(ctx, cql, 0, limit)
+}
+
+// SearchCQLFrom performs a CQL search starting at offset start, following
+// pagination until limit results are collected (<= 0 reads everything).
+// TotalSize on the result reports the server-side match count.
+func (c *Client) SearchCQLFrom(cql string, start, limit int) (*SearchResult, error) {
+	return c.SearchCQLFromContext(context.Background(), cql, start, limit)
+}
+
+// SearchCQLFromContext is like SearchCQLFrom but honors ctx for cancellation and deadlines.
+func (c *Client) SearchCQLFromContext(ctx context.Context, cql string, start, limit int) (*SearchResult, error) {
+	q := url.Values{"cql": {cql}, "expand": {"content.space"}}
 
 	// Use /rest/api/search for rich results (includes excerpts).
-	data, err := c.getV1("search", q)
+	it := PaginateV1[SearchResultItem](ctx, c, "search", q, start, limit)
+	items, err := it.All()
 	if err != nil {
 		return nil, err
 	}
 
-	var result SearchResult
-	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, fmt.Errorf("parsing search results: %w", err)
+	var spaces []Space
+	for _, item := range items {
+		if item.Content != nil && item.Content.Space != nil && item.Content.Space.ID != 0 {
+			spaces = append(spaces, v1ToSpaces([]V1Space{*item.Content.Space})...)
+		}
 	}
-	return &result, nil
+	c.spaces.store(c.baseURL, spaces...)
+
+	return &SearchResult{
+		Results:   items,
+		Start:     start,
+		Limit:     limit,
+		Size:      len(items),
+		TotalSize: it.TotalSize(),
+		CQLQuery:  cql,
+	}, nil
 }
 
 // SearchContentCQL performs a content-only CQL search (v1, simpler response).
+// limit caps the number of results; <= 0 reads the whole result set.
 func (c *Client) SearchContentCQL(cql string, limit int, expand string) ([]V1Content, error) {
+	return c.SearchContentCQLContext(context.Background(), cql, limit, expand)
+}
+
+// SearchContentCQLContext is like SearchContentCQL but honors ctx for cancellation and deadlines.
+func (c *Client) SearchContentCQLContext(ctx context.Context, cql string, limit int, expand string) ([]V1Content, error) {
 	q := url.Values{"cql": {cql}}
-	if limit > 0 {
-		q.Set("limit", strconv.Itoa(limit))
-	}
 	if expand != "" {
 		q.Set("expand", expand)
 	}
 
-	data, err := c.getV1("content/search", q)
-	if err != nil {
-		return nil, err
-	}
-
-	var result V1PageResults
-	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, fmt.Errorf("parsing content search: %w", err)
-	}
-	return result.Results, nil
+	return PaginateV1[V1Content](ctx, c, "content/search", q, 0, limit).All()
 }
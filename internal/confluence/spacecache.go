@@ -0,0 +1,232 @@
+package confluence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultSpaceCacheTTL is how long a persisted key/ID mapping is trusted.
+// Space keys rarely change, but they can be renamed on Cloud.
+const DefaultSpaceCacheTTL = 24 * time.Hour
+
+// SpaceCache maps space keys to space IDs and back, per instance.
+// It is safe for concurrent use. When created with a path, every new mapping
+// is written through to that JSON file so later processes can reuse it.
+type SpaceCache struct {
+	mu        sync.RWMutex
+	path      string        // "" keeps the cache in memory only
+	ttl       time.Duration // <= 0 means entries never expire
+	instances map[string]*spaceIndex
+}
+
+type spaceIndex struct {
+	byKey map[string]spaceCacheEntry
+	byID  map[string]spaceCacheEntry
+}
+
+type spaceCacheEntry struct {
+	ID       string    `json:"id"`
+	Key      string    `json:"key"`
+	StoredAt time.Time `json:"storedAt"`
+}
+
+// spaceCacheFile is the on-disk layout: entries grouped by instance base URL.
+type spaceCacheFile struct {
+	Instances map[string][]spaceCacheEntry `json:"instances"`
+}
+
+// NewSpaceCache returns a cache backed by the JSON file at path (empty for memory only).
+// An unreadable or corrupt file is ignored and rewritten on the next store.
+func NewSpaceCache(path string, ttl time.Duration) *SpaceCache {
+	sc := &SpaceCache{
+		path:      path,
+		ttl:       ttl,
+		instances: make(map[string]*spaceIndex),
+	}
+	if path != "" {
+		if file, err := readSpaceCacheFile(path); err == nil {
+			sc.merge(file)
+		}
+	}
+	return sc
+}
+
+// lookupID returns the cached ID for a space key on instance.
+func (sc *SpaceCache) lookupID(instance, key string) (string, bool) {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	idx, ok := sc.instances[instance]
+	if !ok {
+		return "", false
+	}
+	e, ok := idx.byKey[key]
+	if !ok || sc.expired(e) {
+		return "", false
+	}
+	return e.ID, true
+}
+
+// lookupKey returns the cached key for a space ID on instance.
+func (sc *SpaceCache) lookupKey(instance, id string) (string, bool) {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	idx, ok := sc.instances[instance]
+	if !ok {
+		return "", false
+	}
+	e, ok := idx.byID[id]
+	if !ok || sc.expired(e) {
+		return "", false
+	}
+	return e.Key, true
+}
+
+// store records key<->id mappings for instance and writes them through to disk.
+// Persistence is best effort: a failed write only costs a lookup next time.
+func (sc *SpaceCache) store(instance string, spaces ...Space) {
+	now := time.Now()
+	sc.mu.Lock()
+	changed := false
+	for _, s := range spaces {
+		if s.ID == "" || s.Key == "" {
+			continue
+		}
+		idx := sc.index(instance)
+		if old, ok := idx.byKey[s.Key]; ok && old.ID == s.ID && !sc.expired(old) {
+			continue
+		}
+		e := spaceCacheEntry{ID: s.ID, Key: s.Key, StoredAt: now}
+		idx.put(e)
+		changed = true
+	}
+	sc.mu.Unlock()
+
+	if changed && sc.path != "" {
+		_ = sc.Save()
+	}
+}
+
+// Save writes the cache to its file, merging with entries other processes stored meanwhile.
+func (sc *SpaceCache) Save() error {
+	if sc.path == "" {
+		return nil
+	}
+
+	// Re-read first so concurrent CLI invocations don't drop each other's entries.
+	if file, err := readSpaceCacheFile(sc.path); err == nil {
+		sc.merge(file)
+	}
+
+	sc.mu.RLock()
+	file := spaceCacheFile{Instances: make(map[string][]spaceCacheEntry, len(sc.instances))}
+	for instance, idx := range sc.instances {
+		for _, e := range idx.byKey {
+			if !sc.expired(e) {
+				file.Instances[instance] = append(file.Instances[instance], e)
+			}
+		}
+	}
+	sc.mu.RUnlock()
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling space cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(sc.path), 0o755); err != nil {
+		return fmt.Errorf("creating space cache directory: %w", err)
+	}
+	if err := writeFileAtomic(sc.path, data, 0o644); err != nil {
+		return fmt.Errorf("writing space cache: %w", err)
+	}
+	return nil
+}
+
+// Clear drops every cached mapping and removes the cache file.
+func (sc *SpaceCache) Clear() error {
+	sc.mu.Lock()
+	sc.instances = make(map[string]*spaceIndex)
+	sc.mu.Unlock()
+
+	if sc.path == "" {
+		return nil
+	}
+	if err := os.Remove(sc.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing space cache: %w", err)
+	}
+	return nil
+}
+
+// merge adds entries from file that are newer than what is held in memory.
+func (sc *SpaceCache) merge(file spaceCacheFile) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	for instance, entries := range file.Instances {
+		idx := sc.index(instance)
+		for _, e := range entries {
+			if e.ID == "" || e.Key == "" || sc.expired(e) {
+				continue
+			}
+			if idx.hasNewer(e) {
+				continue
+			}
+			idx.put(e)
+		}
+	}
+}
+
+// index returns the per-instance index, creating it. Callers must hold mu for writing.
+func (sc *SpaceCache) index(instance string) *spaceIndex {
+	idx, ok := sc.instances[instance]
+	if !ok {
+		idx = &spaceIndex{
+			byKey: make(map[string]spaceCacheEntry),
+			byID:  make(map[string]spaceCacheEntry),
+		}
+		sc.instances[instance] = idx
+	}
+	return idx
+}
+
+func (sc *SpaceCache) expired(e spaceCacheEntry) bool {
+	return sc.ttl > 0 && time.Since(e.StoredAt) > sc.ttl
+}
+
+// put replaces any mapping that shares e's key or ID, so renames don't leave stale reverse entries.
+func (idx *spaceIndex) put(e spaceCacheEntry) {
+	if old, ok := idx.byKey[e.Key]; ok {
+		delete(idx.byID, old.ID)
+	}
+	if old, ok := idx.byID[e.ID]; ok {
+		delete(idx.byKey, old.Key)
+	}
+	idx.byKey[e.Key] = e
+	idx.byID[e.ID] = e
+}
+
+// hasNewer reports whether the index already holds a mapping for e's key or ID at least as recent as e.
+func (idx *spaceIndex) hasNewer(e spaceCacheEntry) bool {
+	if old, ok := idx.byKey[e.Key]; ok && !old.StoredAt.Before(e.StoredAt) {
+		return true
+	}
+	if old, ok := idx.byID[e.ID]; ok && !old.StoredAt.Before(e.StoredAt) {
+		return true
+	}
+	return false
+}
+
+func readSpaceCacheFile(path string) (spaceCacheFile, error) {
+	var file spaceCacheFile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return file, err
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return file, fmt.Errorf("parsing space cache: %w", err)
+	}
+	return file, nil
+}
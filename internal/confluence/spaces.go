@@ -1,58 +1,48 @@
 package confluence
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/url"
 	"strconv"
 )
 
 // ListSpaces returns all accessible spaces.
 func (c *Client) ListSpaces(limit int) ([]Space, error) {
-	if c.IsCloud() {
-		return c.listSpacesV2(limit)
-	}
-	return c.listSpacesV1(limit)
+	return c.ListSpacesContext(context.Background(), limit)
 }
 
-func (c *Client) listSpacesV2(limit int) ([]Space, error) {
-	q := url.Values{}
-	if limit > 0 {
-		q.Set("limit", strconv.Itoa(limit))
+// ListSpacesContext is like ListSpaces but honors ctx for cancellation and deadlines.
+func (c *Client) ListSpacesContext(ctx context.Context, limit int) ([]Space, error) {
+	if c.Has(CapV2) {
+		return c.listSpacesV2(ctx, limit)
 	}
+	return c.listSpacesV1(ctx, limit)
+}
 
-	data, err := c.getV2("spaces", q)
+func (c *Client) listSpacesV2(ctx context.Context, limit int) ([]Space, error) {
+	spaces, err := PaginateV2[Space](ctx, c, "spaces", nil, limit).All()
 	if err != nil {
 		return nil, err
 	}
-
-	var page CursorPage[Space]
-	if err := json.Unmarshal(data, &page); err != nil {
-		return nil, fmt.Errorf("parsing spaces: %w", err)
-	}
-	return page.Results, nil
+	c.spaces.store(c.baseURL, spaces...)
+	return spaces, nil
 }
 
-func (c *Client) listSpacesV1(limit int) ([]Space, error) {
-	q := url.Values{}
-	if limit > 0 {
-		q.Set("limit", strconv.Itoa(limit))
-	}
-
-	data, err := c.getV1("space", q)
+func (c *Client) listSpacesV1(ctx context.Context, limit int) ([]Space, error) {
+	results, err := PaginateV1[V1Space](ctx, c, "space", nil, 0, limit).All()
 	if err != nil {
 		return nil, err
 	}
 
-	var result struct {
-		Results []V1Space `json:"results"`
-	}
-	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, fmt.Errorf("parsing v1 spaces: %w", err)
-	}
+	spaces := v1ToSpaces(results)
+	c.spaces.store(c.baseURL, spaces...)
+	return spaces, nil
+}
 
-	spaces := make([]Space, len(result.Results))
-	for i, s := range result.Results {
+func v1ToSpaces(results []V1Space) []Space {
+	spaces := make([]Space, len(results))
+	for i, s := range results {
 		spaces[i] = Space{
 			ID:   strconv.Itoa(s.ID),
 			Key:  s.Key,
@@ -60,17 +50,22 @@ func (c *Client) listSpacesV1(limit int) ([]Space, error) {
 			Type: s.Type,
 		}
 	}
-	return spaces, nil
+	return spaces
 }
 
 // GetSpace retrieves a single space by key.
 func (c *Client) GetSpace(spaceKey string) (*Space, error) {
-	if c.IsCloud() {
-		id, err := c.ResolveSpaceKey(spaceKey)
+	return c.GetSpaceContext(context.Background(), spaceKey)
+}
+
+// GetSpaceContext is like GetSpace but honors ctx for cancellation and deadlines.
+func (c *Client) GetSpaceContext(ctx context.Context, spaceKey string) (*Space, error) {
+	if c.Has(CapV2) {
+		id, err := c.ResolveSpaceKeyContext(ctx, spaceKey)
 		if err != nil {
 			return nil, err
 		}
-		data, err := c.getV2("spaces/"+id, nil)
+		data, err := c.getV2(ctx, "spaces/"+id, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -81,7 +76,7 @@ func (c *Client) GetSpace(spaceKey string) (*Space, error) {
 		return &space, nil
 	}
 
-	data, err := c.getV1("space/"+spaceKey, nil)
+	data, err := c.getV1(ctx, "space/"+spaceKey, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,362 @@
+package confluence
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redacted replaces secrets in traces and HAR files.
+const redacted = "REDACTED"
+
+// TraceEntry describes one HTTP attempt made by request(). Secrets are
+// already redacted: the Authorization header, cookies, token-like query
+// parameters and token-like JSON body fields.
+type TraceEntry struct {
+	Method         string
+	URL            string
+	Attempt        int // 1-based
+	MaxAttempts    int
+	Started        time.Time
+	Duration       time.Duration
+	RequestHeader  http.Header
+	RequestBody    []byte
+	StatusCode     int // 0 when the attempt failed at the transport level
+	Status         string
+	ResponseHeader http.Header
+	ResponseBody   []byte
+	Err            error
+}
+
+// traceAttempt reports a finished attempt to the OnTrace hook.
+func (c *Client) traceAttempt(req *http.Request, reqBody []byte, resp *http.Response, respBody []byte, attempt int, started time.Time, err error) {
+	traceHTTP(c.onTrace, req, reqBody, resp, respBody, attempt, c.retry.MaxAttempts, started, err)
+}
+
+// traceHTTP builds a redacted TraceEntry and passes it to onTrace, if set.
+func traceHTTP(onTrace func(TraceEntry), req *http.Request, reqBody []byte, resp *http.Response, respBody []byte, attempt, maxAttempts int, started time.Time, err error) {
+	if onTrace == nil {
+		return
+	}
+	entry := TraceEntry{
+		Method:        req.Method,
+		URL:           redactURL(req.URL),
+		Attempt:       attempt + 1,
+		MaxAttempts:   maxAttempts,
+		Started:       started,
+		Duration:      time.Since(started),
+		RequestHeader: redactHeader(req.Header),
+		RequestBody:   redactBody(reqBody),
+		Err:           err,
+	}
+	if resp != nil {
+		entry.StatusCode = resp.StatusCode
+		entry.Status = resp.Status
+		entry.ResponseHeader = redactHeader(resp.Header)
+		entry.ResponseBody = redactBody(respBody)
+	}
+	onTrace(entry)
+}
+
+// sensitiveHeaders never appear in traces with their real values.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+func redactHeader(h http.Header) http.Header {
+	out := h.Clone()
+	for _, name := range sensitiveHeaders {
+		if _, ok := out[name]; ok {
+			out[name] = []string{redacted}
+		}
+	}
+	return out
+}
+
+// isSecretName matches query parameter and JSON field names that carry credentials.
+func isSecretName(name string) bool {
+	n := strings.ToLower(name)
+	for _, s := range []string{"token", "secret", "password", "passwd", "apikey", "api_key", "authorization"} {
+		if strings.Contains(n, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactURL(u *url.URL) string {
+	out := *u
+	out.User = nil
+	q := out.Query()
+	changed := false
+	for name := range q {
+		if isSecretName(name) {
+			q[name] = []string{redacted}
+			changed = true
+		}
+	}
+	if changed {
+		out.RawQuery = q.Encode()
+	}
+	return out.String()
+}
+
+// redactBody masks secret-looking fields in a JSON body. Non-JSON bodies are returned unchanged.
+func redactBody(body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return body
+	}
+	if !redactValue(v) {
+		return body
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+// redactValue masks secret fields in place and reports whether anything changed.
+func redactValue(v any) bool {
+	changed := false
+	switch t := v.(type) {
+	case map[string]any:
+		// OAuth token exchanges carry the authorization code and PKCE verifier.
+		_, isGrant := t["grant_type"]
+		for k, val := range t {
+			if isSecretName(k) || (isGrant && (k == "code" || k == "code_verifier")) {
+				if _, isString := val.(string); isString {
+					t[k] = redacted
+					changed = true
+					continue
+				}
+			}
+			if redactValue(val) {
+				changed = true
+			}
+		}
+	case []any:
+		for _, val := range t {
+			if redactValue(val) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// FormatTrace renders an entry as the one-line summary used by --trace,
+// followed by the request body when there is one.
+func FormatTrace(e TraceEntry) string {
+	var b strings.Builder
+	result := e.Status
+	if e.Err != nil && e.StatusCode == 0 {
+		result = "error: " + e.Err.Error()
+	}
+	fmt.Fprintf(&b, "trace: %s %s -> %s (%s, attempt %d/%d)",
+		e.Method, e.URL, result, e.Duration.Round(time.Millisecond), e.Attempt, e.MaxAttempts)
+	if len(e.RequestBody) > 0 {
+		fmt.Fprintf(&b, "\ntrace:   body: %s", truncateForTrace(e.RequestBody, 2048))
+	}
+	return b.String()
+}
+
+func truncateForTrace(data []byte, max int) string {
+	if len(data) <= max {
+		return string(data)
+	}
+	return string(data[:max]) + fmt.Sprintf("... (%d bytes total)", len(data))
+}
+
+// --- HAR 1.2 export ---
+
+// HARRecorder collects trace entries and writes them as a HAR 1.2 archive.
+// Pass its Record method as Config.OnTrace. It is safe for concurrent use.
+type HARRecorder struct {
+	mu      sync.Mutex
+	creator string
+	version string
+	entries []harEntry
+}
+
+// NewHARRecorder returns an empty recorder; creator and version name the tool in the archive.
+func NewHARRecorder(creator, version string) *HARRecorder {
+	return &HARRecorder{creator: creator, version: version}
+}
+
+// Record adds one attempt to the archive.
+func (r *HARRecorder) Record(e TraceEntry) {
+	entry := harEntry{
+		StartedDateTime: e.Started.Format(time.RFC3339Nano),
+		Time:            durationMillis(e.Duration),
+		Request: harRequest{
+			Method:      e.Method,
+			URL:         e.URL,
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(e.RequestHeader),
+			QueryString: harQuery(e.URL),
+			HeadersSize: -1,
+			BodySize:    len(e.RequestBody),
+		},
+		Response: harResponse{
+			Status:      e.StatusCode,
+			StatusText:  strings.TrimSpace(strings.TrimPrefix(e.Status, fmt.Sprint(e.StatusCode))),
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(e.ResponseHeader),
+			Content: harContent{
+				Size:     len(e.ResponseBody),
+				MimeType: e.ResponseHeader.Get("Content-Type"),
+				Text:     string(e.ResponseBody),
+			},
+			RedirectURL: "",
+			HeadersSize: -1,
+			BodySize:    len(e.ResponseBody),
+		},
+		Cache:   struct{}{},
+		Timings: harTimings{Send: 0, Wait: durationMillis(e.Duration), Receive: 0},
+		Attempt: e.Attempt,
+	}
+	if len(e.RequestBody) > 0 {
+		entry.Request.PostData = &harPostData{
+			MimeType: e.RequestHeader.Get("Content-Type"),
+			Text:     string(e.RequestBody),
+		}
+	}
+	if e.Err != nil {
+		entry.Error = e.Err.Error()
+	}
+
+	r.mu.Lock()
+	r.entries = append(r.entries, entry)
+	r.mu.Unlock()
+}
+
+// WriteFile writes the collected entries to path.
+func (r *HARRecorder) WriteFile(path string) error {
+	r.mu.Lock()
+	entries := append([]harEntry{}, r.entries...)
+	r.mu.Unlock()
+
+	doc := harDocument{Log: harLog{
+		Version: "1.2",
+		Creator: harCreator{Name: r.creator, Version: r.version},
+		Entries: entries,
+	}}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling HAR: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("writing HAR file: %w", err)
+	}
+	return nil
+}
+
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func harHeaders(h http.Header) []harNameValue {
+	out := []harNameValue{}
+	for name, values := range h {
+		for _, v := range values {
+			out = append(out, harNameValue{Name: name, Value: v})
+		}
+	}
+	return out
+}
+
+func harQuery(rawURL string) []harNameValue {
+	out := []harNameValue{}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return out
+	}
+	for name, values := range u.Query() {
+		for _, v := range values {
+			out = append(out, harNameValue{Name: name, Value: v})
+		}
+	}
+	return out
+}
+
+type harDocument struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Attempt         int         `json:"_attempt,omitempty"` // custom field: retry attempt, 1-based
+	Error           string      `json:"_error,omitempty"`   // custom field: transport error
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
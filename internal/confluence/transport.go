@@ -0,0 +1,157 @@
+package confluence
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ProxyConfig routes the client through an explicit HTTP(S) proxy instead of
+// the HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment.
+type ProxyConfig struct {
+	URL      string // e.g. "http://proxy.corp:3128"; empty falls back to the environment
+	Username string // optional; sent as Proxy-Authorization
+	Password string
+	NoProxy  string // comma-separated hosts, domains (.corp.com), IPs or CIDRs to reach directly; "*" bypasses the proxy
+}
+
+// proxyFunc returns the Transport.Proxy function for p. A nil p, or one
+// without a URL, keeps the environment settings.
+func proxyFunc(p *ProxyConfig) (func(*http.Request) (*url.URL, error), error) {
+	if p == nil || strings.TrimSpace(p.URL) == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	raw := strings.TrimSpace(p.URL)
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	proxyURL, err := url.Parse(raw)
+	if err != nil || proxyURL.Host == "" {
+		return nil, fmt.Errorf("confluence: invalid proxy URL %q", p.URL)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("confluence: unsupported proxy scheme %q (use http, https or socks5)", proxyURL.Scheme)
+	}
+	if p.Username != "" {
+		proxyURL.User = url.UserPassword(p.Username, p.Password)
+	}
+
+	noProxy := splitNoProxy(p.NoProxy)
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL, noProxy) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}, nil
+}
+
+func splitNoProxy(list string) []string {
+	var entries []string
+	for _, entry := range strings.Split(list, ",") {
+		if entry = strings.ToLower(strings.TrimSpace(entry)); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// bypassProxy reports whether u matches a no-proxy entry. Entries follow the
+// usual NO_PROXY conventions: "*", exact hosts, domain suffixes with or
+// without a leading dot, IPs, CIDR ranges, and an optional ":port".
+func bypassProxy(u *url.URL, noProxy []string) bool {
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+	ip := net.ParseIP(host)
+
+	for _, entry := range noProxy {
+		if entry == "*" {
+			return true
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		entryHost, entryPort := entry, ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			entryHost, entryPort = h, p
+		}
+		if entryPort != "" && entryPort != port {
+			continue
+		}
+		entryHost = strings.Trim(entryHost, "[]")
+		if entryIP := net.ParseIP(entryHost); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		domain := strings.TrimPrefix(entryHost, "*")
+		domain = strings.TrimPrefix(domain, ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// newTransport builds the HTTP transport for cfg and describes the TLS trust
+// path it uses. All client traffic goes through it, so proxy and TLS settings
+// always apply together.
+func newTransport(cfg Config) (*http.Transport, string, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	proxy, err := proxyFunc(cfg.Proxy)
+	if err != nil {
+		return nil, "", err
+	}
+	transport.Proxy = proxy
+
+	tlsConfig := &tls.Config{}
+	trust := "system roots"
+
+	switch {
+	case cfg.InsecureSkipVerify:
+		tlsConfig.InsecureSkipVerify = true
+		trust = "verification disabled (insecure)"
+	case cfg.CABundle != "":
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(cfg.CABundle)
+		if err != nil {
+			return nil, "", fmt.Errorf("confluence: reading CA bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, "", fmt.Errorf("confluence: CA bundle %s contains no PEM certificates", cfg.CABundle)
+		}
+		tlsConfig.RootCAs = pool
+		trust = "system roots + CA bundle " + cfg.CABundle
+	}
+
+	if cfg.ClientCert != "" || cfg.ClientKey != "" {
+		if cfg.ClientCert == "" || cfg.ClientKey == "" {
+			return nil, "", fmt.Errorf("confluence: mutual TLS needs both a client certificate and a key")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, "", fmt.Errorf("confluence: loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+		trust += ", client certificate " + cfg.ClientCert
+	}
+
+	transport.TLSClientConfig = tlsConfig
+	return transport, trust, nil
+}
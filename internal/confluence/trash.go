@@ -0,0 +1,205 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// DeleteManifest records the pages a recursive delete moved to the trash.
+type DeleteManifest struct {
+	// Pages lists the trashed pages in the order to restore them: every
+	// page comes before its children.
+	Pages []TrashedPage `json:"pages"`
+}
+
+// TrashedPage is one entry of a DeleteManifest.
+type TrashedPage struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	ParentID string `json:"parentId,omitempty"`
+}
+
+// IDs returns the manifest's page IDs in restore order.
+func (m *DeleteManifest) IDs() []string {
+	ids := make([]string, len(m.Pages))
+	for i, p := range m.Pages {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+// ListTrash lists the pages in a space's trash (v2 Cloud, v1 Server/DC).
+func (c *Client) ListTrash(spaceKey string, limit int) ([]Page, error) {
+	return c.ListTrashContext(context.Background(), spaceKey, limit)
+}
+
+// ListTrashContext is like ListTrash but honors ctx for cancellation and deadlines.
+func (c *Client) ListTrashContext(ctx context.Context, spaceKey string, limit int) ([]Page, error) {
+	return c.listPagesByStatus(ctx, spaceKey, "trashed", limit)
+}
+
+// RestoreFromTrash brings a trashed page back with its last title and body,
+// as a new version. It returns under its old parent if that page is live,
+// and at the top of the space otherwise, so restore parents first.
+func (c *Client) RestoreFromTrash(pageID string) (*Page, error) {
+	return c.RestoreFromTrashContext(context.Background(), pageID)
+}
+
+// RestoreFromTrashContext is like RestoreFromTrash but honors ctx for cancellation and deadlines.
+func (c *Client) RestoreFromTrashContext(ctx context.Context, pageID string) (*Page, error) {
+	page, err := c.getTrashedPage(ctx, pageID, true)
+	if err != nil {
+		return nil, err
+	}
+	version := 0
+	if page.Version != nil {
+		version = page.Version.Number
+	}
+
+	if c.Has(CapV2) {
+		req := UpdatePageRequest{
+			ID:      pageID,
+			Status:  "current",
+			Title:   page.Title,
+			Body:    &CreatePageBody{Representation: "storage", Value: storageValue(page)},
+			Version: &VersionUpdate{Number: version + 1, Message: "Restored from trash"},
+		}
+		data, err := c.putV2(ctx, "pages/"+pageID, req)
+		if err != nil {
+			return nil, err
+		}
+		var restored Page
+		if err := json.Unmarshal(data, &restored); err != nil {
+			return nil, fmt.Errorf("parsing restored page: %w", err)
+		}
+		return &restored, nil
+	}
+
+	v1Req := map[string]interface{}{
+		"type":   "page",
+		"status": "current",
+		"title":  page.Title,
+		"body": map[string]interface{}{
+			"storage": map[string]string{"value": storageValue(page), "representation": "storage"},
+		},
+		"version": map[string]interface{}{"number": version + 1, "message": "Restored from trash"},
+	}
+	data, err := c.request(ctx, http.MethodPut, c.v1URL("content", pageID), nil, v1Req)
+	if err != nil {
+		return nil, err
+	}
+	var v1 V1Content
+	if err := json.Unmarshal(data, &v1); err != nil {
+		return nil, fmt.Errorf("parsing v1 restored page: %w", err)
+	}
+	return v1ToPage(&v1), nil
+}
+
+// PurgePage permanently deletes a page that is already in the trash. This
+// cannot be undone. Pages that aren't in the trash are refused rather than
+// trashed.
+func (c *Client) PurgePage(pageID string) error {
+	return c.PurgePageContext(context.Background(), pageID)
+}
+
+// PurgePageContext is like PurgePage but honors ctx for cancellation and deadlines.
+func (c *Client) PurgePageContext(ctx context.Context, pageID string) error {
+	if _, err := c.getTrashedPage(ctx, pageID, false); err != nil {
+		return err
+	}
+	if c.Has(CapV2) {
+		_, err := c.request(ctx, http.MethodDelete, c.v2URL("pages", pageID), url.Values{"purge": {"true"}}, nil)
+		return err
+	}
+	_, err := c.request(ctx, http.MethodDelete, c.v1URL("content", pageID), url.Values{"status": {"trashed"}}, nil)
+	return err
+}
+
+// getTrashedPage fetches a page from the trash. A live page is an error, so
+// restore and purge never act on content the caller didn't delete.
+func (c *Client) getTrashedPage(ctx context.Context, pageID string, includeBody bool) (*Page, error) {
+	var page *Page
+	if c.Has(CapV2) {
+		q := url.Values{"status": {"trashed"}}
+		if includeBody {
+			q.Set("body-format", "storage")
+		}
+		data, err := c.getV2(ctx, "pages/"+pageID, q)
+		if err != nil {
+			return nil, err
+		}
+		page = &Page{}
+		if err := json.Unmarshal(data, page); err != nil {
+			return nil, fmt.Errorf("parsing page: %w", err)
+		}
+	} else {
+		expand := "version,space,ancestors"
+		if includeBody {
+			expand += ",body.storage"
+		}
+		data, err := c.getV1(ctx, "content/"+pageID, url.Values{"status": {"trashed"}, "expand": {expand}})
+		if err != nil {
+			return nil, err
+		}
+		var v1 V1Content
+		if err := json.Unmarshal(data, &v1); err != nil {
+			return nil, fmt.Errorf("parsing v1 page: %w", err)
+		}
+		page = v1ToPage(&v1)
+	}
+
+	if page.Status != "trashed" {
+		return nil, fmt.Errorf("confluence: page %s is not in the trash (status %q)", pageID, page.Status)
+	}
+	return page, nil
+}
+
+// DeletePageTree moves a page and all its descendants to the trash. Pages
+// are deleted deepest first, so no child is ever re-parented by Confluence
+// when its parent goes; the manifest lists them in the opposite order for
+// restoring. On error the manifest holds the pages trashed so far.
+func (c *Client) DeletePageTree(pageID string) (*DeleteManifest, error) {
+	return c.DeletePageTreeContext(context.Background(), pageID)
+}
+
+// DeletePageTreeContext is like DeletePageTree but honors ctx for cancellation and deadlines.
+func (c *Client) DeletePageTreeContext(ctx context.Context, pageID string) (*DeleteManifest, error) {
+	root, err := c.GetPageContext(ctx, pageID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk the tree parents first; deleting in reverse then removes every
+	// page after all of its descendants.
+	tree := []TrashedPage{{ID: root.ID, Title: root.Title, ParentID: root.ParentID}}
+	for i := 0; i < len(tree); i++ {
+		children, err := c.GetChildrenContext(ctx, tree[i].ID, 0)
+		if err != nil {
+			return nil, fmt.Errorf("listing children of %s: %w", tree[i].ID, err)
+		}
+		for _, child := range children {
+			tree = append(tree, TrashedPage{ID: child.ID, Title: child.Title, ParentID: tree[i].ID})
+		}
+	}
+
+	deleted := make([]bool, len(tree))
+	manifest := func() *DeleteManifest {
+		m := &DeleteManifest{Pages: []TrashedPage{}}
+		for i, p := range tree {
+			if deleted[i] {
+				m.Pages = append(m.Pages, p)
+			}
+		}
+		return m
+	}
+	for i := len(tree) - 1; i >= 0; i-- {
+		if err := c.DeletePageContext(ctx, tree[i].ID); err != nil {
+			return manifest(), fmt.Errorf("deleting page %s: %w", tree[i].ID, err)
+		}
+		deleted[i] = true
+	}
+	return manifest(), nil
+}
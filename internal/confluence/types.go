@@ -1,6 +1,10 @@
 package confluence
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
 
 // --- Client Config ---
 
@@ -18,6 +22,7 @@ type AuthType string
 const (
 	AuthBasic  AuthType = "basic"  // Cloud: email + API token -> Basic base64(email:token)
 	AuthBearer AuthType = "bearer" // Server/DC: Personal Access Token -> Bearer <token>
+	AuthOAuth  AuthType = "oauth"  // Cloud: OAuth 2.0 (3LO) access token via api.atlassian.com
 )
 
 // Config holds the configuration needed to connect to a Confluence instance.
@@ -26,19 +31,43 @@ type Config struct {
 	Email              string       // Required for Basic auth (Cloud)
 	Token              string       // API token (Basic) or PAT (Bearer)
 	InstanceType       InstanceType // "cloud" or "server"
+	Capabilities       []Capability // from DetectInstance; nil assumes v2 only on Cloud
 	AuthType           AuthType     // "basic" or "bearer"
 	InsecureSkipVerify bool         // Skip TLS certificate verification (corporate CAs)
+	CABundle           string       // PEM file of extra CAs trusted on top of the system pool
+	ClientCert         string       // PEM client certificate for mutual TLS
+	ClientKey          string       // PEM private key for ClientCert
+	Proxy              *ProxyConfig // nil uses the HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment
+	OAuth              *OAuthConfig // required for AuthOAuth
+
+	RetryPolicy   *RetryPolicy     // nil uses DefaultRetryPolicy()
+	OnRetry       func(RetryEvent) // optional hook called before each retry (e.g. verbose logging)
+	OnTrace       func(TraceEntry) // optional hook called after every HTTP attempt, secrets redacted
+	RateLimit     *RateLimit       // nil uses DefaultRateLimit(); zero rates disable pacing
+	SpaceCache    *SpaceCache      // nil keeps space key/ID mappings in memory for this client only
+	ResponseCache *ResponseCache   // nil disables the on-disk response cache
 }
 
 // --- Error Types ---
 
 // APIError represents an error response from the Confluence REST API.
 type APIError struct {
-	StatusCode int    `json:"-"`
-	Message    string `json:"message,omitempty"`
+	StatusCode int           `json:"-"`
+	RetryAfter time.Duration `json:"-"` // server-requested wait on 429/503, if any
+	Message    string        `json:"message,omitempty"`
 	// V1 error format
 	ErrorMessage string `json:"errorMessage,omitempty"`
 	StatusText   string `json:"statusCode,omitempty"`
+	// Errors holds the v2 errors[] array, or v1 data.errors[] mapped onto the same shape.
+	Errors []ErrorDetail `json:"errors,omitempty"`
+}
+
+// ErrorDetail is one entry of a v2 error response's errors[] array.
+type ErrorDetail struct {
+	Status int    `json:"status,omitempty"`
+	Code   string `json:"code,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Detail string `json:"detail,omitempty"`
 }
 
 func (e *APIError) Error() string {
@@ -48,9 +77,23 @@ func (e *APIError) Error() string {
 	if e.ErrorMessage != "" {
 		return e.ErrorMessage
 	}
+	if len(e.Errors) > 0 {
+		return e.Errors[0].String()
+	}
 	return "confluence: unknown API error"
 }
 
+// String renders the detail as "CODE: title: detail", skipping empty parts.
+func (d ErrorDetail) String() string {
+	var parts []string
+	for _, p := range []string{d.Code, d.Title, d.Detail} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ": ")
+}
+
 // --- Page ---
 
 // Page represents a Confluence page (v2 API response shape).
@@ -59,6 +102,7 @@ type Page struct {
 	Status     string      `json:"status,omitempty"`     // "current", "draft", "trashed"
 	Title      string      `json:"title,omitempty"`
 	SpaceID    string      `json:"spaceId,omitempty"`
+	SpaceKey   string      `json:"spaceKey,omitempty"` // not sent by v2; filled from v1 responses or the space cache
 	ParentID   string      `json:"parentId,omitempty"`
 	ParentType string      `json:"parentType,omitempty"`
 	AuthorID   string      `json:"authorId,omitempty"`
@@ -102,6 +146,7 @@ type PageLinks struct {
 type Version struct {
 	Number    int    `json:"number,omitempty"`
 	Message   string `json:"message,omitempty"`
+	MinorEdit bool   `json:"minorEdit,omitempty"`
 	CreatedAt string `json:"createdAt,omitempty"`
 	AuthorID  string `json:"authorId,omitempty"`
 }
@@ -174,6 +219,25 @@ type SearchResultItem struct {
 	ResultGlobalContainer *ResultContainer `json:"resultGlobalContainer,omitempty"`
 }
 
+// SpaceKey returns the key of the space containing the result. It prefers the
+// expanded content.space and falls back to the container's display URL
+// ("/spaces/DEV" on Cloud, "/display/DEV" on Server/DC).
+func (r SearchResultItem) SpaceKey() string {
+	if r.Content != nil && r.Content.Space != nil && r.Content.Space.Key != "" {
+		return r.Content.Space.Key
+	}
+	if r.ResultGlobalContainer == nil {
+		return ""
+	}
+	for _, prefix := range []string{"/spaces/", "/display/"} {
+		if rest, ok := strings.CutPrefix(r.ResultGlobalContainer.DisplayURL, prefix); ok {
+			key, _, _ := strings.Cut(rest, "/")
+			return key
+		}
+	}
+	return ""
+}
+
 // ResultContainer holds space info for a search result.
 type ResultContainer struct {
 	Title      string `json:"title,omitempty"`
@@ -220,10 +284,11 @@ type V1BodyContent struct {
 
 // V1Version represents version info in v1 API.
 type V1Version struct {
-	Number  int    `json:"number,omitempty"`
-	Message string `json:"message,omitempty"`
-	When    string `json:"when,omitempty"`
-	By      *V1User `json:"by,omitempty"`
+	Number    int     `json:"number,omitempty"`
+	Message   string  `json:"message,omitempty"`
+	MinorEdit bool    `json:"minorEdit,omitempty"`
+	When      string  `json:"when,omitempty"`
+	By        *V1User `json:"by,omitempty"`
 }
 
 // V1User represents a user in v1 API.
@@ -277,6 +342,30 @@ func (p *CursorPage[T]) HasMore() bool {
 	return p.Links != nil && p.Links.Next != ""
 }
 
+// --- Pagination (v1 offset-based) ---
+
+// OffsetPage is the generic wrapper for v1 start/limit-paginated responses.
+type OffsetPage[T any] struct {
+	Results   []T          `json:"results"`
+	Start     int          `json:"start"`
+	Limit     int          `json:"limit"`
+	Size      int          `json:"size"`
+	TotalSize int          `json:"totalSize,omitempty"` // only reported by search endpoints
+	Links     *OffsetLinks `json:"_links,omitempty"`
+}
+
+// OffsetLinks holds v1 pagination links. Next is relative to the instance base URL.
+type OffsetLinks struct {
+	Next    string `json:"next,omitempty"`
+	Base    string `json:"base,omitempty"`
+	Context string `json:"context,omitempty"`
+}
+
+// HasMore returns true if the server advertised a next page.
+func (p *OffsetPage[T]) HasMore() bool {
+	return p.Links != nil && p.Links.Next != ""
+}
+
 // --- Request types for write operations ---
 
 // CreatePageRequest is the v2 request body for creating a page.
@@ -296,11 +385,12 @@ type CreatePageBody struct {
 
 // UpdatePageRequest is the v2 request body for updating a page.
 type UpdatePageRequest struct {
-	ID      string          `json:"id"`
-	Status  string          `json:"status"` // "current"
-	Title   string          `json:"title"`
-	Body    *CreatePageBody `json:"body,omitempty"`
-	Version *VersionUpdate  `json:"version"`
+	ID       string          `json:"id"`
+	Status   string          `json:"status"` // "current"
+	Title    string          `json:"title"`
+	ParentID string          `json:"parentId,omitempty"` // set to move the page within its space
+	Body     *CreatePageBody `json:"body,omitempty"`
+	Version  *VersionUpdate  `json:"version"`
 }
 
 // VersionUpdate holds the version number for page updates.
@@ -0,0 +1,248 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// ListPageVersions lists a page's versions, newest first (v2 Cloud, v1 Server/DC).
+// A limit of 0 returns the whole history.
+func (c *Client) ListPageVersions(pageID string, limit int) ([]Version, error) {
+	return c.ListPageVersionsContext(context.Background(), pageID, limit)
+}
+
+// ListPageVersionsContext is like ListPageVersions but honors ctx for cancellation and deadlines.
+func (c *Client) ListPageVersionsContext(ctx context.Context, pageID string, limit int) ([]Version, error) {
+	if c.Has(CapV2) {
+		q := url.Values{"sort": {"-modified-date"}}
+		return PaginateV2[Version](ctx, c, "pages/"+pageID+"/versions", q, limit).All()
+	}
+
+	results, err := PaginateV1[V1Version](ctx, c, "content/"+pageID+"/version", nil, 0, limit).All()
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]Version, len(results))
+	for i := range results {
+		versions[i] = *v1ToVersion(&results[i])
+	}
+	return versions, nil
+}
+
+// GetPageVersion retrieves a page as it was at version n, with its storage
+// body when includeBody is set (v2 Cloud, v1 Server/DC).
+func (c *Client) GetPageVersion(pageID string, n int, includeBody bool) (*Page, error) {
+	return c.GetPageVersionContext(context.Background(), pageID, n, includeBody)
+}
+
+// GetPageVersionContext is like GetPageVersion but honors ctx for cancellation and deadlines.
+func (c *Client) GetPageVersionContext(ctx context.Context, pageID string, n int, includeBody bool) (*Page, error) {
+	if c.Has(CapV2) {
+		q := url.Values{"version": {strconv.Itoa(n)}}
+		if includeBody {
+			q.Set("body-format", "storage")
+		}
+		data, err := c.getV2(ctx, "pages/"+pageID, q)
+		if err != nil {
+			return nil, err
+		}
+		var page Page
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("parsing page version: %w", err)
+		}
+		return &page, nil
+	}
+
+	expand := "version,space"
+	if includeBody {
+		expand += ",body.storage"
+	}
+	q := url.Values{
+		"status":  {"historical"},
+		"version": {strconv.Itoa(n)},
+		"expand":  {expand},
+	}
+	data, err := c.getV1(ctx, "content/"+pageID, q)
+	if err != nil {
+		return nil, err
+	}
+	var v1 V1Content
+	if err := json.Unmarshal(data, &v1); err != nil {
+		return nil, fmt.Errorf("parsing v1 page version: %w", err)
+	}
+	return v1ToPage(&v1), nil
+}
+
+// RestoreOptions guards and labels a version restore.
+type RestoreOptions struct {
+	// ExpectVersion is the current version the caller inspected before
+	// choosing to restore. When the page has moved past it the restore fails
+	// with a *VersionMismatchError. 0 skips the check.
+	ExpectVersion int
+	// Message is the new version's message; empty generates one.
+	Message string
+}
+
+// RestorePageVersion publishes the title and body of version n as a new
+// version of the page. Cloud uses the native restore operation; Server/DC,
+// and Cloud sites that reject it, read version n and write it back.
+func (c *Client) RestorePageVersion(pageID string, n int, opts RestoreOptions) (*Page, error) {
+	return c.RestorePageVersionContext(context.Background(), pageID, n, opts)
+}
+
+// RestorePageVersionContext is like RestorePageVersion but honors ctx for cancellation and deadlines.
+func (c *Client) RestorePageVersionContext(ctx context.Context, pageID string, n int, opts RestoreOptions) (*Page, error) {
+	current, err := c.GetPageContext(ctx, pageID, false)
+	if err != nil {
+		return nil, fmt.Errorf("reading current version: %w", err)
+	}
+	currentVersion := 0
+	if current.Version != nil {
+		currentVersion = current.Version.Number
+	}
+	if opts.ExpectVersion > 0 && currentVersion != opts.ExpectVersion {
+		return nil, &VersionMismatchError{PageID: pageID, Expected: opts.ExpectVersion, Current: currentVersion}
+	}
+	switch {
+	case n == currentVersion:
+		return nil, fmt.Errorf("confluence: version %d is already the current version of page %s", n, pageID)
+	case n < 1 || n > currentVersion:
+		return nil, fmt.Errorf("confluence: page %s has no version %d (current is %d)", pageID, n, currentVersion)
+	}
+
+	message := opts.Message
+	if message == "" {
+		message = fmt.Sprintf("Restored version %d", n)
+	}
+
+	if c.IsCloud() && c.Has(CapV1) {
+		err := c.restoreVersionV1(ctx, pageID, n, message)
+		if err == nil {
+			return c.GetPageContext(ctx, pageID, false)
+		}
+		if !endpointMissing(err) {
+			return nil, err
+		}
+	}
+
+	old, err := c.GetPageVersionContext(ctx, pageID, n, true)
+	if err != nil {
+		return nil, fmt.Errorf("reading version %d: %w", n, err)
+	}
+	if c.Has(CapV2) {
+		return c.updatePageV2(ctx, pageID, old.Title, storageValue(old), message, currentVersion+1)
+	}
+	return c.updatePageV1(ctx, pageID, old.Title, storageValue(old), message, currentVersion+1)
+}
+
+// restoreVersionV1 asks Cloud to restore version n itself, title included.
+func (c *Client) restoreVersionV1(ctx context.Context, pageID string, n int, message string) error {
+	req := map[string]any{
+		"operationKey": "restore",
+		"params": map[string]any{
+			"versionNumber": n,
+			"message":       message,
+			"restoreTitle":  true,
+		},
+	}
+	_, err := c.postV1(ctx, "content/"+pageID+"/version", req)
+	return err
+}
+
+// v1ToVersion maps a v1 version onto the v2 shape. Cloud identifies the
+// author by account ID, Server/DC by username.
+func v1ToVersion(v1 *V1Version) *Version {
+	v := &Version{
+		Number:    v1.Number,
+		Message:   v1.Message,
+		MinorEdit: v1.MinorEdit,
+		CreatedAt: v1.When,
+	}
+	if v1.By != nil {
+		v.AuthorID = v1.By.AccountID
+		if v.AuthorID == "" {
+			v.AuthorID = v1.By.Username
+		}
+	}
+	return v
+}
+
+// PageDiff compares two versions of a page, or a version and a local body.
+type PageDiff struct {
+	PageID      string `json:"pageId"`
+	FromVersion int    `json:"fromVersion"`
+	ToVersion   int    `json:"toVersion,omitempty"` // 0 when compared against a local body
+	*StorageDiff
+}
+
+// DiffPageVersions compares the storage bodies of two versions of a page.
+// A to of 0 means the current version and a from of 0 the one before to.
+func (c *Client) DiffPageVersions(pageID string, from, to int, normalize bool) (*PageDiff, error) {
+	return c.DiffPageVersionsContext(context.Background(), pageID, from, to, normalize)
+}
+
+// DiffPageVersionsContext is like DiffPageVersions but honors ctx for cancellation and deadlines.
+func (c *Client) DiffPageVersionsContext(ctx context.Context, pageID string, from, to int, normalize bool) (*PageDiff, error) {
+	newer, err := c.pageForDiff(ctx, pageID, to)
+	if err != nil {
+		return nil, err
+	}
+	to = newer.Version.Number
+	if from == 0 {
+		from = to - 1
+	}
+	if from < 1 {
+		return nil, fmt.Errorf("confluence: page %s has no version before %d", pageID, to)
+	}
+	older, err := c.GetPageVersionContext(ctx, pageID, from, true)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := DiffStorage(storageValue(older), storageValue(newer), normalize)
+	if err != nil {
+		return nil, err
+	}
+	return &PageDiff{PageID: pageID, FromVersion: from, ToVersion: to, StorageDiff: d}, nil
+}
+
+// DiffPageBody compares a version of a page (0 for the current one) with a
+// storage body that hasn't been published, such as a local file.
+func (c *Client) DiffPageBody(pageID string, version int, body string, normalize bool) (*PageDiff, error) {
+	return c.DiffPageBodyContext(context.Background(), pageID, version, body, normalize)
+}
+
+// DiffPageBodyContext is like DiffPageBody but honors ctx for cancellation and deadlines.
+func (c *Client) DiffPageBodyContext(ctx context.Context, pageID string, version int, body string, normalize bool) (*PageDiff, error) {
+	page, err := c.pageForDiff(ctx, pageID, version)
+	if err != nil {
+		return nil, err
+	}
+	d, err := DiffStorage(storageValue(page), body, normalize)
+	if err != nil {
+		return nil, err
+	}
+	return &PageDiff{PageID: pageID, FromVersion: page.Version.Number, StorageDiff: d}, nil
+}
+
+// pageForDiff fetches version n of a page with its body, or the current
+// version when n is 0.
+func (c *Client) pageForDiff(ctx context.Context, pageID string, n int) (*Page, error) {
+	var page *Page
+	var err error
+	if n == 0 {
+		page, err = c.GetPageContext(ctx, pageID, true)
+	} else {
+		page, err = c.GetPageVersionContext(ctx, pageID, n, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if page.Version == nil {
+		return nil, fmt.Errorf("confluence: page %s came back without a version number", pageID)
+	}
+	return page, nil
+}
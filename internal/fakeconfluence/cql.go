@@ -0,0 +1,378 @@
+package fakeconfluence
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A small CQL subset: clauses on type, space, title, text, label, id,
+// parent, ancestor and creator, combined with AND, OR, NOT and parentheses,
+// plus ORDER BY title, created, lastmodified or id. "~" matches when every
+// term of the value occurs in the field, ignoring case and "*" wildcards.
+
+type cqlQuery struct {
+	where   cqlNode // nil matches everything
+	orderBy string
+	desc    bool
+}
+
+type cqlNode interface {
+	match(s *Server, p *page) bool
+}
+
+type cqlAnd struct{ left, right cqlNode }
+type cqlOr struct{ left, right cqlNode }
+type cqlNot struct{ inner cqlNode }
+
+type cqlClause struct {
+	field  string
+	op     string // =, !=, ~, !~, in, not in
+	values []string
+}
+
+func (n cqlAnd) match(s *Server, p *page) bool { return n.left.match(s, p) && n.right.match(s, p) }
+func (n cqlOr) match(s *Server, p *page) bool  { return n.left.match(s, p) || n.right.match(s, p) }
+func (n cqlNot) match(s *Server, p *page) bool { return !n.inner.match(s, p) }
+
+var cqlFields = map[string]string{
+	"type": "type", "space": "space", "space.key": "space", "title": "title", "text": "text",
+	"label": "label", "labeltext": "label", "id": "id", "content": "id", "parent": "parent",
+	"ancestor": "ancestor", "creator": "creator",
+}
+
+func (c cqlClause) match(s *Server, p *page) bool {
+	negate := c.op == "!=" || c.op == "!~" || c.op == "not in"
+	fuzzy := c.op == "~" || c.op == "!~"
+
+	found := false
+	for _, v := range c.values {
+		if c.matchValue(s, p, v, fuzzy) {
+			found = true
+			break
+		}
+	}
+	return found != negate
+}
+
+func (c cqlClause) matchValue(s *Server, p *page, v string, fuzzy bool) bool {
+	cur := p.current()
+	switch c.field {
+	case "type":
+		return strings.EqualFold(v, p.Type)
+	case "space":
+		sp := s.spaceByID(p.SpaceID)
+		return sp != nil && strings.EqualFold(sp.Key, v)
+	case "title":
+		if fuzzy {
+			return containsTerms(cur.Title, v)
+		}
+		return strings.EqualFold(cur.Title, v)
+	case "text":
+		return containsTerms(cur.Title+" "+plainText(cur.Body), v)
+	case "label":
+		return p.hasLabel(v)
+	case "id":
+		return strconv.Itoa(p.ID) == v
+	case "parent":
+		return strconv.Itoa(p.ParentID) == v
+	case "ancestor":
+		for _, a := range s.ancestors(p) {
+			if strconv.Itoa(a.ID) == v {
+				return true
+			}
+		}
+		return false
+	case "creator":
+		return p.AuthorID == v
+	}
+	return false
+}
+
+// containsTerms reports whether every whitespace-separated term of query
+// occurs in text, case-insensitively.
+func containsTerms(text, query string) bool {
+	text = strings.ToLower(text)
+	terms := strings.Fields(strings.ToLower(strings.ReplaceAll(query, "*", " ")))
+	if len(terms) == 0 {
+		return false
+	}
+	for _, term := range terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// plainText strips storage-format markup down to its text.
+func plainText(storage string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(tagPattern.ReplaceAllString(storage, " "))), " ")
+}
+
+// excerpt is the first stretch of a page's text, as search results show it.
+func excerpt(storage string) string {
+	text := []rune(plainText(storage))
+	if len(text) <= 160 {
+		return string(text)
+	}
+	return strings.TrimSpace(string(text[:160])) + "..."
+}
+
+// evalCQL returns the current pages matching q, in q's order.
+func (s *Server) evalCQL(q *cqlQuery) []*page {
+	var out []*page
+	for _, id := range s.order {
+		p := s.pages[id]
+		if p.Status == "current" && (q.where == nil || q.where.match(s, p)) {
+			out = append(out, p)
+		}
+	}
+	if q.orderBy == "" {
+		return out
+	}
+	slices.SortStableFunc(out, func(a, b *page) int {
+		var cmp int
+		switch q.orderBy {
+		case "title":
+			cmp = strings.Compare(strings.ToLower(a.current().Title), strings.ToLower(b.current().Title))
+		case "created":
+			cmp = a.CreatedAt.Compare(b.CreatedAt)
+		case "lastmodified":
+			cmp = a.current().When.Compare(b.current().When)
+		default:
+			cmp = a.ID - b.ID
+		}
+		if q.desc {
+			return -cmp
+		}
+		return cmp
+	})
+	return out
+}
+
+// --- parser ---
+
+type cqlParser struct {
+	tokens []string
+	pos    int
+}
+
+func parseCQL(input string) (*cqlQuery, error) {
+	tokens, err := tokenizeCQL(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &cqlParser{tokens: tokens}
+	q := &cqlQuery{}
+	if !strings.EqualFold(p.peek(), "order") {
+		if q.where, err = p.or(); err != nil {
+			return nil, err
+		}
+	}
+	if p.keyword("order") {
+		if !p.keyword("by") {
+			return nil, fmt.Errorf("expected BY after ORDER")
+		}
+		field := strings.ToLower(p.next())
+		switch field {
+		case "title", "created", "lastmodified", "id":
+		default:
+			return nil, fmt.Errorf("cannot order by %q", field)
+		}
+		q.orderBy = field
+		if p.keyword("desc") {
+			q.desc = true
+		} else {
+			p.keyword("asc")
+		}
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	return q, nil
+}
+
+func (p *cqlParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *cqlParser) next() string {
+	t := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+	return t
+}
+
+// keyword consumes the next token when it is the (unquoted) keyword kw.
+func (p *cqlParser) keyword(kw string) bool {
+	if strings.EqualFold(p.peek(), kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *cqlParser) or() (cqlNode, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = cqlOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *cqlParser) and() (cqlNode, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = cqlAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *cqlParser) unary() (cqlNode, error) {
+	if p.keyword("not") {
+		inner, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return cqlNot{inner}, nil
+	}
+	if p.peek() == "(" {
+		p.pos++
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return inner, nil
+	}
+	return p.clause()
+}
+
+func (p *cqlParser) clause() (cqlNode, error) {
+	raw := p.next()
+	field, ok := cqlFields[strings.ToLower(raw)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported field %q", raw)
+	}
+
+	op := p.next()
+	switch {
+	case op == "=" || op == "!=" || op == "~" || op == "!~":
+	case strings.EqualFold(op, "in"):
+		op = "in"
+	case strings.EqualFold(op, "not") && p.keyword("in"):
+		op = "not in"
+	default:
+		return nil, fmt.Errorf("unsupported operator %q after %s", op, raw)
+	}
+	if (op == "~" || op == "!~") && field != "title" && field != "text" {
+		return nil, fmt.Errorf("operator %s is not supported for %s", op, raw)
+	}
+
+	c := cqlClause{field: field, op: op}
+	if op == "in" || op == "not in" {
+		if p.next() != "(" {
+			return nil, fmt.Errorf("expected a list after %s", op)
+		}
+		for {
+			c.values = append(c.values, unquote(p.next()))
+			sep := p.next()
+			if sep == ")" {
+				break
+			}
+			if sep != "," {
+				return nil, fmt.Errorf("malformed list")
+			}
+		}
+		return c, nil
+	}
+
+	value := p.next()
+	if value == "" {
+		return nil, fmt.Errorf("missing value for %s", raw)
+	}
+	c.values = []string{unquote(value)}
+	return c, nil
+}
+
+// tokenizeCQL splits input into words, quoted strings (kept with their
+// quotes), operators, parentheses and commas.
+func tokenizeCQL(input string) ([]string, error) {
+	var tokens []string
+	r := []rune(input)
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '=' || c == '~':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '!' && i+1 < len(r) && (r[i+1] == '=' || r[i+1] == '~'):
+			tokens = append(tokens, string(r[i:i+2]))
+			i += 2
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(r) && r[j] != c {
+				if r[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(r) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, string(r[i:j+1]))
+			i = j + 1
+		default:
+			j := i
+			for j < len(r) && !unicode.IsSpace(r[j]) && !strings.ContainsRune("()=~!,\"'", r[j]) {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("unexpected %q", string(c))
+			}
+			tokens = append(tokens, string(r[i:j]))
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+func unquote(token string) string {
+	if len(token) >= 2 && (token[0] == '"' || token[0] == '\'') && token[len(token)-1] == token[0] {
+		inner := token[1 : len(token)-1]
+		return strings.NewReplacer(`\"`, `"`, `\'`, `'`, `\\`, `\`).Replace(inner)
+	}
+	return token
+}
@@ -0,0 +1,255 @@
+package fakeconfluence
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Seed is the initial content of a fake site, usually loaded from YAML:
+//
+//	spaces:
+//	  - key: DEV
+//	    name: Development
+//	    pages:
+//	      - title: Home
+//	        body: <p>Welcome</p>
+//	        labels: [start]
+//	        children:
+//	          - title: Runbook
+//	            history:
+//	              - body: <p>First draft</p>
+//	            body: <p>Current text</p>
+//	    blogposts:
+//	      - title: Release 2.0 is out
+//	        body: <p>Highlights</p>
+type Seed struct {
+	Spaces []SeedSpace `yaml:"spaces"`
+}
+
+// SeedSpace is a space, its page tree and its blog posts. The first
+// top-level page becomes the space homepage.
+type SeedSpace struct {
+	ID          int        `yaml:"id,omitempty"` // assigned when 0
+	Key         string     `yaml:"key"`
+	Name        string     `yaml:"name,omitempty"`
+	Type        string     `yaml:"type,omitempty"` // "global" (default) or "personal"
+	Description string     `yaml:"description,omitempty"`
+	Pages       []SeedPage `yaml:"pages,omitempty"`
+	BlogPosts   []SeedPage `yaml:"blogposts,omitempty"` // without children
+}
+
+// SeedPage is a page with its labels, earlier versions and children.
+type SeedPage struct {
+	ID       int           `yaml:"id,omitempty"` // assigned when 0
+	Title    string        `yaml:"title"`
+	Body     string        `yaml:"body,omitempty"` // storage format, latest version
+	Labels   []string      `yaml:"labels,omitempty"`
+	Author   string        `yaml:"author,omitempty"`  // account ID or username
+	History  []SeedVersion `yaml:"history,omitempty"` // earlier versions, oldest first
+	Children []SeedPage    `yaml:"children,omitempty"`
+}
+
+// SeedVersion is an earlier version of a seeded page.
+type SeedVersion struct {
+	Title   string `yaml:"title,omitempty"` // defaults to the page title
+	Body    string `yaml:"body"`
+	Message string `yaml:"message,omitempty"`
+}
+
+//go:embed demo_seed.yaml
+var demoSeed []byte
+
+// DemoSeed returns the built-in sample site used when no seed file is given.
+func DemoSeed() *Seed {
+	seed, err := ParseSeed(demoSeed)
+	if err != nil {
+		panic("fakeconfluence: invalid demo seed: " + err.Error())
+	}
+	return seed
+}
+
+// LoadSeed reads a YAML seed file.
+func LoadSeed(path string) (*Seed, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading seed: %w", err)
+	}
+	seed, err := ParseSeed(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return seed, nil
+}
+
+// ParseSeed decodes YAML seed data. Unknown keys are rejected so typos
+// don't silently drop content.
+func ParseSeed(data []byte) (*Seed, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var seed Seed
+	if err := dec.Decode(&seed); err != nil {
+		return nil, fmt.Errorf("parsing seed: %w", err)
+	}
+	return &seed, nil
+}
+
+// load adds seed's spaces and pages to the store. Explicit IDs are claimed
+// first so generated ones never collide with them.
+func (s *Server) load(seed *Seed) error {
+	claimed := map[int]bool{}
+	claim := func(id int, what string) error {
+		if id == 0 {
+			return nil
+		}
+		if claimed[id] {
+			return fmt.Errorf("seed: duplicate ID %d (%s)", id, what)
+		}
+		claimed[id] = true
+		return nil
+	}
+	var claimPages func([]SeedPage) error
+	claimPages = func(pages []SeedPage) error {
+		for _, p := range pages {
+			if err := claim(p.ID, "page "+p.Title); err != nil {
+				return err
+			}
+			if err := claimPages(p.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, sp := range seed.Spaces {
+		if err := claim(sp.ID, "space "+sp.Key); err != nil {
+			return err
+		}
+		if err := claimPages(sp.Pages); err != nil {
+			return err
+		}
+		if err := claimPages(sp.BlogPosts); err != nil {
+			return err
+		}
+	}
+	nextFree := func() int {
+		for {
+			id := s.newID()
+			if !claimed[id] {
+				return id
+			}
+		}
+	}
+
+	for _, seedSpace := range seed.Spaces {
+		key := strings.TrimSpace(seedSpace.Key)
+		if key == "" {
+			return fmt.Errorf("seed: space without a key")
+		}
+		if s.spaceByKey(key) != nil {
+			return fmt.Errorf("seed: duplicate space key %s", key)
+		}
+		sp := &space{
+			ID:          seedSpace.ID,
+			Key:         key,
+			Name:        seedSpace.Name,
+			Type:        seedSpace.Type,
+			Description: seedSpace.Description,
+		}
+		if sp.ID == 0 {
+			sp.ID = nextFree()
+		}
+		if sp.Name == "" {
+			sp.Name = key
+		}
+		if sp.Type == "" {
+			sp.Type = "global"
+		}
+		s.spaces = append(s.spaces, sp)
+
+		for i, seedPage := range seedSpace.Pages {
+			p, err := s.loadPage(sp, 0, "page", seedPage, nextFree)
+			if err != nil {
+				return err
+			}
+			if i == 0 {
+				sp.HomepageID = p.ID
+			}
+		}
+	}
+
+	// Blog posts come after every page, so adding some to a seed doesn't
+	// renumber the pages of later spaces.
+	for _, seedSpace := range seed.Spaces {
+		sp := s.spaceByKey(strings.TrimSpace(seedSpace.Key))
+		for _, seedPost := range seedSpace.BlogPosts {
+			if len(seedPost.Children) > 0 {
+				return fmt.Errorf("seed: blog post %q in space %s has children", seedPost.Title, sp.Key)
+			}
+			if _, err := s.loadPage(sp, 0, "blogpost", seedPost, nextFree); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// loadPage adds a seeded page or blog post (typ) and its children.
+func (s *Server) loadPage(sp *space, parentID int, typ string, seedPage SeedPage, nextFree func() int) (*page, error) {
+	if strings.TrimSpace(seedPage.Title) == "" {
+		return nil, fmt.Errorf("seed: %s without a title in space %s", typ, sp.Key)
+	}
+	if typ == "page" && s.titleTaken(sp.ID, seedPage.Title, 0) {
+		return nil, fmt.Errorf("seed: duplicate page title %q in space %s", seedPage.Title, sp.Key)
+	}
+	author := seedPage.Author
+	if author == "" {
+		author = defaultAuthor
+	}
+
+	// Spread earlier versions out an hour apart, ending now.
+	now := s.now()
+	created := now.Add(-time.Duration(len(seedPage.History)) * time.Hour)
+	p := &page{
+		ID:        seedPage.ID,
+		Type:      typ,
+		SpaceID:   sp.ID,
+		ParentID:  parentID,
+		Status:    "current",
+		AuthorID:  author,
+		CreatedAt: created,
+	}
+	if p.ID == 0 {
+		p.ID = nextFree()
+	}
+	for i, h := range seedPage.History {
+		title := h.Title
+		if title == "" {
+			title = seedPage.Title
+		}
+		p.Versions = append(p.Versions, pageVersion{
+			Number: i + 1, Title: title, Body: h.Body, Message: h.Message,
+			AuthorID: author, When: created.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	p.Versions = append(p.Versions, pageVersion{
+		Number: len(p.Versions) + 1, Title: seedPage.Title, Body: seedPage.Body,
+		AuthorID: author, When: now,
+	})
+	s.pages[p.ID] = p
+	s.order = append(s.order, p.ID)
+
+	for _, name := range seedPage.Labels {
+		p.addLabel(nextFree(), strings.ToLower(name), "global")
+	}
+	for _, child := range seedPage.Children {
+		if _, err := s.loadPage(sp, p.ID, typ, child, nextFree); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,670 @@
+// Package fakeconfluence serves an in-memory Confluence for local integration
+// testing. It implements the v2 (Cloud) and v1 (Server/DC and Cloud search)
+// REST endpoints the confluence client calls, with realistic payload shapes,
+// version checks and error responses.
+//
+//	srv, _ := fakeconfluence.New(seed, fakeconfluence.Options{})
+//	ts := httptest.NewServer(srv)
+//	// Cloud client: BaseURL ts.URL+"/wiki"; Server/DC client: BaseURL ts.URL.
+//
+// Under /wiki the server behaves like Cloud (v2, v1 with account IDs,
+// systemInfo with a cloud ID, native version restore and page copy); at the
+// site root like Server/DC (v1 with usernames, an application links manifest
+// with the product version). One server can stand in for either deployment
+// type. Blog posts live in the same store as pages, served by the v2
+// /blogposts endpoints and as v1 content of type blogpost.
+package fakeconfluence
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options tunes the fake server's behavior.
+type Options struct {
+	// Token, when set, must be presented as a Bearer token or as the password
+	// of Basic credentials; other requests get 401.
+	Token string
+	// RateLimit is the number of requests answered per second before the
+	// server starts replying 429 with Retry-After. 0 disables limiting.
+	RateLimit int
+	// Now returns the current time; nil uses time.Now.
+	Now func() time.Time
+}
+
+// Server is an in-memory Confluence. It is safe for concurrent use; requests
+// are handled one at a time.
+type Server struct {
+	opts Options
+	mux  *http.ServeMux
+
+	mu       sync.Mutex
+	spaces   []*space
+	pages    map[int]*page
+	order    []int // page IDs in creation order
+	nextID   int
+	injected []int // statuses to answer the next requests with
+	window   time.Time
+	served   int // requests in the current rate-limit window
+}
+
+type space struct {
+	ID          int
+	Key         string
+	Name        string
+	Type        string
+	Description string
+	HomepageID  int
+}
+
+type page struct {
+	ID        int
+	Type      string // "page" or "blogpost"
+	SpaceID   int
+	ParentID  int           // 0 for top-level pages
+	Status    string        // "current", "draft" (never published) or "trashed"
+	Versions  []pageVersion // Versions[n-1] is version n; a draft page has only its draft
+	Draft     *pageVersion  // unpublished edits of a current page (v2 only)
+	Labels    []label
+	Props     []*property // in creation order
+	AuthorID  string
+	CreatedAt time.Time
+}
+
+type pageVersion struct {
+	Number   int
+	Title    string
+	Body     string
+	Message  string
+	AuthorID string
+	When     time.Time
+}
+
+type label struct {
+	ID     int
+	Name   string
+	Prefix string
+}
+
+// property is a content property. Its Version carries only the number,
+// author and time; Title and Body stay empty.
+type property struct {
+	ID      int
+	Key     string
+	Value   json.RawMessage
+	Version pageVersion
+}
+
+// defaultAuthor is the account ID writes are attributed to.
+const defaultAuthor = "557058:fake-user"
+
+// Identity reported by systemInfo (Cloud) and the applinks manifest (Server/DC).
+const (
+	fakeServerVersion = "8.5.4"
+	fakeServerBuild   = "9012"
+	fakeCloudID       = "00000000-fake-c10d-0000-000000000000"
+)
+
+// New returns a server loaded with seed, which may be nil for an empty site.
+func New(seed *Seed, opts Options) (*Server, error) {
+	s := &Server{
+		opts:   opts,
+		mux:    http.NewServeMux(),
+		pages:  map[int]*page{},
+		nextID: 65536,
+	}
+	if seed != nil {
+		if err := s.load(seed); err != nil {
+			return nil, err
+		}
+	}
+	s.routes()
+	return s, nil
+}
+
+// FailNext makes the next n requests fail with status (e.g. 429, 500, 503),
+// after authentication. Useful for exercising retry handling.
+func (s *Server) FailNext(status, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < n; i++ {
+		s.injected = append(s.injected, status)
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v2 := strings.Contains(r.URL.Path, "/api/v2/")
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Confluence"`)
+		writeError(w, v2, http.StatusUnauthorized, "Unauthorized", "Authentication credentials are missing or invalid")
+		return
+	}
+	if len(s.injected) > 0 {
+		status := s.injected[0]
+		s.injected = s.injected[1:]
+		if status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable {
+			w.Header().Set("Retry-After", "1")
+		}
+		writeError(w, v2, status, http.StatusText(status), "Injected failure")
+		return
+	}
+	if s.rateLimited() {
+		w.Header().Set("Retry-After", "1")
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(s.opts.RateLimit))
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		writeError(w, v2, http.StatusTooManyRequests, "Too Many Requests", "Rate limit exceeded")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) now() time.Time {
+	if s.opts.Now != nil {
+		return s.opts.Now().UTC()
+	}
+	return time.Now().UTC()
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if s.opts.Token == "" {
+		return true
+	}
+	if _, password, ok := r.BasicAuth(); ok {
+		return password == s.opts.Token
+	}
+	return r.Header.Get("Authorization") == "Bearer "+s.opts.Token
+}
+
+// rateLimited counts r against a fixed one-second window.
+func (s *Server) rateLimited() bool {
+	if s.opts.RateLimit <= 0 {
+		return false
+	}
+	now := s.now()
+	if now.Sub(s.window) >= time.Second {
+		s.window, s.served = now, 0
+	}
+	s.served++
+	return s.served > s.opts.RateLimit
+}
+
+// routes registers every endpoint at the site root and under /wiki. Handlers
+// receive the prefix so the links they return resolve against the same base.
+func (s *Server) routes() {
+	for _, prefix := range []string{"", "/wiki"} {
+		v2 := func(pattern string, h func(http.ResponseWriter, *http.Request, string)) {
+			if prefix == "" {
+				return // Server/DC has no v2
+			}
+			method, path, _ := strings.Cut(pattern, " ")
+			s.mux.HandleFunc(method+" "+prefix+"/api/v2"+path, func(w http.ResponseWriter, r *http.Request) { h(w, r, prefix) })
+		}
+		v1 := func(pattern string, h func(http.ResponseWriter, *http.Request, string)) {
+			method, path, _ := strings.Cut(pattern, " ")
+			s.mux.HandleFunc(method+" "+prefix+"/rest/api"+path, func(w http.ResponseWriter, r *http.Request) { h(w, r, prefix) })
+		}
+
+		v2("GET /spaces", s.v2ListSpaces)
+		v2("GET /spaces/{id}", s.v2GetSpace)
+		v2("GET /pages", s.v2ListPages)
+		v2("POST /pages", s.v2CreatePage)
+		v2("GET /pages/{id}", s.v2GetPage)
+		v2("PUT /pages/{id}", s.v2UpdatePage)
+		v2("DELETE /pages/{id}", s.v2DeletePage)
+		v2("GET /pages/{id}/children", s.v2Children)
+		v2("GET /pages/{id}/ancestors", s.v2Ancestors)
+		v2("GET /pages/{id}/versions", s.v2Versions)
+		v2("GET /pages/{id}/labels", s.v2Labels)
+		v2("POST /pages/{id}/labels", s.v2AddLabels)
+		v2("DELETE /pages/{id}/labels/{labelID}", s.v2RemoveLabel)
+		v2("GET /pages/{id}/properties", s.v2Properties)
+		v2("POST /pages/{id}/properties", s.v2AddProperty)
+		v2("GET /pages/{id}/properties/{propertyID}", s.v2GetProperty)
+		v2("PUT /pages/{id}/properties/{propertyID}", s.v2UpdateProperty)
+		v2("DELETE /pages/{id}/properties/{propertyID}", s.v2DeleteProperty)
+		v2("GET /blogposts", s.v2ListBlogPosts)
+		v2("POST /blogposts", s.v2CreateBlogPost)
+		v2("GET /blogposts/{id}", s.v2GetBlogPost)
+		v2("PUT /blogposts/{id}", s.v2UpdateBlogPost)
+		v2("DELETE /blogposts/{id}", s.v2DeleteBlogPost)
+
+		v1("GET /space", s.v1ListSpaces)
+		v1("GET /space/{key}", s.v1GetSpace)
+		v1("GET /content", s.v1ListContent)
+		v1("POST /content", s.v1CreateContent)
+		v1("GET /content/search", s.v1ContentSearch)
+		v1("GET /content/{id}", s.v1GetContent)
+		v1("PUT /content/{id}", s.v1UpdateContent)
+		v1("DELETE /content/{id}", s.v1DeleteContent)
+		v1("GET /content/{id}/child/page", s.v1Children)
+		v1("GET /content/{id}/version", s.v1Versions)
+		v1("PUT /content/{id}/move/{position}/{targetID}", s.v1MovePage)
+		v1("GET /content/{id}/label", s.v1Labels)
+		v1("POST /content/{id}/label", s.v1AddLabels)
+		v1("DELETE /content/{id}/label/{name}", s.v1RemoveLabel)
+		v1("GET /content/{id}/property", s.v1Properties)
+		v1("POST /content/{id}/property", s.v1AddProperty)
+		v1("GET /content/{id}/property/{key}", s.v1GetProperty)
+		v1("PUT /content/{id}/property/{key}", s.v1UpdateProperty)
+		v1("DELETE /content/{id}/property/{key}", s.v1DeleteProperty)
+		v1("GET /search", s.v1Search)
+	}
+	s.mux.HandleFunc("GET /wiki/rest/api/settings/systemInfo", s.systemInfo)
+	s.mux.HandleFunc("POST /wiki/rest/api/content/{id}/version", func(w http.ResponseWriter, r *http.Request) { s.v1RestoreVersion(w, r, "/wiki") })
+	s.mux.HandleFunc("POST /wiki/rest/api/content/{id}/copy", func(w http.ResponseWriter, r *http.Request) { s.v1CopyPage(w, r, "/wiki") })
+	s.mux.HandleFunc("GET /rest/applinks/1.0/manifest", s.manifest)
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, strings.Contains(r.URL.Path, "/api/v2/"), http.StatusNotFound, "Not Found", "No route for "+r.Method+" "+r.URL.Path)
+	})
+}
+
+// --- store helpers ---
+
+// newID returns the next unused ID. Spaces, pages and labels share one sequence.
+func (s *Server) newID() int {
+	for {
+		s.nextID++
+		if s.pages[s.nextID] == nil && s.spaceByID(s.nextID) == nil {
+			return s.nextID
+		}
+	}
+}
+
+func (s *Server) spaceByID(id int) *space {
+	for _, sp := range s.spaces {
+		if sp.ID == id {
+			return sp
+		}
+	}
+	return nil
+}
+
+func (s *Server) spaceByKey(key string) *space {
+	for _, sp := range s.spaces {
+		if sp.Key == key {
+			return sp
+		}
+	}
+	return nil
+}
+
+// liveContent returns the page or blog post with the given ID unless it is
+// missing or trashed.
+func (s *Server) liveContent(rawID string) *page {
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		return nil
+	}
+	p := s.pages[id]
+	if p == nil || p.Status != "current" {
+		return nil
+	}
+	return p
+}
+
+// livePage is liveContent restricted to pages.
+func (s *Server) livePage(rawID string) *page {
+	if p := s.liveContent(rawID); p != nil && p.Type == "page" {
+		return p
+	}
+	return nil
+}
+
+// liveBlogPost is liveContent restricted to blog posts.
+func (s *Server) liveBlogPost(rawID string) *page {
+	if p := s.liveContent(rawID); p != nil && p.Type == "blogpost" {
+		return p
+	}
+	return nil
+}
+
+// pageInStatus returns the page with the given ID if its status is status,
+// e.g. "trashed" or "draft".
+func (s *Server) pageInStatus(rawID, status string) *page {
+	id, _ := strconv.Atoi(rawID)
+	if p := s.pages[id]; p != nil && p.Type == "page" && p.Status == status {
+		return p
+	}
+	return nil
+}
+
+// spacePages returns the current pages of a space in creation order.
+func (s *Server) spacePages(spaceID int) []*page {
+	var out []*page
+	for _, id := range s.order {
+		if p := s.pages[id]; p.Type == "page" && p.SpaceID == spaceID && p.Status == "current" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func (s *Server) children(parentID int) []*page {
+	var out []*page
+	for _, id := range s.order {
+		if p := s.pages[id]; p.Type == "page" && p.ParentID == parentID && p.Status == "current" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// ancestors returns p's ancestors from the root down to its parent.
+func (s *Server) ancestors(p *page) []*page {
+	var chain []*page
+	for id := p.ParentID; id != 0; {
+		parent := s.pages[id]
+		if parent == nil {
+			break
+		}
+		chain = append([]*page{parent}, chain...)
+		id = parent.ParentID
+	}
+	return chain
+}
+
+// titleTaken reports whether another current page in the space has title.
+func (s *Server) titleTaken(spaceID int, title string, except int) bool {
+	for _, p := range s.spacePages(spaceID) {
+		if p.ID != except && strings.EqualFold(p.current().Title, title) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) addPage(sp *space, parentID int, title, body, author string) *page {
+	now := s.now()
+	p := &page{
+		ID:        s.newID(),
+		Type:      "page",
+		SpaceID:   sp.ID,
+		ParentID:  parentID,
+		Status:    "current",
+		AuthorID:  author,
+		CreatedAt: now,
+		Versions:  []pageVersion{{Number: 1, Title: title, Body: body, AuthorID: author, When: now}},
+	}
+	s.pages[p.ID] = p
+	s.order = append(s.order, p.ID)
+	return p
+}
+
+func (s *Server) addBlogPost(sp *space, title, body, author string) *page {
+	p := s.addPage(sp, 0, title, body, author)
+	p.Type = "blogpost"
+	return p
+}
+
+// move places p relative to target: "append" makes it target's last child,
+// "before" and "after" its sibling. p's subtree follows it into target's space.
+func (s *Server) move(p *page, position string, target *page) {
+	if position == "append" {
+		p.ParentID = target.ID
+	} else {
+		p.ParentID = target.ParentID
+	}
+	s.setSpace(p, target.SpaceID)
+
+	s.order = slices.DeleteFunc(s.order, func(id int) bool { return id == p.ID })
+	switch position {
+	case "append":
+		s.order = append(s.order, p.ID)
+	case "before":
+		s.order = slices.Insert(s.order, slices.Index(s.order, target.ID), p.ID)
+	case "after":
+		s.order = slices.Insert(s.order, slices.Index(s.order, target.ID)+1, p.ID)
+	}
+}
+
+func (s *Server) setSpace(p *page, spaceID int) {
+	p.SpaceID = spaceID
+	for _, child := range s.children(p.ID) {
+		s.setSpace(child, spaceID)
+	}
+}
+
+// subtree returns p and its current descendants.
+func (s *Server) subtree(p *page) []*page {
+	out := []*page{p}
+	for _, child := range s.children(p.ID) {
+		out = append(out, s.subtree(child)...)
+	}
+	return out
+}
+
+// within reports whether p is root or one of its descendants.
+func (s *Server) within(p, root *page) bool {
+	for q := p; q != nil; q = s.pages[q.ParentID] {
+		if q.ID == root.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// moveTitleClash returns a title in p's subtree that is already taken in
+// the space with spaceID, or "".
+func (s *Server) moveTitleClash(p *page, spaceID int) string {
+	if p.SpaceID == spaceID {
+		return ""
+	}
+	for _, q := range s.subtree(p) {
+		if title := q.current().Title; s.titleTaken(spaceID, title, q.ID) {
+			return title
+		}
+	}
+	return ""
+}
+
+// update appends a new version, discarding any pending draft; callers check
+// the version number first.
+func (p *page) update(title, body, message, author string, when time.Time) {
+	cur := p.current()
+	if title == "" {
+		title = cur.Title
+	}
+	p.Draft = nil
+	p.Versions = append(p.Versions, pageVersion{
+		Number:   cur.Number + 1,
+		Title:    title,
+		Body:     body,
+		Message:  message,
+		AuthorID: author,
+		When:     when,
+	})
+}
+
+// saveDraft stores unpublished edits: a draft page's only version is
+// replaced, a current page gets a pending draft of its next version.
+func (p *page) saveDraft(title, body, message, author string, when time.Time) {
+	draft := pageVersion{Number: 1, Title: title, Body: body, Message: message, AuthorID: author, When: when}
+	if p.Status == "draft" {
+		p.Versions[0] = draft
+		return
+	}
+	draft.Number = p.current().Number + 1
+	p.Draft = &draft
+}
+
+// publish makes a draft page current, as version 1.
+func (p *page) publish(title, body, message, author string, when time.Time) {
+	p.Status = "current"
+	p.Versions[0] = pageVersion{Number: 1, Title: title, Body: body, Message: message, AuthorID: author, When: when}
+}
+
+func (p *page) current() pageVersion {
+	return p.Versions[len(p.Versions)-1]
+}
+
+// version returns version n of p, or false when it doesn't exist.
+func (p *page) version(n int) (pageVersion, bool) {
+	if n < 1 || n > len(p.Versions) {
+		return pageVersion{}, false
+	}
+	return p.Versions[n-1], true
+}
+
+func (p *page) addLabel(id int, name, prefix string) {
+	for _, l := range p.Labels {
+		if l.Name == name {
+			return
+		}
+	}
+	if prefix == "" {
+		prefix = "global"
+	}
+	p.Labels = append(p.Labels, label{ID: id, Name: name, Prefix: prefix})
+}
+
+// property returns p's property with key, or nil.
+func (p *page) property(key string) *property {
+	for _, pr := range p.Props {
+		if pr.Key == key {
+			return pr
+		}
+	}
+	return nil
+}
+
+func (p *page) deleteProperty(pr *property) {
+	p.Props = slices.DeleteFunc(p.Props, func(q *property) bool { return q == pr })
+}
+
+// propertyRequest is the body of v2 and v1 property writes.
+type propertyRequest struct {
+	Key     string          `json:"key"`
+	Value   json.RawMessage `json:"value"`
+	Version *struct {
+		Number  int    `json:"number"`
+		Message string `json:"message"`
+	} `json:"version"`
+}
+
+// invalid describes what is wrong with a property create (update false) or
+// update request, or returns "".
+func (req propertyRequest) invalid(update bool) string {
+	switch {
+	case strings.TrimSpace(req.Key) == "":
+		return "Property key is required"
+	case len(req.Key) > 255:
+		return "Property key must be at most 255 characters"
+	case len(req.Value) == 0 || !json.Valid(req.Value):
+		return "Property value must be JSON"
+	case update && (req.Version == nil || req.Version.Number == 0):
+		return "Version number is required"
+	}
+	return ""
+}
+
+// addProperty stores a new property at version 1.
+func (s *Server) addProperty(p *page, req propertyRequest) *property {
+	pr := &property{ID: s.newID(), Key: req.Key, Value: req.Value}
+	pr.Version = pageVersion{Number: 1, AuthorID: defaultAuthor, When: s.now()}
+	p.Props = append(p.Props, pr)
+	return pr
+}
+
+// updateProperty replaces pr's value as its next version; callers check
+// the version number first.
+func (s *Server) updateProperty(pr *property, req propertyRequest) {
+	pr.Value = req.Value
+	pr.Version = pageVersion{Number: pr.Version.Number + 1, Message: req.Version.Message, AuthorID: defaultAuthor, When: s.now()}
+}
+
+func (p *page) hasLabel(name string) bool {
+	for _, l := range p.Labels {
+		if strings.EqualFold(l.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// webPath is the page's web UI path, as Cloud renders it.
+func webPath(sp *space, p *page) string {
+	key := ""
+	if sp != nil {
+		key = sp.Key
+	}
+	kind := "/pages/"
+	if p.Type == "blogpost" {
+		kind = "/blog/" + p.CreatedAt.Format("2006/01/02") + "/"
+	}
+	return "/spaces/" + key + kind + strconv.Itoa(p.ID) + "/" + strings.ReplaceAll(p.current().Title, " ", "+")
+}
+
+// --- responses ---
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+}
+
+// writeError answers in the v2 errors[] shape or the v1 statusCode/message shape.
+func writeError(w http.ResponseWriter, v2 bool, status int, title, detail string) {
+	if v2 {
+		writeJSON(w, status, map[string]any{
+			"errors": []map[string]any{{
+				"status": status,
+				"code":   strings.ToUpper(strings.ReplaceAll(http.StatusText(status), " ", "_")),
+				"title":  title,
+				"detail": detail,
+			}},
+		})
+		return
+	}
+	writeJSON(w, status, map[string]any{
+		"statusCode": status,
+		"data": map[string]any{
+			"authorized": status != http.StatusUnauthorized && status != http.StatusForbidden,
+			"valid":      status != http.StatusBadRequest,
+			"errors":     []any{},
+			"successful": false,
+		},
+		"message": detail,
+		"reason":  http.StatusText(status),
+	})
+}
+
+// queryInt reads a non-negative integer query parameter, falling back to def.
+func queryInt(r *http.Request, name string, def int) (int, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// splitList reads a query parameter given either repeated or comma-separated.
+func splitList(r *http.Request, name string) []string {
+	var out []string
+	for _, v := range r.URL.Query()[name] {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
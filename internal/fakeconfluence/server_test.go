@@ -0,0 +1,905 @@
+package fakeconfluence
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relux-works/skill-confluence-management/internal/confluence"
+)
+
+// newFake starts a fake loaded with the demo seed and returns a client of
+// the given type pointed at it.
+func newFake(t *testing.T, instanceType confluence.InstanceType, opts Options) (*Server, *confluence.Client) {
+	t.Helper()
+	srv, err := New(DemoSeed(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	cfg := confluence.Config{
+		BaseURL:      ts.URL,
+		Token:        "tok",
+		InstanceType: instanceType,
+		AuthType:     confluence.AuthBearer,
+		RetryPolicy:  &confluence.RetryPolicy{MaxAttempts: 1},
+	}
+	if instanceType == confluence.InstanceCloud {
+		cfg.BaseURL += "/wiki"
+		cfg.Email = "agent@example.com"
+		cfg.AuthType = confluence.AuthBasic
+	}
+	client, err := confluence.NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, client
+}
+
+func pageByTitle(t *testing.T, pages []confluence.Page, title string) confluence.Page {
+	t.Helper()
+	for _, p := range pages {
+		if p.Title == title {
+			return p
+		}
+	}
+	t.Fatalf("no page %q in %+v", title, pages)
+	return confluence.Page{}
+}
+
+func TestCloudPageLifecycle(t *testing.T) {
+	_, client := newFake(t, confluence.InstanceCloud, Options{})
+
+	pages, err := client.ListPages("DEV", "", 0)
+	if err != nil {
+		t.Fatalf("ListPages: %v", err)
+	}
+	if len(pages) != 4 {
+		t.Fatalf("got %d DEV pages, want 4", len(pages))
+	}
+	checklist := pageByTitle(t, pages, "Release Checklist")
+
+	page, err := client.GetPage(checklist.ID, true)
+	if err != nil {
+		t.Fatalf("GetPage: %v", err)
+	}
+	if page.Version.Number != 3 || !strings.Contains(page.Body.Storage.Value, "Rollback") {
+		t.Errorf("page = %+v", page)
+	}
+
+	ancestors, err := client.GetAncestors(checklist.ID)
+	if err != nil || len(ancestors) != 1 || ancestors[0].ID != page.ParentID {
+		t.Errorf("ancestors = %+v, err = %v (parent %s)", ancestors, err, page.ParentID)
+	}
+	children, err := client.GetChildren(checklist.ID, 0)
+	if err != nil || len(children) != 1 || children[0].Title != "Hotfix Procedure" {
+		t.Errorf("children = %+v, err = %v", children, err)
+	}
+
+	created, err := client.CreatePage("DEV", "Postmortems", "<p>Blameless.</p>", checklist.ID)
+	if err != nil {
+		t.Fatalf("CreatePage: %v", err)
+	}
+	if created.Version.Number != 1 || created.ParentID != checklist.ID {
+		t.Errorf("created = %+v", created)
+	}
+	if _, err := client.CreatePage("DEV", "Postmortems", "", ""); !errors.Is(err, confluence.ErrValidation) {
+		t.Errorf("duplicate title: err = %v, want ErrValidation", err)
+	}
+
+	// A stale edit based on version 2 merges cleanly with the Rollback section added in 3.
+	stale := "<h2>Before the release</h2><ul><li><p>Freeze the release branch</p></li><li><p>Run the full regression suite</p></li><li><p>Tag the build</p></li></ul>"
+	merged, err := client.UpdatePageWithOptions(checklist.ID, "", stale, "Tag step", confluence.UpdateOptions{ExpectVersion: 2, Merge: true})
+	if err != nil {
+		t.Fatalf("merge update: %v", err)
+	}
+	if merged.Version.Number != 4 || !strings.Contains(merged.Body.Storage.Value, "Tag the build") || !strings.Contains(merged.Body.Storage.Value, "Rollback") {
+		t.Errorf("merged = %+v", merged.Body.Storage.Value)
+	}
+
+	if err := client.AddLabels(created.ID, []string{"Retro"}); err != nil {
+		t.Fatalf("AddLabels: %v", err)
+	}
+	if err := client.RemoveLabel(created.ID, "retro"); err != nil {
+		t.Fatalf("RemoveLabel: %v", err)
+	}
+	if labels, _ := client.GetLabels(created.ID); len(labels) != 0 {
+		t.Errorf("labels after removal = %+v", labels)
+	}
+
+	if err := client.DeletePage(created.ID); err != nil {
+		t.Fatalf("DeletePage: %v", err)
+	}
+	if _, err := client.GetPage(created.ID, false); !errors.Is(err, confluence.ErrNotFound) {
+		t.Errorf("trashed page: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestCloudRejectsStaleVersion(t *testing.T) {
+	srv, client := newFake(t, confluence.InstanceCloud, Options{})
+	pages, _ := client.ListPages("OPS", "Incident Process", 0)
+	if len(pages) != 1 {
+		t.Fatalf("pages = %+v", pages)
+	}
+
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	req, _ := http.NewRequest(http.MethodPut, ts.URL+"/wiki/api/v2/pages/"+pages[0].ID,
+		strings.NewReader(`{"id":"`+pages[0].ID+`","status":"current","title":"Incident Process","version":{"number":5}}`))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Errors []confluence.ErrorDetail `json:"errors"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != http.StatusConflict || len(body.Errors) != 1 || !strings.Contains(body.Errors[0].Detail, "Current version is: 1") {
+		t.Errorf("got %d %+v", resp.StatusCode, body)
+	}
+}
+
+func TestServerPageLifecycle(t *testing.T) {
+	_, client := newFake(t, confluence.InstanceServer, Options{})
+
+	spaces, err := client.ListSpaces(0)
+	if err != nil || len(spaces) != 2 {
+		t.Fatalf("spaces = %+v, err = %v", spaces, err)
+	}
+
+	result, err := client.SearchCQL(`space = OPS and label = incident`, 0)
+	if err != nil {
+		t.Fatalf("SearchCQL: %v", err)
+	}
+	if len(result.Results) != 1 || result.TotalSize != 1 || result.Results[0].SpaceKey() != "OPS" {
+		t.Fatalf("search = %+v", result)
+	}
+	id := result.Results[0].Content.ID
+
+	page, err := client.GetPage(id, true)
+	if err != nil {
+		t.Fatalf("GetPage: %v", err)
+	}
+	if page.SpaceKey != "OPS" || page.Version.Number != 1 || page.Labels == nil || page.Labels.Results[0].Name != "incident" {
+		t.Errorf("page = %+v", page)
+	}
+	ancestors, err := client.GetAncestors(id)
+	if err != nil || len(ancestors) != 1 || ancestors[0].Title != "Operations Home" {
+		t.Errorf("ancestors = %+v, err = %v", ancestors, err)
+	}
+
+	updated, err := client.UpdatePage(id, "", "<p>Open a bridge.</p>", "Trim")
+	if err != nil {
+		t.Fatalf("UpdatePage: %v", err)
+	}
+	if updated.Version.Number != 2 || updated.Title != "Incident Process" {
+		t.Errorf("updated = %+v", updated)
+	}
+	if _, err := client.UpdatePageWithOptions(id, "", "<p>x</p>", "", confluence.UpdateOptions{ExpectVersion: 1}); !errors.Is(err, confluence.ErrVersionConflict) {
+		t.Errorf("stale update: err = %v, want ErrVersionConflict", err)
+	}
+
+	created, err := client.CreatePage("OPS", "Severity Levels", "<p>SEV1 to SEV4.</p>", id)
+	if err != nil {
+		t.Fatalf("CreatePage: %v", err)
+	}
+	children, err := client.GetChildren(id, 0)
+	if err != nil || len(children) != 1 || children[0].ID != created.ID {
+		t.Errorf("children = %+v, err = %v", children, err)
+	}
+
+	if err := client.AddLabels(created.ID, []string{"sev"}); err != nil {
+		t.Fatalf("AddLabels: %v", err)
+	}
+	found, err := client.SearchContentCQL(`label in (sev, nothing) and ancestor = `+id, 0, "")
+	if err != nil || len(found) != 1 {
+		t.Errorf("content search = %+v, err = %v", found, err)
+	}
+
+	if err := client.DeletePage(id); err != nil {
+		t.Fatalf("DeletePage: %v", err)
+	}
+	moved, err := client.GetAncestors(created.ID)
+	if err != nil || len(moved) != 1 || moved[0].Title != "Operations Home" {
+		t.Errorf("child of deleted page should move up: %+v, err = %v", moved, err)
+	}
+}
+
+func TestPaginationLinks(t *testing.T) {
+	srv, err := New(DemoSeed(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	get := func(url string, v any) {
+		t.Helper()
+		resp, err := http.Get(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: %d", url, resp.StatusCode)
+		}
+		json.NewDecoder(resp.Body).Decode(v)
+	}
+
+	var titles []string
+	next := "/wiki/api/v2/pages?limit=2"
+	for next != "" {
+		var page confluence.CursorPage[confluence.Page]
+		get(ts.URL+next, &page)
+		for _, p := range page.Results {
+			titles = append(titles, p.Title)
+		}
+		next = ""
+		if page.HasMore() {
+			next = page.Links.Next
+		}
+	}
+	if len(titles) != 6 {
+		t.Errorf("v2 walk returned %d pages: %v", len(titles), titles)
+	}
+
+	var first confluence.OffsetPage[confluence.SearchResultItem]
+	get(ts.URL+"/wiki/rest/api/search?cql=type%3Dpage&limit=5", &first)
+	if first.Size != 5 || first.TotalSize != 6 || first.Links == nil || !strings.HasPrefix(first.Links.Next, "/rest/api/search?") {
+		t.Fatalf("first search page = %+v", first)
+	}
+	var second confluence.OffsetPage[confluence.SearchResultItem]
+	get(ts.URL+"/wiki"+first.Links.Next, &second)
+	if second.Start != 5 || second.Size != 1 || second.HasMore() {
+		t.Errorf("second search page = %+v", second)
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	t.Run("unauthorized", func(t *testing.T) {
+		_, client := newFake(t, confluence.InstanceServer, Options{Token: "secret"})
+		if _, err := client.ListSpaces(0); !errors.Is(err, confluence.ErrUnauthorized) {
+			t.Errorf("err = %v, want ErrUnauthorized", err)
+		}
+	})
+
+	t.Run("injected 429", func(t *testing.T) {
+		srv, client := newFake(t, confluence.InstanceCloud, Options{})
+		srv.FailNext(http.StatusTooManyRequests, 1)
+		_, err := client.ListSpaces(0)
+		var apiErr *confluence.APIError
+		if !errors.Is(err, confluence.ErrRateLimited) || !errors.As(err, &apiErr) || apiErr.RetryAfter != time.Second {
+			t.Errorf("err = %v, want ErrRateLimited with Retry-After 1s", err)
+		}
+		if _, err := client.ListSpaces(0); err != nil {
+			t.Errorf("second call: %v", err)
+		}
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		_, client := newFake(t, confluence.InstanceServer, Options{RateLimit: 2, Now: func() time.Time { return now }})
+		for i := 0; i < 2; i++ {
+			if _, err := client.GetSpace("DEV"); err != nil {
+				t.Fatalf("request %d: %v", i, err)
+			}
+		}
+		if _, err := client.GetSpace("DEV"); !errors.Is(err, confluence.ErrRateLimited) {
+			t.Errorf("err = %v, want ErrRateLimited", err)
+		}
+		now = now.Add(time.Second)
+		if _, err := client.GetSpace("DEV"); err != nil {
+			t.Errorf("next window: %v", err)
+		}
+	})
+
+	t.Run("bad cql", func(t *testing.T) {
+		_, client := newFake(t, confluence.InstanceServer, Options{})
+		if _, err := client.SearchCQL(`lastmodified > now("-1d")`, 0); !errors.Is(err, confluence.ErrValidation) {
+			t.Errorf("err = %v, want ErrValidation", err)
+		}
+	})
+}
+
+func TestParseCQL(t *testing.T) {
+	srv, err := New(DemoSeed(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		cql  string
+		want []string
+	}{
+		{`space = DEV and label = release`, []string{"Release Checklist"}},
+		{`text ~ "rollback" or title ~ "runbook"`, []string{"Release Checklist", "On-call Runbook"}},
+		{`space = "OPS" and not title = "Operations Home"`, []string{"Incident Process"}},
+		{`label in (oncall, incident) order by title desc`, []string{"On-call Runbook", "Incident Process"}},
+		{`type = page and (label = home or label = oncall) and space != OPS`, []string{"Development Home", "On-call Runbook"}},
+	}
+	for _, tt := range tests {
+		q, err := parseCQL(tt.cql)
+		if err != nil {
+			t.Errorf("%s: %v", tt.cql, err)
+			continue
+		}
+		var got []string
+		for _, p := range srv.evalCQL(q) {
+			got = append(got, p.current().Title)
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s = %v, want %v", tt.cql, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{`title =`, `space = DEV and`, `label in (a, b`, `created > 2024-01-01`, `title ~ "open`} {
+		if _, err := parseCQL(bad); err == nil {
+			t.Errorf("%s: expected a parse error", bad)
+		}
+	}
+}
+
+func TestParseSeed(t *testing.T) {
+	if _, err := ParseSeed([]byte("spaces:\n  - key: A\n    pagez: []\n")); err == nil {
+		t.Error("unknown keys should be rejected")
+	}
+
+	seed, err := ParseSeed([]byte(`
+spaces:
+  - key: A
+    id: 10
+    pages:
+      - title: One
+        id: 11
+      - title: Two
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := New(seed, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sp := srv.spaceByKey("A"); sp == nil || sp.ID != 10 || sp.HomepageID != 11 {
+		t.Errorf("space = %+v", sp)
+	}
+
+	seed.Spaces[0].Pages[1].ID = 10
+	if _, err := New(seed, Options{}); err == nil || !strings.Contains(err.Error(), "duplicate ID 10") {
+		t.Errorf("err = %v, want duplicate ID", err)
+	}
+}
+
+func TestDetectInstance(t *testing.T) {
+	_, cloud := newFake(t, confluence.InstanceCloud, Options{})
+	info, err := cloud.DetectInstance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Type != confluence.InstanceCloud || !info.Has(confluence.CapV2) || !info.Has(confluence.CapContentProperties) {
+		t.Errorf("/wiki detected as %+v", info)
+	}
+
+	_, server := newFake(t, confluence.InstanceServer, Options{})
+	info, err = server.DetectInstance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Type != confluence.InstanceServer || info.Has(confluence.CapV2) || !info.Has(confluence.CapContentProperties) || info.Version != fakeServerVersion {
+		t.Errorf("site root detected as %+v", info)
+	}
+}
+
+func TestPageHistory(t *testing.T) {
+	for _, instanceType := range []confluence.InstanceType{confluence.InstanceCloud, confluence.InstanceServer} {
+		t.Run(string(instanceType), func(t *testing.T) {
+			_, client := newFake(t, instanceType, Options{})
+			pages, err := client.ListPages("DEV", "Release Checklist", 0)
+			if err != nil || len(pages) != 1 {
+				t.Fatalf("ListPages: %v %v", pages, err)
+			}
+			id := pages[0].ID
+
+			versions, err := client.ListPageVersions(id, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(versions) != 3 || versions[0].Number != 3 || versions[2].Message != "First cut" {
+				t.Fatalf("versions = %+v, want 3..1", versions)
+			}
+
+			first, err := client.GetPageVersion(id, 1, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if first.Version.Number != 1 || first.Body == nil || strings.Contains(first.Body.Storage.Value, "regression") {
+				t.Errorf("version 1 = %+v", first)
+			}
+		})
+	}
+}
+
+func TestRestorePageVersion(t *testing.T) {
+	for _, instanceType := range []confluence.InstanceType{confluence.InstanceCloud, confluence.InstanceServer} {
+		t.Run(string(instanceType), func(t *testing.T) {
+			_, client := newFake(t, instanceType, Options{})
+			pages, err := client.ListPages("DEV", "Release Checklist", 0)
+			if err != nil || len(pages) != 1 {
+				t.Fatalf("ListPages: %v %v", pages, err)
+			}
+			id := pages[0].ID
+
+			_, err = client.RestorePageVersion(id, 1, confluence.RestoreOptions{ExpectVersion: 2})
+			if !errors.Is(err, confluence.ErrVersionConflict) {
+				t.Fatalf("stale ExpectVersion: err = %v, want version conflict", err)
+			}
+
+			restored, err := client.RestorePageVersion(id, 1, confluence.RestoreOptions{ExpectVersion: 3})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if restored.Version.Number != 4 {
+				t.Errorf("restored version = %d, want 4", restored.Version.Number)
+			}
+			latest, err := client.GetPage(id, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(latest.Body.Storage.Value, "regression") || latest.Version.Message != "Restored version 1" {
+				t.Errorf("after restore: body %q, message %q", latest.Body.Storage.Value, latest.Version.Message)
+			}
+		})
+	}
+}
+
+func TestResponseCacheAfterLabelsAndMoves(t *testing.T) {
+	for _, instanceType := range []confluence.InstanceType{confluence.InstanceCloud, confluence.InstanceServer} {
+		t.Run(string(instanceType), func(t *testing.T) {
+			srv, err := New(DemoSeed(), Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ts := httptest.NewServer(srv)
+			t.Cleanup(ts.Close)
+			cfg := confluence.Config{
+				BaseURL:       ts.URL,
+				Token:         "tok",
+				InstanceType:  instanceType,
+				AuthType:      confluence.AuthBearer,
+				RetryPolicy:   &confluence.RetryPolicy{MaxAttempts: 1},
+				ResponseCache: confluence.NewResponseCache(t.TempDir()),
+			}
+			if instanceType == confluence.InstanceCloud {
+				cfg.BaseURL += "/wiki"
+			}
+			client, err := confluence.NewClient(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dev, err := client.ListPages("DEV", "", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			home := pageByTitle(t, dev, "Development Home")
+			hotfix := pageByTitle(t, dev, "Hotfix Procedure")
+			if _, err := client.GetPage(hotfix.ID, true); err != nil {
+				t.Fatal(err)
+			}
+
+			// Neither change makes a new version, so a cached page must not hide them.
+			if err := client.AddLabels(hotfix.ID, []string{"cached"}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.MovePage(hotfix.ID, confluence.MoveAppend, home.ID); err != nil {
+				t.Fatal(err)
+			}
+			page, err := client.GetPage(hotfix.ID, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if page.ParentID != home.ID || page.Body == nil || page.Body.Storage == nil {
+				t.Errorf("page after move = %+v, want parent %s with body", page, home.ID)
+			}
+			if instanceType == confluence.InstanceServer {
+				if page.Labels == nil || !slices.ContainsFunc(page.Labels.Results, func(l confluence.Label) bool { return l.Name == "cached" }) {
+					t.Errorf("labels after add = %+v", page.Labels)
+				}
+			}
+		})
+	}
+}
+
+func TestMovePage(t *testing.T) {
+	for _, instanceType := range []confluence.InstanceType{confluence.InstanceCloud, confluence.InstanceServer} {
+		t.Run(string(instanceType), func(t *testing.T) {
+			_, client := newFake(t, instanceType, Options{})
+			dev, err := client.ListPages("DEV", "", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			home := pageByTitle(t, dev, "Development Home")
+			release := pageByTitle(t, dev, "Release Checklist")
+			hotfix := pageByTitle(t, dev, "Hotfix Procedure")
+			oncall := pageByTitle(t, dev, "On-call Runbook")
+
+			moved, err := client.MovePage(hotfix.ID, confluence.MoveBefore, release.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(moved.Ancestors) != 1 || moved.Ancestors[0].ID != home.ID || moved.Page.ParentID != home.ID {
+				t.Errorf("ancestors after move = %+v, parent %s", moved.Ancestors, moved.Page.ParentID)
+			}
+			children, err := client.GetChildren(home.ID, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var order []string
+			for _, c := range children {
+				order = append(order, c.Title)
+			}
+			if got := strings.Join(order, ", "); got != "Hotfix Procedure, Release Checklist, On-call Runbook" {
+				t.Errorf("children order = %s", got)
+			}
+
+			ops, err := client.ListPages("OPS", "", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opsHome := pageByTitle(t, ops, "Operations Home")
+			moved, err = client.MovePage(oncall.ID, confluence.MoveAppend, opsHome.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if moved.Page.SpaceID != opsHome.SpaceID || len(moved.Warnings) != 0 {
+				t.Errorf("cross-space move: space %s, warnings %v", moved.Page.SpaceID, moved.Warnings)
+			}
+
+			clash, err := client.CreatePage("DEV", "Incident Process", "<p>dup</p>", home.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = client.MovePage(clash.ID, confluence.MoveAppend, opsHome.ID)
+			if err == nil || !strings.Contains(err.Error(), `space OPS already has a page titled "Incident Process"`) {
+				t.Errorf("title collision: err = %v", err)
+			}
+
+			// The subtree moves too, so a descendant's title can collide as well.
+			notes, err := client.CreatePage("DEV", "Escalation Notes", "<p>notes</p>", home.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := client.DeletePage(clash.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.CreatePage("DEV", "Incident Process", "<p>dup</p>", notes.ID); err != nil {
+				t.Fatal(err)
+			}
+			_, err = client.MovePage(notes.ID, confluence.MoveAppend, opsHome.ID)
+			if err == nil || !strings.Contains(err.Error(), `space OPS already has a page titled "Incident Process"`) {
+				t.Errorf("descendant title collision: err = %v", err)
+			}
+		})
+	}
+}
+
+func TestCopyPage(t *testing.T) {
+	for _, instanceType := range []confluence.InstanceType{confluence.InstanceCloud, confluence.InstanceServer} {
+		t.Run(string(instanceType), func(t *testing.T) {
+			_, client := newFake(t, instanceType, Options{})
+			dev, err := client.ListPages("DEV", "", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			home := pageByTitle(t, dev, "Development Home")
+			release := pageByTitle(t, dev, "Release Checklist")
+			hotfix := pageByTitle(t, dev, "Hotfix Procedure")
+
+			if _, err := client.UpdatePage(release.ID, "", `<p>See <ac:link><ri:page ri:content-title="Hotfix Procedure" /></ac:link>.</p>`, ""); err != nil {
+				t.Fatal(err)
+			}
+			hotfixBody := `<p><a href="/pages/viewpage.action?pageId=` + release.ID + `">Up</a> and <ac:link><ri:page ri:content-title="On-call Runbook" /></ac:link></p>`
+			if _, err := client.UpdatePage(hotfix.ID, "", hotfixBody, ""); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := client.CopyPage(release.ID, hotfix.ID, confluence.CopyOptions{Recursive: true}); err == nil {
+				t.Error("copying a page into its own subtree succeeded")
+			}
+
+			res, err := client.CopyPage(release.ID, home.ID, confluence.CopyOptions{Recursive: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(res.IDs) != 2 || res.RootID != res.IDs[release.ID] {
+				t.Fatalf("result = %+v", res)
+			}
+			root, err := client.GetPage(res.RootID, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if root.Title != "Copy of Release Checklist" || !strings.Contains(root.Body.Storage.Value, `ri:content-title="Copy of Hotfix Procedure"`) {
+				t.Errorf("root copy: title %q, body %q", root.Title, root.Body.Storage.Value)
+			}
+			labels, err := client.GetLabels(res.RootID)
+			if err != nil || len(labels) != 2 {
+				t.Errorf("root copy labels = %+v, %v", labels, err)
+			}
+			child, err := client.GetPage(res.IDs[hotfix.ID], true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if child.ParentID != res.RootID || !strings.Contains(child.Body.Storage.Value, "pageId="+res.RootID+`"`) ||
+				!strings.Contains(child.Body.Storage.Value, `<ri:page ri:content-title="On-call Runbook" />`) {
+				t.Errorf("child copy: parent %s, body %q", child.ParentID, child.Body.Storage.Value)
+			}
+
+			ops, err := client.ListPages("OPS", "", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opsHome := pageByTitle(t, ops, "Operations Home")
+			res, err = client.CopyPage(hotfix.ID, opsHome.ID, confluence.CopyOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			copied, err := client.GetPage(res.RootID, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if copied.Title != "Hotfix Procedure" || !strings.Contains(copied.Body.Storage.Value, `<ri:page ri:space-key="DEV" ri:content-title="On-call Runbook" />`) {
+				t.Errorf("cross-space copy: title %q, body %q", copied.Title, copied.Body.Storage.Value)
+			}
+		})
+	}
+}
+
+func TestTrash(t *testing.T) {
+	for _, instanceType := range []confluence.InstanceType{confluence.InstanceCloud, confluence.InstanceServer} {
+		t.Run(string(instanceType), func(t *testing.T) {
+			_, client := newFake(t, instanceType, Options{})
+			dev, err := client.ListPages("DEV", "", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			home := pageByTitle(t, dev, "Development Home")
+			release := pageByTitle(t, dev, "Release Checklist")
+			hotfix := pageByTitle(t, dev, "Hotfix Procedure")
+			oncall := pageByTitle(t, dev, "On-call Runbook")
+
+			manifest, err := client.DeletePageTree(release.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(manifest.IDs(), ","); got != release.ID+","+hotfix.ID || manifest.Pages[1].ParentID != release.ID {
+				t.Fatalf("manifest = %+v", manifest)
+			}
+			trashed, err := client.ListTrash("DEV", 0)
+			if err != nil || len(trashed) != 2 {
+				t.Fatalf("ListTrash = %+v, %v", trashed, err)
+			}
+
+			if err := client.PurgePage(oncall.ID); err == nil {
+				t.Error("purging a live page succeeded")
+			}
+			if _, err := client.RestoreFromTrash(oncall.ID); err == nil {
+				t.Error("restoring a live page succeeded")
+			}
+
+			for _, id := range manifest.IDs() {
+				if _, err := client.RestoreFromTrash(id); err != nil {
+					t.Fatal(err)
+				}
+			}
+			restored, err := client.GetPage(hotfix.ID, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ancestors, err := client.GetAncestors(hotfix.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ancestors) != 2 || ancestors[0].ID != home.ID || ancestors[1].ID != release.ID || restored.Status != "current" {
+				t.Errorf("restored hotfix: status %q, ancestors %+v", restored.Status, ancestors)
+			}
+
+			if err := client.DeletePage(oncall.ID); err != nil {
+				t.Fatal(err)
+			}
+			if err := client.PurgePage(oncall.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.RestoreFromTrash(oncall.ID); !errors.Is(err, confluence.ErrNotFound) {
+				t.Errorf("restoring a purged page: err = %v, want not found", err)
+			}
+			if trashed, err := client.ListTrash("DEV", 0); err != nil || len(trashed) != 0 {
+				t.Errorf("trash after restore and purge = %+v, %v", trashed, err)
+			}
+		})
+	}
+}
+
+func TestDrafts(t *testing.T) {
+	for _, instanceType := range []confluence.InstanceType{confluence.InstanceCloud, confluence.InstanceServer} {
+		t.Run(string(instanceType), func(t *testing.T) {
+			_, client := newFake(t, instanceType, Options{})
+			dev, err := client.ListPages("DEV", "", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			home := pageByTitle(t, dev, "Development Home")
+			oncall := pageByTitle(t, dev, "On-call Runbook")
+
+			draft, err := client.CreatePageWithOptions("DEV", "Q3 Plan", "<p>v0</p>", home.ID, confluence.CreateOptions{Draft: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if draft.Status != "draft" {
+				t.Errorf("created status = %q, want draft", draft.Status)
+			}
+			if pages, err := client.ListPages("DEV", "Q3 Plan", 0); err != nil || len(pages) != 0 {
+				t.Errorf("draft is listed as published: %+v, %v", pages, err)
+			}
+			if _, err := client.UpdatePageWithOptions(draft.ID, "", "<p>v1</p>", "", confluence.UpdateOptions{Draft: true}); err != nil {
+				t.Fatal(err)
+			}
+			drafts, err := client.ListDrafts("DEV", 0)
+			if err != nil || len(drafts) != 1 || drafts[0].ID != draft.ID {
+				t.Fatalf("ListDrafts = %+v, %v", drafts, err)
+			}
+
+			published, err := client.PublishPage(draft.ID, "Reviewed")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if published.Status != "current" || published.Version.Number != 1 {
+				t.Errorf("published: status %q, version %d", published.Status, published.Version.Number)
+			}
+			live, err := client.GetPage(draft.ID, true)
+			if err != nil || live.Body.Storage.Value != "<p>v1</p>" {
+				t.Fatalf("published page = %+v, %v", live, err)
+			}
+			if _, err := client.PublishPage(draft.ID, ""); err == nil {
+				t.Error("publishing a page without a draft succeeded")
+			}
+
+			// Edits to a published page can be staged only on Cloud.
+			_, err = client.UpdatePageWithOptions(oncall.ID, "", "<p>Staged</p>", "", confluence.UpdateOptions{Draft: true})
+			if instanceType == confluence.InstanceServer {
+				if err == nil {
+					t.Error("Server/DC staged a draft of a published page")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			current, err := client.GetPage(oncall.ID, true)
+			if err != nil || strings.Contains(current.Body.Storage.Value, "Staged") {
+				t.Fatalf("draft leaked into the published page: %+v, %v", current, err)
+			}
+			if _, err := client.PublishPage(oncall.ID, ""); err != nil {
+				t.Fatal(err)
+			}
+			current, err = client.GetPage(oncall.ID, true)
+			if err != nil || current.Body.Storage.Value != "<p>Staged</p>" || current.Version.Number != 2 {
+				t.Errorf("after publishing the pending draft: %+v, %v", current, err)
+			}
+		})
+	}
+}
+
+func TestBlogPosts(t *testing.T) {
+	for _, instanceType := range []confluence.InstanceType{confluence.InstanceCloud, confluence.InstanceServer} {
+		t.Run(string(instanceType), func(t *testing.T) {
+			_, client := newFake(t, instanceType, Options{})
+
+			seeded, err := client.ListBlogPosts("DEV", "", 0)
+			if err != nil || len(seeded) != 1 || seeded[0].Title != "Release 2.4 Retrospective" {
+				t.Fatalf("ListBlogPosts = %+v, %v", seeded, err)
+			}
+			pages, err := client.ListPages("DEV", "", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range pages {
+				if p.ID == seeded[0].ID {
+					t.Error("blog post listed among pages")
+				}
+			}
+			if _, err := client.GetBlogPost(pageByTitle(t, pages, "Development Home").ID, false); !errors.Is(err, confluence.ErrNotFound) {
+				t.Errorf("GetBlogPost on a page: %v, want ErrNotFound", err)
+			}
+
+			post, err := client.CreateBlogPost("DEV", "Sprint 12 Recap", "<p>Shipped</p>")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.UpdateBlogPost(post.ID, "", "<p>Shipped twice</p>", "typo"); err != nil {
+				t.Fatal(err)
+			}
+			got, err := client.GetBlogPost(post.ID, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Title != "Sprint 12 Recap" || got.Version.Number != 2 || got.Body.Storage.Value != "<p>Shipped twice</p>" {
+				t.Errorf("updated post = %+v", got)
+			}
+
+			found, err := client.SearchCQL(`type=blogpost AND space="DEV"`, 0)
+			if err != nil || len(found.Results) != 2 {
+				t.Errorf("CQL type=blogpost = %+v, %v", found, err)
+			}
+
+			if err := client.DeleteBlogPost(post.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.GetBlogPost(post.ID, false); !errors.Is(err, confluence.ErrNotFound) {
+				t.Errorf("deleted post: %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestPageProperties(t *testing.T) {
+	for _, instanceType := range []confluence.InstanceType{confluence.InstanceCloud, confluence.InstanceServer} {
+		t.Run(string(instanceType), func(t *testing.T) {
+			_, client := newFake(t, instanceType, Options{})
+			dev, err := client.ListPages("DEV", "", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			page := pageByTitle(t, dev, "Release Checklist")
+
+			if _, err := client.GetPageProperty(page.ID, "owner"); !errors.Is(err, confluence.ErrNotFound) {
+				t.Errorf("missing property: %v, want ErrNotFound", err)
+			}
+			created, err := client.SetPageProperty(page.ID, "owner", json.RawMessage(`{"team":"platform"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if created.Version == nil || created.Version.Number != 1 {
+				t.Errorf("created version = %+v, want 1", created.Version)
+			}
+			updated, err := client.SetPageProperty(page.ID, "owner", json.RawMessage(`{"team":"release-eng"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if updated.Version == nil || updated.Version.Number != 2 {
+				t.Errorf("updated version = %+v, want 2", updated.Version)
+			}
+			if _, err := client.SetPageProperty(page.ID, "reviewed", json.RawMessage(`"2026-11-01"`)); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.SetPageProperty(page.ID, "bad", json.RawMessage(`{not json`)); err == nil {
+				t.Error("invalid JSON value was accepted")
+			}
+
+			got, err := client.GetPageProperty(page.ID, "owner")
+			if err != nil || string(got.Value) != `{"team":"release-eng"}` {
+				t.Fatalf("GetPageProperty = %+v, %v", got, err)
+			}
+			props, err := client.ListPageProperties(page.ID)
+			if err != nil || len(props) != 2 || props[0].Key != "owner" || props[1].Key != "reviewed" {
+				t.Fatalf("ListPageProperties = %+v, %v", props, err)
+			}
+
+			if err := client.DeletePageProperty(page.ID, "owner"); err != nil {
+				t.Fatal(err)
+			}
+			if err := client.DeletePageProperty(page.ID, "owner"); !errors.Is(err, confluence.ErrNotFound) {
+				t.Errorf("deleting twice: %v, want ErrNotFound", err)
+			}
+			if props, err := client.ListPageProperties(page.ID); err != nil || len(props) != 1 {
+				t.Errorf("after delete: %+v, %v", props, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,810 @@
+package fakeconfluence
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/relux-works/skill-confluence-management/internal/confluence"
+)
+
+// v1 endpoints, as served by Server/DC under /rest/api and by Cloud under
+// /wiki/rest/api (where the client still uses them for CQL search).
+
+const (
+	v1DefaultLimit = 25
+	v1MaxLimit     = 100
+)
+
+// offsetPage is the v1 start/limit envelope. Search responses add
+// totalSize and cqlQuery.
+type offsetPage[T any] struct {
+	Results   []T               `json:"results"`
+	Start     int               `json:"start"`
+	Limit     int               `json:"limit"`
+	Size      int               `json:"size"`
+	TotalSize int               `json:"totalSize,omitempty"`
+	CQLQuery  string            `json:"cqlQuery,omitempty"`
+	Links     map[string]string `json:"_links"`
+}
+
+// offsetSlice cuts items to the request's start/limit window. The next link
+// is relative to the context path (/wiki on Cloud), as Confluence returns it.
+func offsetSlice[T any](w http.ResponseWriter, r *http.Request, prefix string, items []T) (offsetPage[T], bool) {
+	start, ok := queryInt(r, "start", 0)
+	limit, ok2 := queryInt(r, "limit", v1DefaultLimit)
+	if !ok || !ok2 {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "start and limit must be non-negative integers")
+		return offsetPage[T]{}, false
+	}
+	limit = min(max(limit, 1), v1MaxLimit)
+	start = min(start, len(items))
+	end := min(start+limit, len(items))
+
+	page := offsetPage[T]{
+		Results: append([]T{}, items[start:end]...),
+		Start:   start,
+		Limit:   limit,
+		Size:    end - start,
+		Links: map[string]string{
+			"base":    "http://" + r.Host + prefix,
+			"context": prefix,
+		},
+	}
+	if end < len(items) {
+		q := r.URL.Query()
+		q.Set("start", strconv.Itoa(end))
+		q.Set("limit", strconv.Itoa(limit))
+		page.Links["next"] = strings.TrimPrefix(r.URL.Path, prefix) + "?" + q.Encode()
+	}
+	return page, true
+}
+
+func writeOffsetPage[T any](w http.ResponseWriter, r *http.Request, prefix string, items []T) {
+	if page, ok := offsetSlice(w, r, prefix, items); ok {
+		writeJSON(w, http.StatusOK, page)
+	}
+}
+
+// expansions parses the expand parameter into a set.
+func expansions(r *http.Request) map[string]bool {
+	set := map[string]bool{}
+	for _, e := range splitList(r, "expand") {
+		set[e] = true
+	}
+	return set
+}
+
+func v1Space(sp *space) confluence.V1Space {
+	return confluence.V1Space{ID: sp.ID, Key: sp.Key, Name: sp.Name, Type: sp.Type}
+}
+
+// v1Version renders v in the v1 shape. Cloud (prefix /wiki) identifies users
+// by account ID, Server/DC by username.
+func v1Version(v pageVersion, prefix string) confluence.V1Version {
+	by := &confluence.V1User{DisplayName: v.AuthorID}
+	if prefix == "" {
+		by.Username = v.AuthorID
+	} else {
+		by.AccountID = v.AuthorID
+	}
+	return confluence.V1Version{Number: v.Number, Message: v.Message, When: formatTime(v.When), By: by}
+}
+
+// v1Content renders version v of p with the requested expansions.
+func (s *Server) v1Content(p *page, v pageVersion, expand map[string]bool, prefix string) confluence.V1Content {
+	sp := s.spaceByID(p.SpaceID)
+	out := confluence.V1Content{
+		ID:     strconv.Itoa(p.ID),
+		Type:   p.Type,
+		Status: p.Status,
+		Title:  v.Title,
+	}
+	if expand["space"] && sp != nil {
+		space := v1Space(sp)
+		out.Space = &space
+	}
+	if expand["version"] {
+		version := v1Version(v, prefix)
+		out.Version = &version
+	}
+	if expand["body.storage"] {
+		out.Body = &confluence.V1Body{Storage: &confluence.V1BodyContent{Value: v.Body, Representation: "storage"}}
+	}
+	if expand["ancestors"] {
+		out.Ancestors = []confluence.V1Content{}
+		for _, a := range s.ancestors(p) {
+			out.Ancestors = append(out.Ancestors, confluence.V1Content{
+				ID: strconv.Itoa(a.ID), Type: "page", Status: a.Status, Title: a.current().Title,
+			})
+		}
+	}
+	if expand["metadata.labels"] {
+		labels := v1Labels(p)
+		out.Metadata = &confluence.V1Metadata{Labels: &confluence.V1LabelResults{Results: labels, Limit: 200, Size: len(labels)}}
+	}
+
+	webui := "/display/" + sp.Key + "/" + strings.ReplaceAll(v.Title, " ", "+")
+	if prefix != "" {
+		webui = webPath(sp, p)
+	}
+	out.Links, _ = json.Marshal(map[string]string{
+		"webui": webui,
+		"self":  prefix + "/rest/api/content/" + out.ID,
+	})
+	return out
+}
+
+func v1Labels(p *page) []confluence.Label {
+	out := []confluence.Label{}
+	for _, l := range p.Labels {
+		out = append(out, confluence.Label{ID: strconv.Itoa(l.ID), Name: l.Name, Prefix: l.Prefix})
+	}
+	return out
+}
+
+func notFoundV1(w http.ResponseWriter, id string) {
+	writeError(w, false, http.StatusNotFound, "Not Found", "No content found with id: ContentId{id="+id+"}")
+}
+
+func (s *Server) v1ListSpaces(w http.ResponseWriter, r *http.Request, prefix string) {
+	keys, ids := splitList(r, "spaceKey"), splitList(r, "spaceId")
+	var out []confluence.V1Space
+	for _, sp := range s.spaces {
+		if len(keys) > 0 && !slices.Contains(keys, sp.Key) {
+			continue
+		}
+		if len(ids) > 0 && !slices.Contains(ids, strconv.Itoa(sp.ID)) {
+			continue
+		}
+		out = append(out, v1Space(sp))
+	}
+	writeOffsetPage(w, r, prefix, out)
+}
+
+func (s *Server) v1GetSpace(w http.ResponseWriter, r *http.Request, _ string) {
+	sp := s.spaceByKey(r.PathValue("key"))
+	if sp == nil {
+		writeError(w, false, http.StatusNotFound, "Not Found", "No space with key : "+r.PathValue("key"))
+		return
+	}
+	writeJSON(w, http.StatusOK, v1Space(sp))
+}
+
+func (s *Server) v1ListContent(w http.ResponseWriter, r *http.Request, prefix string) {
+	q := r.URL.Query()
+	typ, spaceKey, title := q.Get("type"), q.Get("spaceKey"), q.Get("title")
+	status := q.Get("status")
+	if status == "" {
+		status = "current"
+	}
+	expand := expansions(r)
+
+	if typ == "" {
+		typ = "page"
+	}
+
+	var out []confluence.V1Content
+	for _, id := range s.order {
+		p := s.pages[id]
+		if p.Type != typ || p.Status != status {
+			continue
+		}
+		if spaceKey != "" {
+			if sp := s.spaceByID(p.SpaceID); sp == nil || sp.Key != spaceKey {
+				continue
+			}
+		}
+		if title != "" && p.current().Title != title {
+			continue
+		}
+		out = append(out, s.v1Content(p, p.current(), expand, prefix))
+	}
+	writeOffsetPage(w, r, prefix, out)
+}
+
+func (s *Server) v1GetContent(w http.ResponseWriter, r *http.Request, prefix string) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	p := s.pages[id]
+	q := r.URL.Query()
+	if p == nil || (p.Status != "current" && q.Get("status") != p.Status) {
+		notFoundV1(w, r.PathValue("id"))
+		return
+	}
+	v := p.current()
+	if q.Get("status") == "historical" && q.Get("version") != "" {
+		n, _ := strconv.Atoi(q.Get("version"))
+		var ok bool
+		if v, ok = p.version(n); !ok {
+			notFoundV1(w, r.PathValue("id"))
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, s.v1Content(p, v, expansions(r), prefix))
+}
+
+// v1ContentRequest is the body of v1 content create and update calls.
+type v1ContentRequest struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	Space  *struct {
+		Key string `json:"key"`
+	} `json:"space"`
+	Ancestors []struct {
+		ID string `json:"id"`
+	} `json:"ancestors"`
+	Body *struct {
+		Storage *confluence.V1BodyContent `json:"storage"`
+	} `json:"body"`
+	Version *struct {
+		Number  int    `json:"number"`
+		Message string `json:"message"`
+	} `json:"version"`
+}
+
+func (req v1ContentRequest) storage() (string, bool) {
+	if req.Body == nil || req.Body.Storage == nil {
+		return "", false
+	}
+	return req.Body.Storage.Value, true
+}
+
+var writeExpand = map[string]bool{"space": true, "version": true, "body.storage": true, "ancestors": true}
+
+func (s *Server) v1CreateContent(w http.ResponseWriter, r *http.Request, prefix string) {
+	var req v1ContentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "Malformed request body: "+err.Error())
+		return
+	}
+	if req.Type != "page" && req.Type != "blogpost" {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "Unsupported content type: "+req.Type)
+		return
+	}
+	if req.Space == nil || s.spaceByKey(req.Space.Key) == nil {
+		key := ""
+		if req.Space != nil {
+			key = req.Space.Key
+		}
+		writeError(w, false, http.StatusNotFound, "Not Found", "No space with key : "+key)
+		return
+	}
+	sp := s.spaceByKey(req.Space.Key)
+	if strings.TrimSpace(req.Title) == "" {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "A title is required")
+		return
+	}
+	if req.Status != "" && req.Status != "current" && req.Status != "draft" {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "Unsupported status: "+req.Status)
+		return
+	}
+	if req.Type == "blogpost" {
+		if req.Status == "draft" || len(req.Ancestors) > 0 {
+			writeError(w, false, http.StatusBadRequest, "Bad Request", "Blog posts are published directly in a space")
+			return
+		}
+		body, _ := req.storage()
+		p := s.addBlogPost(sp, req.Title, body, defaultAuthor)
+		writeJSON(w, http.StatusOK, s.v1Content(p, p.current(), writeExpand, prefix))
+		return
+	}
+	if req.Status != "draft" && s.titleTaken(sp.ID, req.Title, 0) {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "A page with this title already exists: A page already exists with the title "+req.Title+" in the space with key "+sp.Key)
+		return
+	}
+	parentID := 0
+	if len(req.Ancestors) > 0 {
+		parent := s.livePage(req.Ancestors[len(req.Ancestors)-1].ID)
+		if parent == nil || parent.SpaceID != sp.ID {
+			writeError(w, false, http.StatusBadRequest, "Bad Request", "Invalid ancestor")
+			return
+		}
+		parentID = parent.ID
+	}
+	body, _ := req.storage()
+	p := s.addPage(sp, parentID, req.Title, body, defaultAuthor)
+	if req.Status == "draft" {
+		p.Status = "draft"
+	}
+	writeJSON(w, http.StatusOK, s.v1Content(p, p.current(), writeExpand, prefix))
+}
+
+// v1UpdateContent publishes a new version of a page or blog post. Updating a
+// trashed page to status current restores it. Drafts (addressed with
+// status=draft) are saved or published by v1UpdateDraft; published content
+// can't go back to draft.
+func (s *Server) v1UpdateContent(w http.ResponseWriter, r *http.Request, prefix string) {
+	p := s.liveContent(r.PathValue("id"))
+	if p == nil && r.URL.Query().Get("status") == "draft" {
+		p = s.pageInStatus(r.PathValue("id"), "draft")
+	}
+	trashed := p == nil
+	if trashed {
+		p = s.pageInStatus(r.PathValue("id"), "trashed")
+	}
+	if p == nil {
+		notFoundV1(w, r.PathValue("id"))
+		return
+	}
+	var req v1ContentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "Malformed request body: "+err.Error())
+		return
+	}
+	if trashed && req.Status != "current" {
+		notFoundV1(w, r.PathValue("id"))
+		return
+	}
+	if p.Status == "draft" {
+		s.v1UpdateDraft(w, p, req, prefix)
+		return
+	}
+	if req.Status == "draft" {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "Cannot change the status of published content to draft")
+		return
+	}
+	cur := p.current()
+	switch {
+	case req.Type != "" && req.Type != p.Type:
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "Cannot change the type of content "+r.PathValue("id")+" from "+p.Type+" to "+req.Type)
+		return
+	case req.Version == nil || req.Version.Number == 0:
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "Must supply an incremented version when updating Content. No version supplied.")
+		return
+	case req.Version.Number != cur.Number+1:
+		writeError(w, false, http.StatusConflict, "Conflict", "Version must be incremented on update. Current version is: "+strconv.Itoa(cur.Number))
+		return
+	case strings.TrimSpace(req.Title) == "":
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "A title is required")
+		return
+	case p.Type == "page" && s.titleTaken(p.SpaceID, req.Title, p.ID):
+		sp := s.spaceByID(p.SpaceID)
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "A page with this title already exists: A page already exists with the title "+req.Title+" in the space with key "+sp.Key)
+		return
+	}
+
+	body := cur.Body
+	if value, ok := req.storage(); ok {
+		body = value
+	}
+	if trashed {
+		s.restore(p)
+	}
+	p.update(req.Title, body, req.Version.Message, defaultAuthor, s.now())
+	writeJSON(w, http.StatusOK, s.v1Content(p, p.current(), writeExpand, prefix))
+}
+
+// v1UpdateDraft saves a draft page (status draft) or publishes it (status
+// current). Either way the version stays 1.
+func (s *Server) v1UpdateDraft(w http.ResponseWriter, p *page, req v1ContentRequest, prefix string) {
+	publishing := req.Status == "current"
+	switch {
+	case req.Version == nil || req.Version.Number != 1:
+		writeError(w, false, http.StatusConflict, "Conflict", "Version must be 1 when saving or publishing a draft")
+		return
+	case strings.TrimSpace(req.Title) == "":
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "A title is required")
+		return
+	case publishing && s.titleTaken(p.SpaceID, req.Title, p.ID):
+		sp := s.spaceByID(p.SpaceID)
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "A page with this title already exists: A page already exists with the title "+req.Title+" in the space with key "+sp.Key)
+		return
+	}
+
+	body := p.current().Body
+	if value, ok := req.storage(); ok {
+		body = value
+	}
+	if publishing {
+		p.publish(req.Title, body, req.Version.Message, defaultAuthor, s.now())
+	} else {
+		p.saveDraft(req.Title, body, req.Version.Message, defaultAuthor, s.now())
+	}
+	writeJSON(w, http.StatusOK, s.v1Content(p, p.current(), writeExpand, prefix))
+}
+
+// v1DeleteContent trashes a page; status=trashed purges one already in the trash.
+func (s *Server) v1DeleteContent(w http.ResponseWriter, r *http.Request, _ string) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	p := s.pages[id]
+	switch {
+	case p != nil && p.Status == "current":
+		s.trash(p)
+	case p != nil && p.Status == "trashed" && r.URL.Query().Get("status") == "trashed":
+		s.purge(p)
+	default:
+		notFoundV1(w, r.PathValue("id"))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) v1Children(w http.ResponseWriter, r *http.Request, prefix string) {
+	p := s.livePage(r.PathValue("id"))
+	if p == nil {
+		notFoundV1(w, r.PathValue("id"))
+		return
+	}
+	expand := expansions(r)
+	var out []confluence.V1Content
+	for _, child := range s.children(p.ID) {
+		out = append(out, s.v1Content(child, child.current(), expand, prefix))
+	}
+	writeOffsetPage(w, r, prefix, out)
+}
+
+func (s *Server) v1Versions(w http.ResponseWriter, r *http.Request, prefix string) {
+	p := s.liveContent(r.PathValue("id"))
+	if p == nil {
+		notFoundV1(w, r.PathValue("id"))
+		return
+	}
+	out := make([]confluence.V1Version, 0, len(p.Versions))
+	for i := len(p.Versions) - 1; i >= 0; i-- {
+		out = append(out, v1Version(p.Versions[i], prefix))
+	}
+	writeOffsetPage(w, r, prefix, out)
+}
+
+// v1MovePage implements the v1 move endpoint: position is append (last
+// child of the target), before or after (sibling of the target).
+func (s *Server) v1MovePage(w http.ResponseWriter, r *http.Request, _ string) {
+	p := s.livePage(r.PathValue("id"))
+	if p == nil {
+		notFoundV1(w, r.PathValue("id"))
+		return
+	}
+	target := s.livePage(r.PathValue("targetID"))
+	if target == nil {
+		notFoundV1(w, r.PathValue("targetID"))
+		return
+	}
+	position := r.PathValue("position")
+	switch {
+	case position != "append" && position != "before" && position != "after":
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "Invalid position: "+position)
+		return
+	case s.within(target, p):
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "Cannot move a page relative to itself or its descendants")
+		return
+	}
+	if title := s.moveTitleClash(p, target.SpaceID); title != "" {
+		sp := s.spaceByID(target.SpaceID)
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "A page with this title already exists: A page already exists with the title "+title+" in the space with key "+sp.Key)
+		return
+	}
+	s.move(p, position, target)
+	writeJSON(w, http.StatusOK, map[string]string{"pageId": strconv.Itoa(p.ID)})
+}
+
+// v1RestoreVersion implements Cloud's native restore: version n's title and
+// body become a new version.
+func (s *Server) v1RestoreVersion(w http.ResponseWriter, r *http.Request, prefix string) {
+	p := s.livePage(r.PathValue("id"))
+	if p == nil {
+		notFoundV1(w, r.PathValue("id"))
+		return
+	}
+	var req struct {
+		OperationKey string `json:"operationKey"`
+		Params       struct {
+			VersionNumber int    `json:"versionNumber"`
+			Message       string `json:"message"`
+			RestoreTitle  bool   `json:"restoreTitle"`
+		} `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "Malformed request body: "+err.Error())
+		return
+	}
+	if req.OperationKey != "restore" {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "Unsupported operationKey: "+req.OperationKey)
+		return
+	}
+	old, ok := p.version(req.Params.VersionNumber)
+	if !ok || old.Number == p.current().Number {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "Cannot restore version "+strconv.Itoa(req.Params.VersionNumber))
+		return
+	}
+	title := ""
+	if req.Params.RestoreTitle {
+		title = old.Title
+	}
+	if title != "" && s.titleTaken(p.SpaceID, title, p.ID) {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "A page with this title already exists: "+title)
+		return
+	}
+	p.update(title, old.Body, req.Params.Message, defaultAuthor, s.now())
+	writeJSON(w, http.StatusOK, v1Version(p.current(), prefix))
+}
+
+// v1CopyPage implements Cloud's single-page copy under a destination parent,
+// which may be in another space. Attachments aren't modeled.
+func (s *Server) v1CopyPage(w http.ResponseWriter, r *http.Request, prefix string) {
+	p := s.livePage(r.PathValue("id"))
+	if p == nil {
+		notFoundV1(w, r.PathValue("id"))
+		return
+	}
+	var req struct {
+		CopyLabels  bool `json:"copyLabels"`
+		Destination struct {
+			Type  string `json:"type"`
+			Value string `json:"value"`
+		} `json:"destination"`
+		PageTitle string `json:"pageTitle"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "Malformed request body: "+err.Error())
+		return
+	}
+	if req.Destination.Type != "parent_page" {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "Unsupported destination type: "+req.Destination.Type)
+		return
+	}
+	parent := s.livePage(req.Destination.Value)
+	if parent == nil {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "Destination page not found: "+req.Destination.Value)
+		return
+	}
+	title := req.PageTitle
+	if title == "" {
+		title = p.current().Title
+	}
+	sp := s.spaceByID(parent.SpaceID)
+	if s.titleTaken(sp.ID, title, 0) {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "A page with this title already exists: A page already exists with the title "+title+" in the space with key "+sp.Key)
+		return
+	}
+	cp := s.addPage(sp, parent.ID, title, p.current().Body, defaultAuthor)
+	if req.CopyLabels {
+		for _, l := range p.Labels {
+			cp.addLabel(s.newID(), l.Name, l.Prefix)
+		}
+	}
+	writeJSON(w, http.StatusOK, s.v1Content(cp, cp.current(), writeExpand, prefix))
+}
+
+func (s *Server) v1Labels(w http.ResponseWriter, r *http.Request, prefix string) {
+	p := s.liveContent(r.PathValue("id"))
+	if p == nil {
+		notFoundV1(w, r.PathValue("id"))
+		return
+	}
+	writeOffsetPage(w, r, prefix, v1Labels(p))
+}
+
+func (s *Server) v1AddLabels(w http.ResponseWriter, r *http.Request, prefix string) {
+	p := s.liveContent(r.PathValue("id"))
+	if p == nil {
+		notFoundV1(w, r.PathValue("id"))
+		return
+	}
+	var entries []confluence.AddLabelEntry
+	if err := json.NewDecoder(r.Body).Decode(&entries); err != nil {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "Malformed request body: "+err.Error())
+		return
+	}
+	for _, entry := range entries {
+		if !validLabel(entry.Name) {
+			writeError(w, false, http.StatusBadRequest, "Bad Request", "Label name contains invalid characters: "+entry.Name)
+			return
+		}
+	}
+	for _, entry := range entries {
+		p.addLabel(s.newID(), strings.ToLower(entry.Name), entry.Prefix)
+	}
+	writeOffsetPage(w, r, prefix, v1Labels(p))
+}
+
+func (s *Server) v1RemoveLabel(w http.ResponseWriter, r *http.Request, _ string) {
+	p := s.liveContent(r.PathValue("id"))
+	if p == nil {
+		notFoundV1(w, r.PathValue("id"))
+		return
+	}
+	for i, l := range p.Labels {
+		if l.Name == r.PathValue("name") {
+			p.Labels = slices.Delete(p.Labels, i, i+1)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, false, http.StatusNotFound, "Not Found", "Label "+r.PathValue("name")+" does not exist on content "+r.PathValue("id"))
+}
+
+// --- content properties ---
+
+func v1Property(pr *property, prefix string) confluence.V1ContentProperty {
+	version := v1Version(pr.Version, prefix)
+	return confluence.V1ContentProperty{ID: strconv.Itoa(pr.ID), Key: pr.Key, Value: pr.Value, Version: &version}
+}
+
+func propertyNotFoundV1(w http.ResponseWriter, key string) {
+	writeError(w, false, http.StatusNotFound, "Not Found", "Cannot find content property with key: "+key)
+}
+
+func (s *Server) v1Properties(w http.ResponseWriter, r *http.Request, prefix string) {
+	p := s.liveContent(r.PathValue("id"))
+	if p == nil {
+		notFoundV1(w, r.PathValue("id"))
+		return
+	}
+	out := make([]confluence.V1ContentProperty, 0, len(p.Props))
+	for _, pr := range p.Props {
+		out = append(out, v1Property(pr, prefix))
+	}
+	writeOffsetPage(w, r, prefix, out)
+}
+
+func (s *Server) v1AddProperty(w http.ResponseWriter, r *http.Request, prefix string) {
+	p := s.liveContent(r.PathValue("id"))
+	if p == nil {
+		notFoundV1(w, r.PathValue("id"))
+		return
+	}
+	var req propertyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "Malformed request body: "+err.Error())
+		return
+	}
+	if msg := req.invalid(false); msg != "" {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", msg)
+		return
+	}
+	if p.property(req.Key) != nil {
+		writeError(w, false, http.StatusConflict, "Conflict", "Cannot add a property with key '"+req.Key+"' as it already exists")
+		return
+	}
+	writeJSON(w, http.StatusOK, v1Property(s.addProperty(p, req), prefix))
+}
+
+func (s *Server) v1GetProperty(w http.ResponseWriter, r *http.Request, prefix string) {
+	p := s.liveContent(r.PathValue("id"))
+	if p == nil {
+		notFoundV1(w, r.PathValue("id"))
+		return
+	}
+	pr := p.property(r.PathValue("key"))
+	if pr == nil {
+		propertyNotFoundV1(w, r.PathValue("key"))
+		return
+	}
+	writeJSON(w, http.StatusOK, v1Property(pr, prefix))
+}
+
+func (s *Server) v1UpdateProperty(w http.ResponseWriter, r *http.Request, prefix string) {
+	p := s.liveContent(r.PathValue("id"))
+	if p == nil {
+		notFoundV1(w, r.PathValue("id"))
+		return
+	}
+	pr := p.property(r.PathValue("key"))
+	if pr == nil {
+		propertyNotFoundV1(w, r.PathValue("key"))
+		return
+	}
+	var req propertyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "Malformed request body: "+err.Error())
+		return
+	}
+	switch {
+	case req.invalid(true) != "":
+		writeError(w, false, http.StatusBadRequest, "Bad Request", req.invalid(true))
+		return
+	case req.Key != pr.Key:
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "The key in the request doesn't match the key in the URL")
+		return
+	case req.Version.Number != pr.Version.Number+1:
+		writeError(w, false, http.StatusConflict, "Conflict", "Version must be incremented on update. Current version is: "+strconv.Itoa(pr.Version.Number))
+		return
+	}
+	s.updateProperty(pr, req)
+	writeJSON(w, http.StatusOK, v1Property(pr, prefix))
+}
+
+func (s *Server) v1DeleteProperty(w http.ResponseWriter, r *http.Request, _ string) {
+	p := s.liveContent(r.PathValue("id"))
+	if p == nil {
+		notFoundV1(w, r.PathValue("id"))
+		return
+	}
+	pr := p.property(r.PathValue("key"))
+	if pr == nil {
+		propertyNotFoundV1(w, r.PathValue("key"))
+		return
+	}
+	p.deleteProperty(pr)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// searchPages evaluates the request's cql parameter, writing a 400 on failure.
+func (s *Server) searchPages(w http.ResponseWriter, r *http.Request) ([]*page, bool) {
+	raw := r.URL.Query().Get("cql")
+	if strings.TrimSpace(raw) == "" {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "CQL was not provided")
+		return nil, false
+	}
+	query, err := parseCQL(raw)
+	if err != nil {
+		writeError(w, false, http.StatusBadRequest, "Bad Request", "Could not parse cql : "+raw+" ("+err.Error()+")")
+		return nil, false
+	}
+	return s.evalCQL(query), true
+}
+
+func (s *Server) v1Search(w http.ResponseWriter, r *http.Request, prefix string) {
+	pages, ok := s.searchPages(w, r)
+	if !ok {
+		return
+	}
+	expand := map[string]bool{}
+	for e := range expansions(r) {
+		if rest, ok := strings.CutPrefix(e, "content."); ok {
+			expand[rest] = true
+		}
+	}
+
+	items := make([]confluence.SearchResultItem, 0, len(pages))
+	for _, p := range pages {
+		sp := s.spaceByID(p.SpaceID)
+		content := s.v1Content(p, p.current(), expand, prefix)
+		containerURL := "/display/" + sp.Key
+		if prefix != "" {
+			containerURL = "/spaces/" + sp.Key
+		}
+		items = append(items, confluence.SearchResultItem{
+			Content:               &content,
+			Title:                 p.current().Title,
+			Excerpt:               excerpt(p.current().Body),
+			URL:                   webPath(sp, p),
+			ResultGlobalContainer: &confluence.ResultContainer{Title: sp.Name, DisplayURL: containerURL},
+		})
+	}
+	page, ok := offsetSlice(w, r, prefix, items)
+	if !ok {
+		return
+	}
+	page.TotalSize = len(items)
+	page.CQLQuery = r.URL.Query().Get("cql")
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) v1ContentSearch(w http.ResponseWriter, r *http.Request, prefix string) {
+	pages, ok := s.searchPages(w, r)
+	if !ok {
+		return
+	}
+	expand := expansions(r)
+	out := make([]confluence.V1Content, 0, len(pages))
+	for _, p := range pages {
+		out = append(out, s.v1Content(p, p.current(), expand, prefix))
+	}
+	writeOffsetPage(w, r, prefix, out)
+}
+
+// systemInfo is Cloud's /settings/systemInfo; its cloudId is what tells a
+// custom-domain Cloud site apart from Data Center.
+func (s *Server) systemInfo(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"cloudId":    fakeCloudID,
+		"commitHash": "fake",
+		"baseUrl":    "http://" + r.Host + "/wiki",
+	})
+}
+
+// manifest is the Server/DC application links manifest, which carries the
+// product version.
+func (s *Server) manifest(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"typeId":      "confluence",
+		"name":        "Fake Confluence",
+		"version":     fakeServerVersion,
+		"buildNumber": fakeServerBuild,
+		"url":         "http://" + r.Host,
+	})
+}
@@ -0,0 +1,768 @@
+package fakeconfluence
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/relux-works/skill-confluence-management/internal/confluence"
+)
+
+// v2 endpoints, as served by Confluence Cloud under /wiki/api/v2.
+
+const (
+	v2DefaultLimit = 25
+	v2MaxLimit     = 250
+)
+
+// writeCursorPage writes items as a v2 cursor page. Cursors are opaque
+// offsets; the next link repeats the request's other parameters.
+func writeCursorPage[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	limit, ok := queryInt(r, "limit", v2DefaultLimit)
+	if !ok || limit == 0 {
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "limit must be a positive integer")
+		return
+	}
+	limit = min(limit, v2MaxLimit)
+
+	offset := 0
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err == nil {
+			offset, err = strconv.Atoi(strings.TrimPrefix(string(raw), "offset:"))
+		}
+		if err != nil || offset < 0 {
+			writeError(w, true, http.StatusBadRequest, "Bad Request", "Invalid cursor")
+			return
+		}
+	}
+	offset = min(offset, len(items))
+	end := min(offset+limit, len(items))
+
+	resp := confluence.CursorPage[T]{Results: append([]T{}, items[offset:end]...)}
+	if end < len(items) {
+		q := r.URL.Query()
+		q.Set("cursor", base64.RawURLEncoding.EncodeToString([]byte("offset:"+strconv.Itoa(end))))
+		resp.Links = &confluence.PaginationLinks{Next: r.URL.Path + "?" + q.Encode()}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) v2Space(sp *space, r *http.Request) confluence.Space {
+	out := confluence.Space{
+		ID:     strconv.Itoa(sp.ID),
+		Key:    sp.Key,
+		Name:   sp.Name,
+		Type:   sp.Type,
+		Status: "current",
+		Links:  &confluence.SpaceLinks{WebUI: "/spaces/" + sp.Key},
+	}
+	if sp.HomepageID != 0 {
+		out.HomepageID = strconv.Itoa(sp.HomepageID)
+	}
+	if r.URL.Query().Get("description-format") == "plain" {
+		out.Description = &confluence.SpaceDesc{Plain: &confluence.BodyRepresentation{Value: sp.Description, Representation: "plain"}}
+	}
+	return out
+}
+
+// v2Page renders version v of p. The storage body is included only when
+// the request asked for body-format=storage.
+func (s *Server) v2Page(p *page, v pageVersion, r *http.Request) confluence.Page {
+	sp := s.spaceByID(p.SpaceID)
+	out := confluence.Page{
+		ID:        strconv.Itoa(p.ID),
+		Status:    p.Status,
+		Title:     v.Title,
+		SpaceID:   strconv.Itoa(p.SpaceID),
+		AuthorID:  p.AuthorID,
+		CreatedAt: formatTime(p.CreatedAt),
+		Version: &confluence.Version{
+			Number:    v.Number,
+			Message:   v.Message,
+			CreatedAt: formatTime(v.When),
+			AuthorID:  v.AuthorID,
+		},
+		Links: &confluence.PageLinks{
+			WebUI:  webPath(sp, p),
+			EditUI: "/pages/resumedraft.action?draftId=" + strconv.Itoa(p.ID),
+			TinyUI: "/x/" + base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(p.ID))),
+		},
+	}
+	if p.ParentID != 0 {
+		out.ParentID = strconv.Itoa(p.ParentID)
+		out.ParentType = "page"
+	}
+	if r != nil && r.URL.Query().Get("body-format") == "storage" {
+		out.Body = &confluence.PageBody{Storage: &confluence.BodyRepresentation{Value: v.Body, Representation: "storage"}}
+	}
+	return out
+}
+
+func (s *Server) v2ListSpaces(w http.ResponseWriter, r *http.Request, _ string) {
+	keys, ids := splitList(r, "keys"), splitList(r, "ids")
+	typ := r.URL.Query().Get("type")
+
+	var out []confluence.Space
+	for _, sp := range s.spaces {
+		if len(keys) > 0 && !slices.Contains(keys, sp.Key) {
+			continue
+		}
+		if len(ids) > 0 && !slices.Contains(ids, strconv.Itoa(sp.ID)) {
+			continue
+		}
+		if typ != "" && typ != sp.Type {
+			continue
+		}
+		out = append(out, s.v2Space(sp, r))
+	}
+	writeCursorPage(w, r, out)
+}
+
+func (s *Server) v2GetSpace(w http.ResponseWriter, r *http.Request, _ string) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	sp := s.spaceByID(id)
+	if sp == nil {
+		writeError(w, true, http.StatusNotFound, "Not Found", "Space "+r.PathValue("id")+" does not exist")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.v2Space(sp, r))
+}
+
+func (s *Server) v2ListPages(w http.ResponseWriter, r *http.Request, _ string) {
+	spaceIDs := splitList(r, "space-id")
+	title := r.URL.Query().Get("title")
+	statuses := splitList(r, "status")
+	if len(statuses) == 0 {
+		statuses = []string{"current"}
+	}
+
+	var out []confluence.Page
+	for _, id := range s.order {
+		p := s.pages[id]
+		var rendered confluence.Page
+		switch {
+		case p.Type != "page":
+			continue
+		case slices.Contains(statuses, p.Status):
+			rendered = s.v2Page(p, p.current(), r)
+		case p.Draft != nil && slices.Contains(statuses, "draft"):
+			rendered = s.v2Draft(p, r)
+		default:
+			continue
+		}
+		if len(spaceIDs) > 0 && !slices.Contains(spaceIDs, strconv.Itoa(p.SpaceID)) {
+			continue
+		}
+		if title != "" && rendered.Title != title {
+			continue
+		}
+		out = append(out, rendered)
+	}
+	writeCursorPage(w, r, out)
+}
+
+// v2Draft renders the pending draft of a current page, or p itself when it
+// has none.
+func (s *Server) v2Draft(p *page, r *http.Request) confluence.Page {
+	if p.Draft == nil {
+		return s.v2Page(p, p.current(), r)
+	}
+	out := s.v2Page(p, *p.Draft, r)
+	out.Status = "draft"
+	return out
+}
+
+// v2GetPage serves a current page, or with get-draft=true its draft; drafts
+// and trashed pages need get-draft or a matching status filter.
+func (s *Server) v2GetPage(w http.ResponseWriter, r *http.Request, _ string) {
+	statuses := splitList(r, "status")
+	getDraft := r.URL.Query().Get("get-draft") == "true"
+	p := s.livePage(r.PathValue("id"))
+	if p == nil && slices.Contains(statuses, "trashed") {
+		p = s.pageInStatus(r.PathValue("id"), "trashed")
+	}
+	if p == nil && (getDraft || slices.Contains(statuses, "draft")) {
+		p = s.pageInStatus(r.PathValue("id"), "draft")
+	}
+	if p == nil {
+		writeError(w, true, http.StatusNotFound, "Not Found", "Page "+r.PathValue("id")+" does not exist or you don't have permission to view it")
+		return
+	}
+	if getDraft {
+		writeJSON(w, http.StatusOK, s.v2Draft(p, r))
+		return
+	}
+	v := p.current()
+	if raw := r.URL.Query().Get("version"); raw != "" {
+		n, _ := strconv.Atoi(raw)
+		var ok bool
+		if v, ok = p.version(n); !ok {
+			writeError(w, true, http.StatusNotFound, "Not Found", "Version "+raw+" of page "+r.PathValue("id")+" does not exist")
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, s.v2Page(p, v, r))
+}
+
+func (s *Server) v2CreatePage(w http.ResponseWriter, r *http.Request, _ string) {
+	var req confluence.CreatePageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Malformed request body: "+err.Error())
+		return
+	}
+	spaceID, _ := strconv.Atoi(req.SpaceID)
+	sp := s.spaceByID(spaceID)
+	switch {
+	case sp == nil:
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Invalid spaceId: "+req.SpaceID)
+		return
+	case strings.TrimSpace(req.Title) == "":
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Title is required")
+		return
+	case req.Status != "" && req.Status != "current" && req.Status != "draft":
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Unsupported status: "+req.Status)
+		return
+	case req.Body != nil && req.Body.Representation != "storage":
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Unsupported body representation: "+req.Body.Representation)
+		return
+	case req.Status != "draft" && s.titleTaken(sp.ID, req.Title, 0):
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "A page with this title already exists: A page already exists with the same TITLE in this space")
+		return
+	}
+
+	parentID := 0
+	if req.ParentID != "" {
+		parent := s.livePage(req.ParentID)
+		if parent == nil || parent.SpaceID != sp.ID {
+			writeError(w, true, http.StatusBadRequest, "Bad Request", "Invalid parentId: "+req.ParentID)
+			return
+		}
+		parentID = parent.ID
+	}
+	body := ""
+	if req.Body != nil {
+		body = req.Body.Value
+	}
+	p := s.addPage(sp, parentID, req.Title, body, defaultAuthor)
+	if req.Status == "draft" {
+		p.Status = "draft"
+	}
+	out := s.v2Page(p, p.current(), nil)
+	out.Body = &confluence.PageBody{Storage: &confluence.BodyRepresentation{Value: body, Representation: "storage"}}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// v2UpdatePage publishes a new version. A trashed page can only be updated
+// to status current, which restores it; drafts are handled by v2UpdateDraft.
+func (s *Server) v2UpdatePage(w http.ResponseWriter, r *http.Request, _ string) {
+	p := s.livePage(r.PathValue("id"))
+	if p == nil {
+		p = s.pageInStatus(r.PathValue("id"), "draft")
+	}
+	trashed := p == nil
+	if trashed {
+		p = s.pageInStatus(r.PathValue("id"), "trashed")
+	}
+	if p == nil {
+		writeError(w, true, http.StatusNotFound, "Not Found", "Page "+r.PathValue("id")+" does not exist or you don't have permission to view it")
+		return
+	}
+	var req confluence.UpdatePageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Malformed request body: "+err.Error())
+		return
+	}
+	if trashed && req.Status != "current" {
+		writeError(w, true, http.StatusNotFound, "Not Found", "Page "+r.PathValue("id")+" does not exist or you don't have permission to view it")
+		return
+	}
+	if p.Status == "draft" || req.Status == "draft" {
+		s.v2UpdateDraft(w, p, req)
+		return
+	}
+	cur := p.current()
+	switch {
+	case req.Version == nil || req.Version.Number == 0:
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Version number is required")
+		return
+	case req.Version.Number != cur.Number+1:
+		writeError(w, true, http.StatusConflict, "Conflict", "Version must be incremented on update. Current version is: "+strconv.Itoa(cur.Number))
+		return
+	case strings.TrimSpace(req.Title) == "":
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Title is required")
+		return
+	case req.Body != nil && req.Body.Representation != "storage":
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Unsupported body representation: "+req.Body.Representation)
+		return
+	case s.titleTaken(p.SpaceID, req.Title, p.ID):
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "A page with this title already exists: A page already exists with the same TITLE in this space")
+		return
+	}
+	var parent *page
+	if req.ParentID != "" && req.ParentID != strconv.Itoa(p.ParentID) {
+		parent = s.livePage(req.ParentID)
+		switch {
+		case parent == nil:
+			writeError(w, true, http.StatusBadRequest, "Bad Request", "Parent page "+req.ParentID+" does not exist")
+			return
+		case parent.SpaceID != p.SpaceID:
+			writeError(w, true, http.StatusBadRequest, "Bad Request", "Moving a page to a different space is not supported")
+			return
+		case s.within(parent, p):
+			writeError(w, true, http.StatusBadRequest, "Bad Request", "A page cannot become a child of itself or its descendants")
+			return
+		}
+	}
+
+	body := cur.Body
+	if req.Body != nil {
+		body = req.Body.Value
+	}
+	if trashed {
+		s.restore(p)
+	}
+	p.update(req.Title, body, req.Version.Message, defaultAuthor, s.now())
+	if parent != nil {
+		s.move(p, "append", parent)
+	}
+	out := s.v2Page(p, p.current(), nil)
+	out.Body = &confluence.PageBody{Storage: &confluence.BodyRepresentation{Value: body, Representation: "storage"}}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// v2UpdateDraft saves unpublished edits (status draft) or publishes a draft
+// page (status current, version 1). Draft saves don't check versions.
+func (s *Server) v2UpdateDraft(w http.ResponseWriter, p *page, req confluence.UpdatePageRequest) {
+	publishing := req.Status == "current"
+	switch {
+	case req.Version == nil || req.Version.Number == 0:
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Version number is required")
+		return
+	case publishing && req.Version.Number != 1:
+		writeError(w, true, http.StatusConflict, "Conflict", "A draft is published as version 1")
+		return
+	case strings.TrimSpace(req.Title) == "":
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Title is required")
+		return
+	case req.Body != nil && req.Body.Representation != "storage":
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Unsupported body representation: "+req.Body.Representation)
+		return
+	case publishing && s.titleTaken(p.SpaceID, req.Title, p.ID):
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "A page with this title already exists: A page already exists with the same TITLE in this space")
+		return
+	}
+
+	body := p.current().Body
+	if p.Draft != nil {
+		body = p.Draft.Body
+	}
+	if req.Body != nil {
+		body = req.Body.Value
+	}
+	var out confluence.Page
+	if publishing {
+		p.publish(req.Title, body, req.Version.Message, defaultAuthor, s.now())
+		out = s.v2Page(p, p.current(), nil)
+	} else {
+		p.saveDraft(req.Title, body, req.Version.Message, defaultAuthor, s.now())
+		out = s.v2Draft(p, nil)
+	}
+	out.Body = &confluence.PageBody{Storage: &confluence.BodyRepresentation{Value: body, Representation: "storage"}}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// v2DeletePage moves a page to the trash, or purges it when it is already
+// trashed and purge=true. Children move up to the deleted page's parent.
+func (s *Server) v2DeletePage(w http.ResponseWriter, r *http.Request, _ string) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	p := s.pages[id]
+	switch {
+	case p == nil || p.Type != "page":
+		writeError(w, true, http.StatusNotFound, "Not Found", "Page "+r.PathValue("id")+" does not exist or you don't have permission to view it")
+		return
+	case p.Status == "current":
+		s.trash(p)
+	case p.Status == "trashed" && r.URL.Query().Get("purge") == "true":
+		s.purge(p)
+	default:
+		writeError(w, true, http.StatusNotFound, "Not Found", "Page "+r.PathValue("id")+" does not exist or you don't have permission to view it")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --- blog posts ---
+
+func blogPostNotFound(w http.ResponseWriter, id string) {
+	writeError(w, true, http.StatusNotFound, "Not Found", "Blog post "+id+" does not exist or you don't have permission to view it")
+}
+
+func (s *Server) v2ListBlogPosts(w http.ResponseWriter, r *http.Request, _ string) {
+	spaceIDs := splitList(r, "space-id")
+	title := r.URL.Query().Get("title")
+
+	var out []confluence.Page
+	for _, id := range s.order {
+		p := s.pages[id]
+		if p.Type != "blogpost" || p.Status != "current" {
+			continue
+		}
+		if len(spaceIDs) > 0 && !slices.Contains(spaceIDs, strconv.Itoa(p.SpaceID)) {
+			continue
+		}
+		if title != "" && p.current().Title != title {
+			continue
+		}
+		out = append(out, s.v2Page(p, p.current(), r))
+	}
+	writeCursorPage(w, r, out)
+}
+
+func (s *Server) v2GetBlogPost(w http.ResponseWriter, r *http.Request, _ string) {
+	p := s.liveBlogPost(r.PathValue("id"))
+	if p == nil {
+		blogPostNotFound(w, r.PathValue("id"))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.v2Page(p, p.current(), r))
+}
+
+func (s *Server) v2CreateBlogPost(w http.ResponseWriter, r *http.Request, _ string) {
+	var req confluence.CreatePageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Malformed request body: "+err.Error())
+		return
+	}
+	spaceID, _ := strconv.Atoi(req.SpaceID)
+	sp := s.spaceByID(spaceID)
+	switch {
+	case sp == nil:
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Invalid spaceId: "+req.SpaceID)
+		return
+	case strings.TrimSpace(req.Title) == "":
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Title is required")
+		return
+	case req.Status != "" && req.Status != "current":
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Unsupported status: "+req.Status)
+		return
+	case req.Body != nil && req.Body.Representation != "storage":
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Unsupported body representation: "+req.Body.Representation)
+		return
+	}
+
+	body := ""
+	if req.Body != nil {
+		body = req.Body.Value
+	}
+	p := s.addBlogPost(sp, req.Title, body, defaultAuthor)
+	out := s.v2Page(p, p.current(), nil)
+	out.Body = &confluence.PageBody{Storage: &confluence.BodyRepresentation{Value: body, Representation: "storage"}}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) v2UpdateBlogPost(w http.ResponseWriter, r *http.Request, _ string) {
+	p := s.liveBlogPost(r.PathValue("id"))
+	if p == nil {
+		blogPostNotFound(w, r.PathValue("id"))
+		return
+	}
+	var req confluence.UpdatePageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Malformed request body: "+err.Error())
+		return
+	}
+	cur := p.current()
+	switch {
+	case req.Version == nil || req.Version.Number == 0:
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Version number is required")
+		return
+	case req.Version.Number != cur.Number+1:
+		writeError(w, true, http.StatusConflict, "Conflict", "Version must be incremented on update. Current version is: "+strconv.Itoa(cur.Number))
+		return
+	case strings.TrimSpace(req.Title) == "":
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Title is required")
+		return
+	case req.Body != nil && req.Body.Representation != "storage":
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Unsupported body representation: "+req.Body.Representation)
+		return
+	}
+
+	body := cur.Body
+	if req.Body != nil {
+		body = req.Body.Value
+	}
+	p.update(req.Title, body, req.Version.Message, defaultAuthor, s.now())
+	out := s.v2Page(p, p.current(), nil)
+	out.Body = &confluence.PageBody{Storage: &confluence.BodyRepresentation{Value: body, Representation: "storage"}}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) v2DeleteBlogPost(w http.ResponseWriter, r *http.Request, _ string) {
+	p := s.liveBlogPost(r.PathValue("id"))
+	if p == nil {
+		blogPostNotFound(w, r.PathValue("id"))
+		return
+	}
+	s.trash(p)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) v2Children(w http.ResponseWriter, r *http.Request, _ string) {
+	p := s.livePage(r.PathValue("id"))
+	if p == nil {
+		writeError(w, true, http.StatusNotFound, "Not Found", "Page "+r.PathValue("id")+" does not exist or you don't have permission to view it")
+		return
+	}
+	var out []confluence.Page
+	for _, child := range s.children(p.ID) {
+		out = append(out, confluence.Page{
+			ID:      strconv.Itoa(child.ID),
+			Status:  child.Status,
+			Title:   child.current().Title,
+			SpaceID: strconv.Itoa(child.SpaceID),
+		})
+	}
+	writeCursorPage(w, r, out)
+}
+
+func (s *Server) v2Versions(w http.ResponseWriter, r *http.Request, _ string) {
+	p := s.livePage(r.PathValue("id"))
+	if p == nil {
+		writeError(w, true, http.StatusNotFound, "Not Found", "Page "+r.PathValue("id")+" does not exist or you don't have permission to view it")
+		return
+	}
+	out := make([]confluence.Version, 0, len(p.Versions))
+	for _, v := range p.Versions {
+		out = append(out, confluence.Version{Number: v.Number, Message: v.Message, CreatedAt: formatTime(v.When), AuthorID: v.AuthorID})
+	}
+	if r.URL.Query().Get("sort") != "modified-date" {
+		slices.Reverse(out) // newest first unless asked otherwise
+	}
+	writeCursorPage(w, r, out)
+}
+
+// v2Ancestor is the v2 ancestor shape: IDs only, root first.
+type v2Ancestor struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+func (s *Server) v2Ancestors(w http.ResponseWriter, r *http.Request, _ string) {
+	p := s.livePage(r.PathValue("id"))
+	if p == nil {
+		writeError(w, true, http.StatusNotFound, "Not Found", "Page "+r.PathValue("id")+" does not exist or you don't have permission to view it")
+		return
+	}
+	var out []v2Ancestor
+	for _, a := range s.ancestors(p) {
+		out = append(out, v2Ancestor{ID: strconv.Itoa(a.ID), Type: "page"})
+	}
+	writeCursorPage(w, r, out)
+}
+
+func v2Labels(p *page, prefix string) []confluence.Label {
+	var out []confluence.Label
+	for _, l := range p.Labels {
+		if prefix != "" && l.Prefix != prefix {
+			continue
+		}
+		out = append(out, confluence.Label{ID: strconv.Itoa(l.ID), Name: l.Name, Prefix: l.Prefix})
+	}
+	return out
+}
+
+func (s *Server) v2Labels(w http.ResponseWriter, r *http.Request, _ string) {
+	p := s.livePage(r.PathValue("id"))
+	if p == nil {
+		writeError(w, true, http.StatusNotFound, "Not Found", "Page "+r.PathValue("id")+" does not exist or you don't have permission to view it")
+		return
+	}
+	writeCursorPage(w, r, v2Labels(p, r.URL.Query().Get("prefix")))
+}
+
+func (s *Server) v2AddLabels(w http.ResponseWriter, r *http.Request, _ string) {
+	p := s.livePage(r.PathValue("id"))
+	if p == nil {
+		writeError(w, true, http.StatusNotFound, "Not Found", "Page "+r.PathValue("id")+" does not exist or you don't have permission to view it")
+		return
+	}
+	var req confluence.AddLabelsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Malformed request body: "+err.Error())
+		return
+	}
+	for _, entry := range req {
+		if !validLabel(entry.Name) {
+			writeError(w, true, http.StatusBadRequest, "Bad Request", "Invalid label name: "+entry.Name)
+			return
+		}
+	}
+	for _, entry := range req {
+		p.addLabel(s.newID(), strings.ToLower(entry.Name), entry.Prefix)
+	}
+	writeJSON(w, http.StatusOK, confluence.CursorPage[confluence.Label]{Results: append([]confluence.Label{}, v2Labels(p, "")...)})
+}
+
+func (s *Server) v2RemoveLabel(w http.ResponseWriter, r *http.Request, _ string) {
+	p := s.livePage(r.PathValue("id"))
+	if p == nil {
+		writeError(w, true, http.StatusNotFound, "Not Found", "Page "+r.PathValue("id")+" does not exist or you don't have permission to view it")
+		return
+	}
+	for i, l := range p.Labels {
+		if strconv.Itoa(l.ID) == r.PathValue("labelID") {
+			p.Labels = slices.Delete(p.Labels, i, i+1)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, true, http.StatusNotFound, "Not Found", "Label "+r.PathValue("labelID")+" is not on page "+r.PathValue("id"))
+}
+
+// --- content properties ---
+
+func v2Property(pr *property) confluence.ContentProperty {
+	return confluence.ContentProperty{
+		ID:    strconv.Itoa(pr.ID),
+		Key:   pr.Key,
+		Value: pr.Value,
+		Version: &confluence.Version{
+			Number:    pr.Version.Number,
+			Message:   pr.Version.Message,
+			CreatedAt: formatTime(pr.Version.When),
+			AuthorID:  pr.Version.AuthorID,
+		},
+	}
+}
+
+// pageProperty resolves the page and property IDs of r, writing a 404 when
+// either is missing.
+func (s *Server) pageProperty(w http.ResponseWriter, r *http.Request) (*page, *property) {
+	p := s.livePage(r.PathValue("id"))
+	if p == nil {
+		writeError(w, true, http.StatusNotFound, "Not Found", "Page "+r.PathValue("id")+" does not exist or you don't have permission to view it")
+		return nil, nil
+	}
+	for _, pr := range p.Props {
+		if strconv.Itoa(pr.ID) == r.PathValue("propertyID") {
+			return p, pr
+		}
+	}
+	writeError(w, true, http.StatusNotFound, "Not Found", "Property "+r.PathValue("propertyID")+" does not exist on page "+r.PathValue("id"))
+	return nil, nil
+}
+
+// v2Properties lists a page's properties, or with key= just that one.
+func (s *Server) v2Properties(w http.ResponseWriter, r *http.Request, _ string) {
+	p := s.livePage(r.PathValue("id"))
+	if p == nil {
+		writeError(w, true, http.StatusNotFound, "Not Found", "Page "+r.PathValue("id")+" does not exist or you don't have permission to view it")
+		return
+	}
+	key := r.URL.Query().Get("key")
+	var out []confluence.ContentProperty
+	for _, pr := range p.Props {
+		if key == "" || pr.Key == key {
+			out = append(out, v2Property(pr))
+		}
+	}
+	writeCursorPage(w, r, out)
+}
+
+func (s *Server) v2AddProperty(w http.ResponseWriter, r *http.Request, _ string) {
+	p := s.livePage(r.PathValue("id"))
+	if p == nil {
+		writeError(w, true, http.StatusNotFound, "Not Found", "Page "+r.PathValue("id")+" does not exist or you don't have permission to view it")
+		return
+	}
+	var req propertyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Malformed request body: "+err.Error())
+		return
+	}
+	if msg := req.invalid(false); msg != "" {
+		writeError(w, true, http.StatusBadRequest, "Bad Request", msg)
+		return
+	}
+	if p.property(req.Key) != nil {
+		writeError(w, true, http.StatusConflict, "Conflict", "A property with key "+req.Key+" already exists on page "+r.PathValue("id"))
+		return
+	}
+	writeJSON(w, http.StatusOK, v2Property(s.addProperty(p, req)))
+}
+
+func (s *Server) v2GetProperty(w http.ResponseWriter, r *http.Request, _ string) {
+	if _, pr := s.pageProperty(w, r); pr != nil {
+		writeJSON(w, http.StatusOK, v2Property(pr))
+	}
+}
+
+func (s *Server) v2UpdateProperty(w http.ResponseWriter, r *http.Request, _ string) {
+	_, pr := s.pageProperty(w, r)
+	if pr == nil {
+		return
+	}
+	var req propertyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Malformed request body: "+err.Error())
+		return
+	}
+	switch {
+	case req.invalid(true) != "":
+		writeError(w, true, http.StatusBadRequest, "Bad Request", req.invalid(true))
+		return
+	case req.Key != pr.Key:
+		writeError(w, true, http.StatusBadRequest, "Bad Request", "Property key can't be changed")
+		return
+	case req.Version.Number != pr.Version.Number+1:
+		writeError(w, true, http.StatusConflict, "Conflict", "Version must be incremented on update. Current version is: "+strconv.Itoa(pr.Version.Number))
+		return
+	}
+	s.updateProperty(pr, req)
+	writeJSON(w, http.StatusOK, v2Property(pr))
+}
+
+func (s *Server) v2DeleteProperty(w http.ResponseWriter, r *http.Request, _ string) {
+	p, pr := s.pageProperty(w, r)
+	if pr == nil {
+		return
+	}
+	p.deleteProperty(pr)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validLabel mirrors Confluence's label rules: non-empty, no whitespace or
+// reserved punctuation.
+func validLabel(name string) bool {
+	return name != "" && !strings.ContainsAny(name, " \t\n:;,.?&[]()#^*@!")
+}
+
+// trash moves p to the trash and re-parents its children.
+func (s *Server) trash(p *page) {
+	for _, child := range s.children(p.ID) {
+		child.ParentID = p.ParentID
+	}
+	p.Status = "trashed"
+	if sp := s.spaceByID(p.SpaceID); sp != nil && sp.HomepageID == p.ID {
+		sp.HomepageID = 0
+	}
+}
+
+// restore brings a trashed page back, under its old parent if that is still
+// live and at the top of its space otherwise.
+func (s *Server) restore(p *page) {
+	p.Status = "current"
+	if parent := s.pages[p.ParentID]; parent == nil || parent.Status != "current" {
+		p.ParentID = 0
+	}
+}
+
+// purge removes a trashed page permanently.
+func (s *Server) purge(p *page) {
+	delete(s.pages, p.ID)
+	s.order = slices.DeleteFunc(s.order, func(id int) bool { return id == p.ID })
+}
@@ -3,21 +3,60 @@
 package query
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"sync/atomic"
 
 	"github.com/relux-works/skill-agent-facing-api/agentquery"
 	"github.com/relux-works/skill-confluence-management/internal/confluence"
 )
 
+// defaultSearchLimit is the number of search() results returned when no limit= is given.
+const defaultSearchLimit = 25
+
+// maxPropertyPages caps how many pages one query fetches the properties
+// field for; the count is kept per schema, and the CLI builds a schema per
+// query. Each page costs a request, so list(space=X){properties} on a large
+// space would otherwise fan out into thousands of calls.
+const maxPropertyPages = 50
+
+// defaultHistoryLimit is the number of history() versions returned when no limit= is given.
+const defaultHistoryLimit = 25
+
+// historyFields maps projected field names onto the fields of a history()
+// entry; other page fields are ignored.
+var historyFields = map[string]string{
+	"author":    "author",
+	"date":      "date",
+	"created":   "date",
+	"updated":   "date",
+	"message":   "message",
+	"minorEdit": "minorEdit",
+}
+
+// defaultHistoryFields are the history() entry fields when no projection is
+// given. Entries always carry the version number.
+var defaultHistoryFields = []string{"author", "date", "message"}
+
 // treeNode is the recursive structure returned by the tree() operation.
+// Truncated is set when the walk was cancelled before this node's children were fully read.
 type treeNode struct {
-	Page     any        `json:"page"`
-	Children []treeNode `json:"children,omitempty"`
+	Page      any        `json:"page"`
+	Children  []treeNode `json:"children,omitempty"`
+	Truncated bool       `json:"truncated,omitempty"`
 }
 
 // NewSchema builds a fully configured agentquery.Schema for Confluence pages.
 // The client is captured by closure in all operation handlers.
 func NewSchema(client *confluence.Client) *agentquery.Schema[*confluence.Page] {
+	return NewSchemaContext(context.Background(), client)
+}
+
+// NewSchemaContext is like NewSchema, but every API call made by the operation
+// handlers is bound to reqCtx, so cancelling it aborts in-flight queries.
+func NewSchemaContext(reqCtx context.Context, client *confluence.Client) *agentquery.Schema[*confluence.Page] {
 	schema := agentquery.NewSchema[*confluence.Page]()
 
 	// --- Page fields ---
@@ -49,8 +88,18 @@ func NewSchema(client *confluence.Client) *agentquery.Schema[*confluence.Page] {
 		if p == nil {
 			return nil
 		}
-		// v2 returns spaceId, not spaceKey — best effort
-		return p.SpaceID
+		if p.SpaceKey != "" {
+			return p.SpaceKey
+		}
+		if p.SpaceID == "" {
+			return nil
+		}
+		// v2 only returns spaceId; the client caches the reverse lookup per space.
+		key, err := client.SpaceKeyForIDContext(reqCtx, p.SpaceID)
+		if err != nil {
+			return nil
+		}
+		return key
 	})
 	schema.Field("version", func(p *confluence.Page) any {
 		if p == nil || p.Version == nil {
@@ -58,6 +107,31 @@ func NewSchema(client *confluence.Client) *agentquery.Schema[*confluence.Page] {
 		}
 		return p.Version.Number
 	})
+	// Version fields describe the version a page was read at.
+	schema.Field("number", func(p *confluence.Page) any {
+		if p == nil || p.Version == nil {
+			return nil
+		}
+		return p.Version.Number
+	})
+	schema.Field("date", func(p *confluence.Page) any {
+		if p == nil || p.Version == nil {
+			return nil
+		}
+		return p.Version.CreatedAt
+	})
+	schema.Field("message", func(p *confluence.Page) any {
+		if p == nil || p.Version == nil {
+			return nil
+		}
+		return p.Version.Message
+	})
+	schema.Field("minorEdit", func(p *confluence.Page) any {
+		if p == nil || p.Version == nil {
+			return nil
+		}
+		return p.Version.MinorEdit
+	})
 	schema.Field("body", func(p *confluence.Page) any {
 		if p == nil || p.Body == nil || p.Body.Storage == nil {
 			return nil
@@ -74,6 +148,36 @@ func NewSchema(client *confluence.Client) *agentquery.Schema[*confluence.Page] {
 		}
 		return names
 	})
+	var propertyPages atomic.Int32
+	schema.Field("properties", func(p *confluence.Page) any {
+		if p == nil || p.ID == "" {
+			return nil
+		}
+		// Properties aren't part of the page payload; fetched only when
+		// projected, one request per page. Failures are reported in place,
+		// in the shape agentquery uses for failed statements, so they can't
+		// pass for a page without properties.
+		if !client.Has(confluence.CapContentProperties) {
+			return fieldError(fmt.Errorf("%w: content properties", confluence.ErrNotSupported))
+		}
+		if propertyPages.Add(1) > maxPropertyPages {
+			return fieldError(fmt.Errorf("properties are fetched for at most %d pages per query; use 'page prop list %s'", maxPropertyPages, p.ID))
+		}
+		props, err := client.ListPagePropertiesContext(reqCtx, p.ID)
+		if err != nil {
+			return fieldError(err)
+		}
+		out := make(map[string]any, len(props))
+		for _, prop := range props {
+			var v any
+			if err := json.Unmarshal(prop.Value, &v); err != nil {
+				out[prop.Key] = fieldError(fmt.Errorf("decoding property %s: %w", prop.Key, err))
+				continue
+			}
+			out[prop.Key] = v
+		}
+		return out
+	})
 	schema.Field("created", func(p *confluence.Page) any {
 		if p == nil {
 			return nil
@@ -132,58 +236,97 @@ func NewSchema(client *confluence.Client) *agentquery.Schema[*confluence.Page] {
 
 	// get(PAGE_ID) or get(space=KEY, title="Title")
 	schema.OperationWithMetadata("get", func(ctx agentquery.OperationContext[*confluence.Page]) (any, error) {
-		return opGet(ctx, client)
+		return opGet(reqCtx, ctx, client)
 	}, agentquery.OperationMetadata{
-		Description: "Get page by ID or by space+title",
+		Description: "Get page or blog post by ID or by space+title",
 		Parameters: []agentquery.ParameterDef{
 			{Name: "id", Type: "string", Optional: true, Description: "Page ID (positional)"},
 			{Name: "space", Type: "string", Optional: true, Description: "Space key (use with title)"},
 			{Name: "title", Type: "string", Optional: true, Description: "Page title (use with space)"},
+			{Name: "type", Type: "string", Optional: true, Default: "page", Description: "Content type: page or blogpost"},
 		},
 		Examples: []string{
 			"get(12345) { default }",
 			"get(space=DEV, title=\"My Page\") { full }",
+			"get(67890, type=blogpost) { full }",
 		},
 	})
 
 	// list(space=KEY)
 	schema.OperationWithMetadata("list", func(ctx agentquery.OperationContext[*confluence.Page]) (any, error) {
-		return opList(ctx, client)
+		return opList(reqCtx, ctx, client)
 	}, agentquery.OperationMetadata{
 		Description: "List pages in a space, with optional label/title filter",
 		Parameters: []agentquery.ParameterDef{
 			{Name: "space", Type: "string", Optional: false, Description: "Space key"},
 			{Name: "label", Type: "string", Optional: true, Description: "Filter by label (uses CQL)"},
 			{Name: "title", Type: "string", Optional: true, Description: "Filter by title substring"},
+			{Name: "limit", Type: "int", Optional: true, Default: 0, Description: "Max pages to return (0 = all)"},
 		},
 		Examples: []string{
 			"list(space=DEV) { default }",
+			"list(space=DEV, limit=100) { minimal }",
 			"list(space=DEV, label=api) { minimal }",
 			"list(space=DEV, title=\"API\") { overview }",
 		},
 	})
 
+	// blogs(space=KEY)
+	schema.OperationWithMetadata("blogs", func(ctx agentquery.OperationContext[*confluence.Page]) (any, error) {
+		return opBlogs(reqCtx, ctx, client)
+	}, agentquery.OperationMetadata{
+		Description: "List blog posts in a space, with optional title filter",
+		Parameters: []agentquery.ParameterDef{
+			{Name: "space", Type: "string", Optional: false, Description: "Space key"},
+			{Name: "title", Type: "string", Optional: true, Description: "Exact blog post title"},
+			{Name: "limit", Type: "int", Optional: true, Default: 0, Description: "Max blog posts to return (0 = all)"},
+		},
+		Examples: []string{
+			"blogs(space=DEV) { default }",
+			"blogs(space=DEV, limit=5) { minimal }",
+		},
+	})
+
+	// drafts(space=KEY)
+	schema.OperationWithMetadata("drafts", func(ctx agentquery.OperationContext[*confluence.Page]) (any, error) {
+		return opDrafts(reqCtx, ctx, client)
+	}, agentquery.OperationMetadata{
+		Description: "Unpublished drafts in a space, awaiting review (page publish makes them live)",
+		Parameters: []agentquery.ParameterDef{
+			{Name: "space", Type: "string", Optional: false, Description: "Space key"},
+			{Name: "limit", Type: "int", Optional: true, Default: 0, Description: "Max drafts to return (0 = all)"},
+		},
+		Examples: []string{
+			"drafts(space=DEV) { default }",
+			"drafts(space=DEV, limit=10) { minimal }",
+		},
+	})
+
 	// search("CQL")
 	schema.OperationWithMetadata("search", func(ctx agentquery.OperationContext[*confluence.Page]) (any, error) {
-		return opSearch(ctx, client)
+		return opSearch(reqCtx, ctx, client)
 	}, agentquery.OperationMetadata{
-		Description: "CQL search",
+		Description: "CQL search; returns {results, totalSize, start, limit}",
 		Parameters: []agentquery.ParameterDef{
 			{Name: "cql", Type: "string", Optional: false, Description: "CQL query string (positional)"},
+			{Name: "limit", Type: "int", Optional: true, Default: defaultSearchLimit, Description: "Max results to return (0 = all)"},
+			{Name: "start", Type: "int", Optional: true, Default: 0, Description: "Offset of the first result"},
 		},
 		Examples: []string{
 			`search("type=page AND space=DEV") { default }`,
 			`search("type=page AND text~\"API\"") { default }`,
+			`search("type=page AND space=DEV", limit=100, start=100) { minimal }`,
 		},
 	})
 
 	// children(PAGE_ID)
 	schema.OperationWithMetadata("children", func(ctx agentquery.OperationContext[*confluence.Page]) (any, error) {
-		return opChildren(ctx, client)
+		return opChildren(reqCtx, ctx, client)
 	}, agentquery.OperationMetadata{
 		Description: "Direct children of a page",
 		Parameters: []agentquery.ParameterDef{
 			{Name: "id", Type: "string", Optional: false, Description: "Page ID (positional)"},
+			{Name: "limit", Type: "int", Optional: true, Default: 0, Description: "Max children to return (0 = all)"},
 		},
 		Examples: []string{
 			"children(12345) { minimal }",
@@ -192,7 +335,7 @@ func NewSchema(client *confluence.Client) *agentquery.Schema[*confluence.Page] {
 
 	// ancestors(PAGE_ID)
 	schema.OperationWithMetadata("ancestors", func(ctx agentquery.OperationContext[*confluence.Page]) (any, error) {
-		return opAncestors(ctx, client)
+		return opAncestors(reqCtx, ctx, client)
 	}, agentquery.OperationMetadata{
 		Description: "Breadcrumb chain (ancestor pages)",
 		Parameters: []agentquery.ParameterDef{
@@ -205,7 +348,7 @@ func NewSchema(client *confluence.Client) *agentquery.Schema[*confluence.Page] {
 
 	// tree(PAGE_ID)
 	schema.OperationWithMetadata("tree", func(ctx agentquery.OperationContext[*confluence.Page]) (any, error) {
-		return opTree(ctx, client)
+		return opTree(reqCtx, ctx, client)
 	}, agentquery.OperationMetadata{
 		Description: "Recursive children tree with configurable depth",
 		Parameters: []agentquery.ParameterDef{
@@ -220,9 +363,12 @@ func NewSchema(client *confluence.Client) *agentquery.Schema[*confluence.Page] {
 
 	// spaces()
 	schema.OperationWithMetadata("spaces", func(ctx agentquery.OperationContext[*confluence.Page]) (any, error) {
-		return opSpaces(ctx, client)
+		return opSpaces(reqCtx, ctx, client)
 	}, agentquery.OperationMetadata{
 		Description: "List all accessible spaces",
+		Parameters: []agentquery.ParameterDef{
+			{Name: "limit", Type: "int", Optional: true, Default: 0, Description: "Max spaces to return (0 = all)"},
+		},
 		Examples: []string{
 			"spaces() { minimal }",
 			"spaces()",
@@ -231,11 +377,36 @@ func NewSchema(client *confluence.Client) *agentquery.Schema[*confluence.Page] {
 
 	// history(PAGE_ID)
 	schema.OperationWithMetadata("history", func(ctx agentquery.OperationContext[*confluence.Page]) (any, error) {
-		return nil, fmt.Errorf("history operation not yet implemented")
+		return opHistory(reqCtx, ctx, client)
 	}, agentquery.OperationMetadata{
-		Description: "Version history (not yet implemented)",
+		Description: "Version history of a page, newest first",
 		Parameters: []agentquery.ParameterDef{
 			{Name: "id", Type: "string", Optional: false, Description: "Page ID (positional)"},
+			{Name: "limit", Type: "int", Optional: true, Default: defaultHistoryLimit, Description: "Max versions to return (0 = all)"},
+		},
+		Examples: []string{
+			"history(12345)",
+			"history(12345, limit=5) { date minorEdit }",
+			"history(12345) { author message }",
+		},
+	})
+
+	// diff(PAGE_ID)
+	schema.OperationWithMetadata("diff", func(ctx agentquery.OperationContext[*confluence.Page]) (any, error) {
+		return opDiff(reqCtx, ctx, client)
+	}, agentquery.OperationMetadata{
+		Description: "Changed blocks between two versions of a page's storage body",
+		Parameters: []agentquery.ParameterDef{
+			{Name: "id", Type: "string", Optional: false, Description: "Page ID (positional)"},
+			{Name: "from", Type: "int", Optional: true, Default: 0, Description: "Older version (0 = the one before to)"},
+			{Name: "to", Type: "int", Optional: true, Default: 0, Description: "Newer version (0 = current)"},
+			{Name: "normalize", Type: "bool", Optional: true, Default: false, Description: "Ignore whitespace and attribute order differences"},
+			{Name: "format", Type: "string", Optional: true, Default: "blocks", Description: "blocks (structured) or unified (diff text)"},
+		},
+		Examples: []string{
+			"diff(12345)",
+			"diff(12345, from=3, to=5, normalize=true)",
+			"diff(12345, format=unified)",
 		},
 	})
 
@@ -244,11 +415,21 @@ func NewSchema(client *confluence.Client) *agentquery.Schema[*confluence.Page] {
 
 // --- Operation handlers ---
 
-func opGet(ctx agentquery.OperationContext[*confluence.Page], client *confluence.Client) (any, error) {
+func opGet(reqCtx context.Context, ctx agentquery.OperationContext[*confluence.Page], client *confluence.Client) (any, error) {
+	contentType := getNamedArg(ctx.Statement.Args, "type")
+	blog := contentType == "blogpost"
+	if contentType != "" && contentType != "page" && !blog {
+		return nil, fmt.Errorf("get: type must be page or blogpost, got %q", contentType)
+	}
+
 	pageID := getPositionalArg(ctx.Statement.Args, 0)
 	if pageID != "" {
 		includeBody := containsField(ctx.Statement.Fields, "body")
-		page, err := client.GetPage(pageID, includeBody)
+		get := client.GetPageContext
+		if blog {
+			get = client.GetBlogPostContext
+		}
+		page, err := get(reqCtx, pageID, includeBody)
 		if err != nil {
 			return nil, err
 		}
@@ -259,12 +440,16 @@ func opGet(ctx agentquery.OperationContext[*confluence.Page], client *confluence
 	spaceKey := getNamedArg(ctx.Statement.Args, "space")
 	title := getNamedArg(ctx.Statement.Args, "title")
 	if spaceKey != "" && title != "" {
-		pages, err := client.ListPages(spaceKey, title, 1)
+		list, what := client.ListPagesContext, "page"
+		if blog {
+			list, what = client.ListBlogPostsContext, "blog post"
+		}
+		pages, err := list(reqCtx, spaceKey, title, 1)
 		if err != nil {
 			return nil, err
 		}
 		if len(pages) == 0 {
-			return nil, fmt.Errorf("page %q not found in space %s", title, spaceKey)
+			return nil, fmt.Errorf("%s %q not found in space %s", what, title, spaceKey)
 		}
 		return ctx.Selector.Apply(&pages[0]), nil
 	}
@@ -272,17 +457,22 @@ func opGet(ctx agentquery.OperationContext[*confluence.Page], client *confluence
 	return nil, fmt.Errorf("get requires a page ID or space+title args")
 }
 
-func opList(ctx agentquery.OperationContext[*confluence.Page], client *confluence.Client) (any, error) {
+func opList(reqCtx context.Context, ctx agentquery.OperationContext[*confluence.Page], client *confluence.Client) (any, error) {
 	spaceKey := getNamedArg(ctx.Statement.Args, "space")
 	if spaceKey == "" {
 		return nil, fmt.Errorf("list requires space=KEY")
 	}
 
+	limit, err := getIntArg(ctx.Statement.Args, "limit", 0)
+	if err != nil {
+		return nil, err
+	}
+
 	label := getNamedArg(ctx.Statement.Args, "label")
 	if label != "" {
 		// Labels require CQL search (v2 doesn't support label filter).
 		cql := fmt.Sprintf("type=page AND space=%q AND label=%q", spaceKey, label)
-		result, err := client.SearchCQL(cql, 50)
+		result, err := client.SearchCQLContext(reqCtx, cql, limit)
 		if err != nil {
 			return nil, err
 		}
@@ -290,7 +480,7 @@ func opList(ctx agentquery.OperationContext[*confluence.Page], client *confluenc
 	}
 
 	title := getNamedArg(ctx.Statement.Args, "title")
-	pages, err := client.ListPages(spaceKey, title, 0)
+	pages, err := client.ListPagesContext(reqCtx, spaceKey, title, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -302,26 +492,92 @@ func opList(ctx agentquery.OperationContext[*confluence.Page], client *confluenc
 	return results, nil
 }
 
-func opSearch(ctx agentquery.OperationContext[*confluence.Page], client *confluence.Client) (any, error) {
+func opBlogs(reqCtx context.Context, ctx agentquery.OperationContext[*confluence.Page], client *confluence.Client) (any, error) {
+	spaceKey := getNamedArg(ctx.Statement.Args, "space")
+	if spaceKey == "" {
+		return nil, fmt.Errorf("blogs requires space=KEY")
+	}
+
+	limit, err := getIntArg(ctx.Statement.Args, "limit", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := client.ListBlogPostsContext(reqCtx, spaceKey, getNamedArg(ctx.Statement.Args, "title"), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]map[string]any, 0, len(posts))
+	for i := range posts {
+		results = append(results, ctx.Selector.Apply(&posts[i]))
+	}
+	return results, nil
+}
+
+func opDrafts(reqCtx context.Context, ctx agentquery.OperationContext[*confluence.Page], client *confluence.Client) (any, error) {
+	spaceKey := getNamedArg(ctx.Statement.Args, "space")
+	if spaceKey == "" {
+		return nil, fmt.Errorf("drafts requires space=KEY")
+	}
+
+	limit, err := getIntArg(ctx.Statement.Args, "limit", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	drafts, err := client.ListDraftsContext(reqCtx, spaceKey, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]map[string]any, 0, len(drafts))
+	for i := range drafts {
+		results = append(results, ctx.Selector.Apply(&drafts[i]))
+	}
+	return results, nil
+}
+
+func opSearch(reqCtx context.Context, ctx agentquery.OperationContext[*confluence.Page], client *confluence.Client) (any, error) {
 	cql := getPositionalArg(ctx.Statement.Args, 0)
 	if cql == "" {
 		return nil, fmt.Errorf("search requires a CQL query string")
 	}
 
-	result, err := client.SearchCQL(cql, 25)
+	limit, err := getIntArg(ctx.Statement.Args, "limit", defaultSearchLimit)
+	if err != nil {
+		return nil, err
+	}
+	start, err := getIntArg(ctx.Statement.Args, "start", 0)
 	if err != nil {
 		return nil, err
 	}
-	return searchToMinimal(result), nil
+
+	result, err := client.SearchCQLFromContext(reqCtx, cql, start, limit)
+	if err != nil {
+		return nil, err
+	}
+	// totalSize tells callers paging with start= whether more results exist.
+	return map[string]any{
+		"results":   searchToMinimal(result),
+		"totalSize": result.TotalSize,
+		"start":     result.Start,
+		"limit":     result.Limit,
+	}, nil
 }
 
-func opChildren(ctx agentquery.OperationContext[*confluence.Page], client *confluence.Client) (any, error) {
+func opChildren(reqCtx context.Context, ctx agentquery.OperationContext[*confluence.Page], client *confluence.Client) (any, error) {
 	pageID := getPositionalArg(ctx.Statement.Args, 0)
 	if pageID == "" {
 		return nil, fmt.Errorf("children requires a page ID")
 	}
 
-	children, err := client.GetChildren(pageID, 0)
+	limit, err := getIntArg(ctx.Statement.Args, "limit", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := client.GetChildrenContext(reqCtx, pageID, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -333,13 +589,13 @@ func opChildren(ctx agentquery.OperationContext[*confluence.Page], client *confl
 	return results, nil
 }
 
-func opAncestors(ctx agentquery.OperationContext[*confluence.Page], client *confluence.Client) (any, error) {
+func opAncestors(reqCtx context.Context, ctx agentquery.OperationContext[*confluence.Page], client *confluence.Client) (any, error) {
 	pageID := getPositionalArg(ctx.Statement.Args, 0)
 	if pageID == "" {
 		return nil, fmt.Errorf("ancestors requires a page ID")
 	}
 
-	ancestors, err := client.GetAncestors(pageID)
+	ancestors, err := client.GetAncestorsContext(reqCtx, pageID)
 	if err != nil {
 		return nil, err
 	}
@@ -347,7 +603,7 @@ func opAncestors(ctx agentquery.OperationContext[*confluence.Page], client *conf
 	return ancestors, nil
 }
 
-func opTree(ctx agentquery.OperationContext[*confluence.Page], client *confluence.Client) (any, error) {
+func opTree(reqCtx context.Context, ctx agentquery.OperationContext[*confluence.Page], client *confluence.Client) (any, error) {
 	pageID := getPositionalArg(ctx.Statement.Args, 0)
 	if pageID == "" {
 		return nil, fmt.Errorf("tree requires a page ID")
@@ -362,11 +618,14 @@ func opTree(ctx agentquery.OperationContext[*confluence.Page], client *confluenc
 		maxDepth = 10
 	}
 
-	return buildTree(client, ctx.Selector, pageID, maxDepth, 0)
+	return buildTree(reqCtx, client, ctx.Selector, pageID, maxDepth, 0)
 }
 
-func buildTree(client *confluence.Client, selector *agentquery.FieldSelector[*confluence.Page], pageID string, maxDepth, currentDepth int) (*treeNode, error) {
-	page, err := client.GetPage(pageID, false)
+// buildTree walks the page hierarchy depth-first. If reqCtx is cancelled
+// part-way through, the nodes read so far are returned with Truncated set
+// instead of an error, so callers still get a partial tree.
+func buildTree(reqCtx context.Context, client *confluence.Client, selector *agentquery.FieldSelector[*confluence.Page], pageID string, maxDepth, currentDepth int) (*treeNode, error) {
+	page, err := client.GetPageContext(reqCtx, pageID, false)
 	if err != nil {
 		return nil, err
 	}
@@ -377,24 +636,130 @@ func buildTree(client *confluence.Client, selector *agentquery.FieldSelector[*co
 		return node, nil
 	}
 
-	children, err := client.GetChildren(pageID, 0)
+	children, err := client.GetChildrenContext(reqCtx, pageID, 0)
 	if err != nil {
+		node.Truncated = reqCtx.Err() != nil
 		return node, nil // non-fatal
 	}
 
 	for _, child := range children {
-		childNode, err := buildTree(client, selector, child.ID, maxDepth, currentDepth+1)
+		if reqCtx.Err() != nil {
+			node.Truncated = true
+			break
+		}
+		childNode, err := buildTree(reqCtx, client, selector, child.ID, maxDepth, currentDepth+1)
 		if err != nil {
+			if reqCtx.Err() != nil {
+				node.Truncated = true
+				break
+			}
 			continue
 		}
 		node.Children = append(node.Children, *childNode)
+		if childNode.Truncated {
+			node.Truncated = true
+		}
 	}
 
 	return node, nil
 }
 
-func opSpaces(ctx agentquery.OperationContext[*confluence.Page], client *confluence.Client) (any, error) {
-	spaces, err := client.ListSpaces(0)
+func opHistory(reqCtx context.Context, ctx agentquery.OperationContext[*confluence.Page], client *confluence.Client) (any, error) {
+	pageID := getPositionalArg(ctx.Statement.Args, 0)
+	if pageID == "" {
+		return nil, fmt.Errorf("history requires a page ID")
+	}
+
+	limit, err := getIntArg(ctx.Statement.Args, "limit", defaultHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := client.ListPageVersionsContext(reqCtx, pageID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Versions are not pages — project the version fields manually; page-only
+	// fields such as title or url are ignored.
+	fields := ctx.Statement.Fields
+	if len(fields) == 0 {
+		fields = defaultHistoryFields
+	}
+	selected := map[string]bool{}
+	for _, f := range fields {
+		if name, ok := historyFields[f]; ok {
+			selected[name] = true
+		}
+	}
+
+	results := make([]map[string]any, 0, len(versions))
+	for _, v := range versions {
+		m := map[string]any{"number": v.Number}
+		if selected["author"] {
+			m["author"] = v.AuthorID
+		}
+		if selected["date"] {
+			m["date"] = v.CreatedAt
+		}
+		if selected["message"] {
+			m["message"] = v.Message
+		}
+		if selected["minorEdit"] {
+			m["minorEdit"] = v.MinorEdit
+		}
+		results = append(results, m)
+	}
+	return results, nil
+}
+
+func opDiff(reqCtx context.Context, ctx agentquery.OperationContext[*confluence.Page], client *confluence.Client) (any, error) {
+	pageID := getPositionalArg(ctx.Statement.Args, 0)
+	if pageID == "" {
+		return nil, fmt.Errorf("diff requires a page ID")
+	}
+
+	from, err := getIntArg(ctx.Statement.Args, "from", 0)
+	if err != nil {
+		return nil, err
+	}
+	to, err := getIntArg(ctx.Statement.Args, "to", 0)
+	if err != nil {
+		return nil, err
+	}
+	normalize, err := getBoolArg(ctx.Statement.Args, "normalize", false)
+	if err != nil {
+		return nil, err
+	}
+	format := getNamedArg(ctx.Statement.Args, "format")
+	if format != "" && format != "blocks" && format != "unified" {
+		return nil, fmt.Errorf("format must be blocks or unified, got %q", format)
+	}
+
+	diff, err := client.DiffPageVersionsContext(reqCtx, pageID, from, to, normalize)
+	if err != nil {
+		return nil, err
+	}
+	if format != "unified" {
+		return diff, nil
+	}
+	return map[string]any{
+		"pageId":      diff.PageID,
+		"fromVersion": diff.FromVersion,
+		"toVersion":   diff.ToVersion,
+		"unified": diff.Unified(
+			fmt.Sprintf("%s v%d", diff.PageID, diff.FromVersion),
+			fmt.Sprintf("%s v%d", diff.PageID, diff.ToVersion), 3),
+	}, nil
+}
+
+func opSpaces(reqCtx context.Context, ctx agentquery.OperationContext[*confluence.Page], client *confluence.Client) (any, error) {
+	limit, err := getIntArg(ctx.Statement.Args, "limit", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	spaces, err := client.ListSpacesContext(reqCtx, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -448,6 +813,37 @@ func getNamedArg(args []agentquery.Arg, name string) string {
 	return ""
 }
 
+// getIntArg parses a named integer arg, returning def when it is absent.
+func getIntArg(args []agentquery.Arg, name string, def int) (int, error) {
+	raw := getNamedArg(args, name)
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer, got %q", name, raw)
+	}
+	return n, nil
+}
+
+// getBoolArg parses a named boolean arg, returning def when it is absent.
+func getBoolArg(args []agentquery.Arg, name string, def bool) (bool, error) {
+	raw := getNamedArg(args, name)
+	if raw == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false, got %q", name, raw)
+	}
+	return b, nil
+}
+
+// fieldError is the value of a field whose lookup failed.
+func fieldError(err error) map[string]any {
+	return map[string]any{"error": map[string]any{"message": err.Error()}}
+}
+
 func containsField(fields []string, name string) bool {
 	if fields == nil {
 		return false
@@ -460,8 +856,8 @@ func containsField(fields []string, name string) bool {
 	return false
 }
 
-func searchToMinimal(result *confluence.SearchResult) any {
-	var items []map[string]any
+func searchToMinimal(result *confluence.SearchResult) []map[string]any {
+	items := make([]map[string]any, 0, len(result.Results))
 	for _, r := range result.Results {
 		item := map[string]any{
 			"title":   r.Title,
@@ -470,9 +866,9 @@ func searchToMinimal(result *confluence.SearchResult) any {
 		if r.Content != nil {
 			item["id"] = r.Content.ID
 			item["type"] = r.Content.Type
-			if r.Content.Space != nil {
-				item["spaceKey"] = r.Content.Space.Key
-			}
+		}
+		if key := r.SpaceKey(); key != "" {
+			item["spaceKey"] = key
 		}
 		items = append(items, item)
 	}
@@ -1,9 +1,13 @@
 package query
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -87,6 +91,64 @@ func TestSchema_GetBySpaceTitle(t *testing.T) {
 	}
 }
 
+func TestSchema_GetBlogPost(t *testing.T) {
+	var path string
+	ts, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewEncoder(w).Encode(confluence.Page{ID: "67890", Title: "Release Notes", Status: "current"})
+	})
+	defer ts.Close()
+
+	schema := NewSchema(client)
+	result := queryJSON(t, schema, `get(67890, type=blogpost){minimal}`)
+
+	var m map[string]any
+	if err := json.Unmarshal([]byte(result), &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !strings.HasSuffix(path, "/blogposts/67890") {
+		t.Errorf("path = %s, want the v2 blogposts endpoint", path)
+	}
+	if m["id"] != "67890" || m["title"] != "Release Notes" {
+		t.Errorf("post = %v", m)
+	}
+
+	if result := queryJSON(t, schema, `get(67890, type=comment){minimal}`); !strings.Contains(result, "page or blogpost") {
+		t.Errorf("unknown content type: got %s", result)
+	}
+}
+
+func TestSchema_Blogs(t *testing.T) {
+	var path string
+	ts, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/spaces") {
+			json.NewEncoder(w).Encode(confluence.CursorPage[confluence.Space]{
+				Results: []confluence.Space{{ID: "7", Key: "DEV"}},
+			})
+			return
+		}
+		path = r.URL.Path
+		json.NewEncoder(w).Encode(confluence.CursorPage[confluence.Page]{
+			Results: []confluence.Page{{ID: "401", Title: "Sprint 12 Recap", Status: "current", SpaceID: "7"}},
+		})
+	})
+	defer ts.Close()
+
+	schema := NewSchema(client)
+	result := queryJSON(t, schema, `blogs(space=DEV){minimal}`)
+
+	var arr []map[string]any
+	if err := json.Unmarshal([]byte(result), &arr); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !strings.HasSuffix(path, "/blogposts") {
+		t.Errorf("path = %s, want the v2 blogposts endpoint", path)
+	}
+	if len(arr) != 1 || arr[0]["id"] != "401" || arr[0]["title"] != "Sprint 12 Recap" {
+		t.Errorf("blogs = %v", arr)
+	}
+}
+
 func TestSchema_Spaces(t *testing.T) {
 	ts, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(confluence.CursorPage[confluence.Space]{
@@ -138,6 +200,37 @@ func TestSchema_Children(t *testing.T) {
 	}
 }
 
+func TestSchema_Drafts(t *testing.T) {
+	var status string
+	ts, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/spaces") {
+			json.NewEncoder(w).Encode(confluence.CursorPage[confluence.Space]{
+				Results: []confluence.Space{{ID: "7", Key: "DEV"}},
+			})
+			return
+		}
+		status = r.URL.Query().Get("status")
+		json.NewEncoder(w).Encode(confluence.CursorPage[confluence.Page]{
+			Results: []confluence.Page{{ID: "301", Title: "Q3 Plan", Status: "draft", SpaceID: "7"}},
+		})
+	})
+	defer ts.Close()
+
+	schema := NewSchema(client)
+	result := queryJSON(t, schema, `drafts(space=DEV){minimal}`)
+
+	var arr []map[string]any
+	if err := json.Unmarshal([]byte(result), &arr); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if status != "draft" {
+		t.Errorf("status filter = %q, want draft", status)
+	}
+	if len(arr) != 1 || arr[0]["id"] != "301" || arr[0]["status"] != "draft" {
+		t.Errorf("drafts = %v", arr)
+	}
+}
+
 func TestSchema_Search(t *testing.T) {
 	ts, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
 		cql := r.URL.Query().Get("cql")
@@ -158,12 +251,113 @@ func TestSchema_Search(t *testing.T) {
 	schema := NewSchema(client)
 	result := queryJSON(t, schema, `search("type=page"){default}`)
 
+	var page searchPage
+	if err := json.Unmarshal([]byte(result), &page); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(page.Results) != 1 || page.Results[0]["id"] != "77" {
+		t.Errorf("unexpected search results: %+v", page)
+	}
+	if page.Limit != defaultSearchLimit {
+		t.Errorf("limit = %d, want the default %d", page.Limit, defaultSearchLimit)
+	}
+}
+
+func TestSchema_SpaceKeyResolvedFromID(t *testing.T) {
+	lookups := 0
+	ts, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/spaces") {
+			lookups++
+			if r.URL.Query().Get("ids") != "100" {
+				t.Errorf("expected ids=100, got %q", r.URL.RawQuery)
+			}
+			json.NewEncoder(w).Encode(confluence.CursorPage[confluence.Space]{
+				Results: []confluence.Space{{ID: "100", Key: "DEV"}},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(confluence.CursorPage[confluence.Page]{
+			Results: []confluence.Page{{ID: "1", SpaceID: "100"}, {ID: "2", SpaceID: "100"}},
+		})
+	})
+	defer ts.Close()
+
+	schema := NewSchema(client)
+	result := queryJSON(t, schema, `children(5){id spaceKey}`)
+
 	var arr []map[string]any
 	if err := json.Unmarshal([]byte(result), &arr); err != nil {
 		t.Fatalf("unmarshal: %v", err)
 	}
-	if len(arr) != 1 || arr[0]["id"] != "77" {
-		t.Errorf("unexpected search results: %+v", arr)
+	if len(arr) != 2 || arr[0]["spaceKey"] != "DEV" || arr[1]["spaceKey"] != "DEV" {
+		t.Errorf("expected spaceKey DEV on both pages, got %+v", arr)
+	}
+	if lookups != 1 {
+		t.Errorf("expected one cached space lookup, got %d", lookups)
+	}
+}
+
+// searchPage is the shape of a search() result.
+type searchPage struct {
+	Results   []map[string]any `json:"results"`
+	TotalSize int              `json:"totalSize"`
+	Start     int              `json:"start"`
+	Limit     int              `json:"limit"`
+}
+
+func TestSchema_Search_SpaceKeyFromContainer(t *testing.T) {
+	ts, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(confluence.SearchResult{
+			Results: []confluence.SearchResultItem{{
+				Title:                 "Found",
+				Content:               &confluence.V1Content{ID: "77", Type: "page"},
+				ResultGlobalContainer: &confluence.ResultContainer{Title: "Dev", DisplayURL: "/spaces/DEV"},
+			}},
+		})
+	})
+	defer ts.Close()
+
+	result := queryJSON(t, NewSchema(client), `search("type=page")`)
+
+	var page searchPage
+	if err := json.Unmarshal([]byte(result), &page); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(page.Results) != 1 || page.Results[0]["spaceKey"] != "DEV" {
+		t.Errorf("expected spaceKey DEV, got %+v", page.Results)
+	}
+}
+
+func TestSchema_Search_LimitAndStart(t *testing.T) {
+	ts, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("start"); got != "10" {
+			t.Errorf("start = %s, want 10", got)
+		}
+		if got := r.URL.Query().Get("limit"); got != "5" {
+			t.Errorf("limit = %s, want 5", got)
+		}
+		json.NewEncoder(w).Encode(confluence.SearchResult{
+			Results: []confluence.SearchResultItem{
+				{Title: "Found", Content: &confluence.V1Content{ID: "77"}},
+			},
+			Start:     10,
+			TotalSize: 11,
+		})
+	})
+	defer ts.Close()
+
+	schema := NewSchema(client)
+	result := queryJSON(t, schema, `search("type=page", limit=5, start=10){minimal}`)
+
+	var page searchPage
+	if err := json.Unmarshal([]byte(result), &page); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(page.Results) != 1 {
+		t.Errorf("expected 1 result, got %+v", page.Results)
+	}
+	if page.TotalSize != 11 || page.Start != 10 || page.Limit != 5 {
+		t.Errorf("paging = totalSize %d, start %d, limit %d; want 11, 10, 5", page.TotalSize, page.Start, page.Limit)
 	}
 }
 
@@ -217,24 +411,289 @@ func TestSchema_Batch(t *testing.T) {
 	}
 }
 
-func TestSchema_HistoryNotImplemented(t *testing.T) {
-	ts, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {})
+func TestSchema_Tree_PartialOnCancel(t *testing.T) {
+	reqCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/pages/1/children":
+			json.NewEncoder(w).Encode(confluence.CursorPage[confluence.Page]{
+				Results: []confluence.Page{{ID: "2"}, {ID: "3"}},
+			})
+		case "/api/v2/pages/2/children":
+			// Cancel once the first child has been read; page 3 must not be visited.
+			cancel()
+			json.NewEncoder(w).Encode(confluence.CursorPage[confluence.Page]{})
+		case "/api/v2/pages/3":
+			t.Error("tree walk continued after cancellation")
+		default:
+			id := strings.TrimPrefix(r.URL.Path, "/api/v2/pages/")
+			json.NewEncoder(w).Encode(confluence.Page{ID: id})
+		}
+	})
+	defer ts.Close()
+
+	schema := NewSchemaContext(reqCtx, client)
+	result := queryJSON(t, schema, `tree(1, depth=2){minimal}`)
+
+	var node map[string]any
+	if err := json.Unmarshal([]byte(result), &node); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if node["truncated"] != true {
+		t.Errorf("expected truncated root, got %v", node)
+	}
+	children, _ := node["children"].([]any)
+	if len(children) != 1 {
+		t.Errorf("expected 1 child read before cancel, got %d", len(children))
+	}
+}
+
+func TestSchema_History(t *testing.T) {
+	ts, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/pages/12345/versions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("limit"); got != "2" {
+			t.Errorf("limit = %s, want 2", got)
+		}
+		json.NewEncoder(w).Encode(confluence.CursorPage[confluence.Version]{
+			Results: []confluence.Version{
+				{Number: 3, Message: "Fix typo", MinorEdit: true, AuthorID: "u1", CreatedAt: "2026-01-03T00:00:00Z"},
+				{Number: 2, Message: "Add rollback", AuthorID: "u2", CreatedAt: "2026-01-02T00:00:00Z"},
+			},
+		})
+	})
 	defer ts.Close()
 
 	schema := NewSchema(client)
-	// history returns an error, but in agentquery batch mode it's inlined.
-	// For a single statement, QueryJSONWithMode wraps it as an error.
-	data, err := schema.QueryJSONWithMode(`history(12345){minimal}`, agentquery.HumanReadable)
-	if err != nil {
-		// Single-statement error is fine — the error is returned directly
-		if !strings.Contains(err.Error(), "not yet implemented") {
-			t.Errorf("unexpected error: %v", err)
+	var rows []map[string]any
+	if err := json.Unmarshal([]byte(queryJSON(t, schema, `history(12345, limit=2)`)), &rows); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	want := map[string]any{"number": float64(3), "author": "u1", "date": "2026-01-03T00:00:00Z", "message": "Fix typo"}
+	if len(rows) != 2 || len(rows[0]) != len(want) {
+		t.Fatalf("rows = %v", rows)
+	}
+	for k, v := range want {
+		if rows[0][k] != v {
+			t.Errorf("%s = %v, want %v", k, rows[0][k], v)
+		}
+	}
+
+	rows = nil
+	if err := json.Unmarshal([]byte(queryJSON(t, schema, `history(12345, limit=2) { minorEdit date }`)), &rows); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(rows[0]) != 3 || rows[0]["minorEdit"] != true || rows[1]["date"] != "2026-01-02T00:00:00Z" {
+		t.Errorf("projected rows = %v, want number, minorEdit and date", rows)
+	}
+}
+
+func TestSchema_HistoryPresets(t *testing.T) {
+	ts, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(confluence.CursorPage[confluence.Version]{
+			Results: []confluence.Version{
+				{Number: 3, Message: "Fix typo", MinorEdit: true, AuthorID: "u1", CreatedAt: "2026-01-03T00:00:00Z"},
+			},
+		})
+	})
+	defer ts.Close()
+
+	schema := NewSchema(client)
+	keys := func(query string) []string {
+		var rows []map[string]any
+		if err := json.Unmarshal([]byte(queryJSON(t, schema, query)), &rows); err != nil || len(rows) != 1 {
+			t.Fatalf("%s: rows = %v, err = %v", query, rows, err)
+		}
+		var names []string
+		for k := range rows[0] {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`history(12345)`, "author,date,message,number"},
+		// Presets expand to page fields; only the version fields among them count.
+		{`history(12345){minimal}`, "number"},
+		{`history(12345){id title status}`, "number"},
+		{`history(12345){default}`, "number"},
+		{`history(12345){full}`, "author,date,number"},
+		{`history(12345){author id title status}`, "author,number"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(keys(tt.query), ","); got != tt.want {
+			t.Errorf("%s: fields = %s, want %s", tt.query, got, tt.want)
 		}
-		return
 	}
-	// If we got data instead, check that it contains an error
-	if !strings.Contains(string(data), "not yet implemented") {
-		t.Errorf("expected 'not yet implemented' in result, got: %s", data)
+}
+
+func TestSchema_VersionFields(t *testing.T) {
+	ts, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(confluence.Page{ID: "12345", Title: "Runbook",
+			Version: &confluence.Version{Number: 4, Message: "Add rollback", CreatedAt: "2026-01-02T00:00:00Z"}})
+	})
+	defer ts.Close()
+
+	out := queryJSON(t, NewSchema(client), `get(12345) { id number date message minorEdit }`)
+	if want := `{"date":"2026-01-02T00:00:00Z","id":"12345","message":"Add rollback","minorEdit":false,"number":4}`; out != want {
+		t.Errorf("get = %s, want %s", out, want)
+	}
+}
+
+func TestSchema_Diff(t *testing.T) {
+	ts, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/pages/12345" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		page := confluence.Page{ID: "12345", Version: &confluence.Version{Number: 5},
+			Body: &confluence.PageBody{Storage: &confluence.BodyRepresentation{Value: "<p>One</p><p>Two, edited</p>"}}}
+		if r.URL.Query().Get("version") == "4" {
+			page.Version.Number = 4
+			page.Body.Storage.Value = "<p>One</p><p>Two</p>"
+		}
+		json.NewEncoder(w).Encode(page)
+	})
+	defer ts.Close()
+
+	schema := NewSchema(client)
+	var diff struct {
+		FromVersion int                    `json:"fromVersion"`
+		ToVersion   int                    `json:"toVersion"`
+		Blocks      []confluence.DiffBlock `json:"blocks"`
+		Unified     string                 `json:"unified"`
+	}
+	if err := json.Unmarshal([]byte(queryJSON(t, schema, `diff(12345)`)), &diff); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if diff.FromVersion != 4 || diff.ToVersion != 5 {
+		t.Errorf("versions = %d..%d, want 4..5", diff.FromVersion, diff.ToVersion)
+	}
+	if len(diff.Blocks) != 1 || diff.Blocks[0].Kind != "changed" || diff.Blocks[0].Added[0] != "<p>Two, edited</p>" {
+		t.Errorf("blocks = %+v", diff.Blocks)
+	}
+
+	if err := json.Unmarshal([]byte(queryJSON(t, schema, `diff(12345, from=4, format=unified)`)), &diff); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !strings.Contains(diff.Unified, "-<p>Two</p>\n+<p>Two, edited</p>\n") {
+		t.Errorf("unified = %q", diff.Unified)
+	}
+}
+
+func TestSchema_Properties(t *testing.T) {
+	var propertyCalls int
+	ts, client := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/properties") {
+			propertyCalls++
+			// "legacy" has no value to decode.
+			w.Write([]byte(`{"results":[{"id":"9","key":"owner","value":{"team":"platform"}},` +
+				`{"id":"10","key":"reviewed","value":"2026-11-01"},{"id":"11","key":"legacy"}]}`))
+			return
+		}
+		json.NewEncoder(w).Encode(confluence.Page{ID: "12345", Title: "Runbook"})
+	})
+	defer ts.Close()
+
+	schema := NewSchema(client)
+	queryJSON(t, schema, `get(12345){full}`)
+	if propertyCalls != 0 {
+		t.Errorf("properties fetched without being projected")
+	}
+
+	result := queryJSON(t, schema, `get(12345){id properties}`)
+	var m map[string]any
+	if err := json.Unmarshal([]byte(result), &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	props, _ := m["properties"].(map[string]any)
+	owner, _ := props["owner"].(map[string]any)
+	if owner["team"] != "platform" || props["reviewed"] != "2026-11-01" {
+		t.Errorf("properties = %v", m["properties"])
+	}
+	legacy, _ := props["legacy"].(map[string]any)
+	if e, _ := legacy["error"].(map[string]any); e == nil || !strings.Contains(e["message"].(string), "legacy") {
+		t.Errorf("undecodable value = %v, want it reported in place", props["legacy"])
+	}
+}
+
+func TestSchema_PropertiesErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/properties") {
+			if strings.Contains(r.URL.Path, "/pages/1/") {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			json.NewEncoder(w).Encode(confluence.CursorPage[confluence.ContentProperty]{})
+			return
+		}
+		pages := make([]confluence.Page, maxPropertyPages+1)
+		for i := range pages {
+			pages[i] = confluence.Page{ID: strconv.Itoa(i + 1)}
+		}
+		json.NewEncoder(w).Encode(confluence.CursorPage[confluence.Page]{Results: pages})
+	}))
+	defer ts.Close()
+	// Unthrottled: the query makes one request per page.
+	client, _ := confluence.NewClient(confluence.Config{
+		BaseURL:      ts.URL,
+		Email:        "test@test.com",
+		Token:        "tok",
+		InstanceType: confluence.InstanceCloud,
+		RateLimit:    &confluence.RateLimit{},
+	})
+	client.SetHTTPClient(ts.Client())
+
+	var rows []map[string]any
+	if err := json.Unmarshal([]byte(queryJSON(t, NewSchema(client), `children(5){id properties}`)), &rows); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(rows) != maxPropertyPages+1 {
+		t.Fatalf("got %d rows, want %d", len(rows), maxPropertyPages+1)
+	}
+	message := func(row map[string]any) string {
+		props, _ := row["properties"].(map[string]any)
+		e, _ := props["error"].(map[string]any)
+		m, _ := e["message"].(string)
+		return m
+	}
+	if m := message(rows[0]); !strings.Contains(m, "403") {
+		t.Errorf("failed lookup = %v, want the 403 reported", rows[0]["properties"])
+	}
+	if props, _ := rows[1]["properties"].(map[string]any); props == nil || len(props) != 0 {
+		t.Errorf("page without properties = %v, want {}", rows[1]["properties"])
+	}
+	if m := message(rows[maxPropertyPages]); !strings.Contains(m, "at most") {
+		t.Errorf("page past the cap = %v, want the fan-out error", rows[maxPropertyPages]["properties"])
+	}
+}
+
+func TestSchema_PropertiesNotSupported(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/properties") {
+			t.Errorf("properties requested from an instance without them")
+		}
+		json.NewEncoder(w).Encode(confluence.Page{ID: "12345"})
+	}))
+	defer ts.Close()
+	client, _ := confluence.NewClient(confluence.Config{
+		BaseURL:      ts.URL,
+		Email:        "test@test.com",
+		Token:        "tok",
+		InstanceType: confluence.InstanceCloud,
+		Capabilities: []confluence.Capability{confluence.CapV2, confluence.CapV1},
+	})
+	client.SetHTTPClient(ts.Client())
+
+	result := queryJSON(t, NewSchema(client), `get(12345){id properties}`)
+	if !strings.Contains(result, "not supported on this instance") {
+		t.Errorf("result = %s, want a not-supported marker", result)
 	}
 }
 
@@ -304,7 +763,7 @@ func TestSchema_AllOperationsRegistered(t *testing.T) {
 	defer ts.Close()
 
 	schema := NewSchema(client)
-	ops := []string{"get", "list", "search", "children", "ancestors", "tree", "spaces", "history", "schema"}
+	ops := []string{"get", "list", "blogs", "drafts", "search", "children", "ancestors", "tree", "spaces", "history", "schema"}
 	for _, op := range ops {
 		// Just verify the operation is recognized by the parser (no parse error).
 		// Some will fail at execution (missing args), that's fine.
@@ -409,3 +868,33 @@ func TestSchema_ApplyPageFields_NilSafety(t *testing.T) {
 		t.Errorf("id = %v, want 1", m["id"])
 	}
 }
+
+// TestSchema_ReplayCloudCassette runs DSL queries against the recorded Cloud
+// v2 cassette shared with the client tests, so field projection is checked
+// against realistic payloads rather than hand-built stubs.
+func TestSchema_ReplayCloudCassette(t *testing.T) {
+	cassette, err := confluence.LoadCassette(filepath.Join("..", "confluence", "testdata", "cassettes", "cloud_v2_pages.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := confluence.NewClient(confluence.Config{
+		BaseURL:      "https://example.atlassian.net/wiki",
+		Email:        "agent@example.com",
+		Token:        "tok",
+		InstanceType: confluence.InstanceCloud,
+		RetryPolicy:  &confluence.RetryPolicy{MaxAttempts: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetHTTPClient(cassette.HTTPClient())
+	schema := NewSchema(client)
+
+	out := queryJSON(t, schema, "get(131078) { id title version }")
+	if out != `{"id":"131078","title":"Release Checklist","version":7}` {
+		t.Errorf("get = %s", out)
+	}
+	if misses := cassette.Misses(); len(misses) > 0 {
+		t.Errorf("requests missing from the cassette: %v", misses)
+	}
+}